@@ -0,0 +1,128 @@
+package bachelier
+
+import (
+	"errors"
+	"math"
+
+	"code.vegaprotocol.io/quant/misc"
+)
+
+// The Bachelier (normal) model assumes the forward price F follows an arithmetic
+// Brownian motion with absolute volatility sigma, so prices can reach zero or go negative.
+// All functions take the discount factor D from option expiry back to today, e.g. D = exp(-rT).
+
+// ErrPriceBelowLowerBound is returned by ImpliedVol when the option price doesn't exceed its discounted intrinsic value
+var ErrPriceBelowLowerBound = errors.New("option price is at or below its no-arbitrage lower bound")
+
+// expiry returns the time to maturity floored at 0, past expiry the option is worth its intrinsic value
+func expiry(T float64) float64 {
+	return math.Max(T, 0)
+}
+
+// dFn returns (F - K) / (sigma sqrt(T)). When sigma sqrt(T) is zero, i.e. the volatility is zero or the option
+// is at expiry, it returns the limit: +Inf if the forward is above the strike, -Inf if it is below and 0 at the strike,
+// so that the prices are the discounted intrinsic values and the deltas are step functions.
+func dFn(F, K, sigma, T float64) float64 {
+	stdDev := sigma * math.Sqrt(T)
+	if stdDev == 0 {
+		switch {
+		case F > K:
+			return math.Inf(1)
+		case F < K:
+			return math.Inf(-1)
+		}
+		return 0
+	}
+	return (F - K) / stdDev
+}
+
+// CallPrice calculates the call option price according to the Bachelier formula.
+// The two terms nearly cancel far out of the money so the CDF is the precise misc.GaussCdf.
+func CallPrice(F, K, D, sigma, T float64) float64 {
+	T = expiry(T)
+	stdDev := sigma * math.Sqrt(T)
+	d := dFn(F, K, sigma, T)
+	return D * ((F-K)*misc.GaussCdf(d) + stdDev*misc.GaussDensity(d))
+}
+
+// PutPrice calculates the put option price according to the Bachelier formula,
+// directly rather than by put-call parity which cancels far out of the money
+func PutPrice(F, K, D, sigma, T float64) float64 {
+	T = expiry(T)
+	stdDev := sigma * math.Sqrt(T)
+	d := dFn(F, K, sigma, T)
+	return D * ((K-F)*misc.GaussCdf(-d) + stdDev*misc.GaussDensity(d))
+}
+
+// CallDelta calculates the Bachelier Delta (partial derivative w.r.t. F)
+func CallDelta(F, K, D, sigma, T float64) float64 {
+	T = expiry(T)
+	return D * misc.GaussCdf(dFn(F, K, sigma, T))
+}
+
+// PutDelta calculates the Bachelier Delta (partial derivative w.r.t. F)
+func PutDelta(F, K, D, sigma, T float64) float64 {
+	T = expiry(T)
+	return -D * misc.GaussCdf(-dFn(F, K, sigma, T))
+}
+
+// Gamma calculates the Bachelier Gamma (second partial derivative w.r.t. F)
+// Note that it's identical for both puts and calls
+func Gamma(F, K, D, sigma, T float64) float64 {
+	T = expiry(T)
+	stdDev := sigma * math.Sqrt(T)
+	if stdDev == 0 {
+		return 0
+	}
+	return D * misc.GaussDensity(dFn(F, K, sigma, T)) / stdDev
+}
+
+// Vega calculates the Bachelier Vega (partial derivative w.r.t. the normal volatility sigma)
+// Note that it's identical for both puts and calls
+func Vega(F, K, D, sigma, T float64) float64 {
+	T = expiry(T)
+	return D * misc.GaussDensity(dFn(F, K, sigma, T)) * math.Sqrt(T)
+}
+
+// ImpliedVol calculates the implied normal volatility
+// from call or put price as indicated by isCall.
+// It returns misc.ErrInvalidMaturity unless T is positive and ErrPriceBelowLowerBound when the price has no time value.
+func ImpliedVol(F, K, D, T, price float64, isCall bool) (float64, error) {
+	const solverTol = 1e-12
+	const solverMaxIt = 100
+
+	if !(T > 0) {
+		return math.NaN(), misc.ErrInvalidMaturity
+	}
+	intrinsic := math.Max(F-K, 0)
+	if !isCall {
+		intrinsic = math.Max(K-F, 0)
+	}
+	if !(price > D*intrinsic) {
+		return math.NaN(), ErrPriceBelowLowerBound
+	}
+
+	// start from the at-the-money approximation price = D sigma sqrt(T/(2 pi)) using the time value,
+	// but not below the distance to the strike measured in standard deviations of one
+	guessVol := math.Max(math.Sqrt(2*math.Pi/T)*(price/D-intrinsic), math.Abs(F-K)/math.Sqrt(T))
+
+	var priceAsFnOfSigma func(sigma float64) float64
+	if isCall {
+		priceAsFnOfSigma = func(sigma float64) float64 {
+			return CallPrice(F, K, D, sigma, T) - price
+		}
+	} else {
+		priceAsFnOfSigma = func(sigma float64) float64 {
+			return PutPrice(F, K, D, sigma, T) - price
+		}
+	}
+	priceAsFnOfSigmaPrime := func(sigma float64) float64 {
+		return Vega(F, K, D, sigma, T)
+	}
+
+	impliedVol, err := misc.FindRoot(priceAsFnOfSigma, priceAsFnOfSigmaPrime, guessVol, solverMaxIt, solverTol)
+	if err != nil {
+		return math.NaN(), err
+	}
+	return impliedVol, nil
+}
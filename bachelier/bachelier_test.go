@@ -0,0 +1,197 @@
+package bachelier
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/misc"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// the inputs are F, K, r, sigma, T; the discount factor is exp(-rT)
+var testValues = []struct {
+	F     float64
+	K     float64
+	r     float64
+	sigma float64
+	T     float64
+}{
+	{1.0, 1.0, 0.02, 0.5, 0.25},   // test at the money
+	{1.0, 1.0, -0.02, 0.5, 0.25},  // test negative interest rate
+	{1.0, 1.5, 0.02, 0.5, 0.25},   // test high strike
+	{1.0, 0.5, 0.02, 0.5, 0.25},   // test low strike
+	{0.0, 0.1, 0.0, 0.3, 1.0},     // test zero forward
+	{-0.5, -0.2, 0.01, 0.4, 2.0},  // test negative forward and strike
+	{75, 80, 0.03, 7.5, 2},        // test non-unit strike / mat
+	{0.01, 0.015, 0.0, 0.01, 0.5}, // test rates-like spread
+}
+
+// TestBachelierPricesUsingMonteCarlo we use MC simulation to check call
+// and put prices.
+func TestBachelierPricesUsingMonteCarlo(t *testing.T) {
+	const testToleranceForMC float64 = 2.0e-3
+	const numIndepMCSamples int = 20000
+
+	numMCSamples := 2 * numIndepMCSamples
+	Z := make([]float64, numMCSamples)
+	for i := 0; i < numIndepMCSamples; i++ {
+		z := distuv.UnitNormal.Rand()
+		Z[i] = z
+		Z[numIndepMCSamples+i] = -z // antithetic sample
+	}
+
+	for _, table := range testValues {
+		F, K, r, sigma, T := table.F, table.K, table.r, table.sigma, table.T
+		D := math.Exp(-r * T)
+
+		call := CallPrice(F, K, D, sigma, T)
+		put := PutPrice(F, K, D, sigma, T)
+		var callPayoff float64
+		var putPayoff float64
+		for i := 0; i < numMCSamples; i++ {
+			FatT := F + sigma*math.Sqrt(T)*Z[i]
+			callPayoff += math.Max(FatT-K, 0.0)
+			putPayoff += math.Max(K-FatT, 0.0)
+		}
+		mcCall := D * callPayoff / float64(numMCSamples)
+		mcPut := D * putPayoff / float64(numMCSamples)
+
+		// errors relative to the standard deviation of the forward at expiry
+		error := (math.Abs(mcCall-call) + math.Abs(mcPut-put)) / (sigma * math.Sqrt(T))
+		if math.IsNaN(error) || math.IsInf(error, 0) || error > testToleranceForMC {
+			t.Errorf("F=%g, K=%g, r=%g, sigma=%g, T=%g, error=%g is greater than tolerance.\n",
+				F, K, r, sigma, T, error)
+		}
+	}
+}
+
+func TestBachelierGreeksVsFiniteDifference(t *testing.T) {
+	const testTolerance float64 = 1.0e-3
+	const bump float64 = 1.0e-7
+
+	for _, table := range testValues {
+		F, K, r, sigma, T := table.F, table.K, table.r, table.sigma, table.T
+		D := math.Exp(-r * T)
+
+		fd := func(fn func(h float64) float64) float64 {
+			return (fn(bump) - fn(-bump)) / (2 * bump)
+		}
+		// we check gamma with second differences of the price, and with a looser tolerance,
+		// relative to the standard deviation of F at expiry
+		const gammaBump float64 = 1.0e-4
+		const gammaTolerance float64 = 5.0e-3
+		stdDev := sigma * math.Sqrt(T)
+		fdGamma := (CallPrice(F+gammaBump*stdDev, K, D, sigma, T) - 2*CallPrice(F, K, D, sigma, T) +
+			CallPrice(F-gammaBump*stdDev, K, D, sigma, T)) / (gammaBump * gammaBump * stdDev * stdDev)
+
+		errors := []float64{
+			CallDelta(F, K, D, sigma, T) - fd(func(h float64) float64 { return CallPrice(F+h, K, D, sigma, T) }),
+			PutDelta(F, K, D, sigma, T) - fd(func(h float64) float64 { return PutPrice(F+h, K, D, sigma, T) }),
+			Vega(F, K, D, sigma, T) - fd(func(h float64) float64 { return CallPrice(F, K, D, sigma+h, T) }),
+		}
+		errorGamma := math.Abs(Gamma(F, K, D, sigma, T)-fdGamma) * stdDev
+		if math.IsNaN(errorGamma) || math.IsInf(errorGamma, 0) || errorGamma > gammaTolerance {
+			t.Errorf("F=%g, K=%g, r=%g, sigma=%g, T=%g, gamma error=%g is greater than tolerance.\n",
+				F, K, r, sigma, T, errorGamma)
+		}
+
+		for _, e := range errors {
+			error := math.Abs(e)
+			if math.IsNaN(error) || math.IsInf(error, 0) || error > testTolerance {
+				t.Errorf("F=%g, K=%g, r=%g, sigma=%g, T=%g, error=%g is greater than tolerance.\n",
+					F, K, r, sigma, T, error)
+			}
+		}
+	}
+}
+
+func TestBachelierImpliedVolCalcs(t *testing.T) {
+	const testTolerance float64 = 1.0e-6
+
+	for _, table := range testValues {
+		F, K, r, sigma, T := table.F, table.K, table.r, table.sigma, table.T
+		D := math.Exp(-r * T)
+
+		volFromCall, errCall := ImpliedVol(F, K, D, T, CallPrice(F, K, D, sigma, T), true)
+		if errCall != nil {
+			t.Errorf(errCall.Error())
+		}
+		volFromPut, errPut := ImpliedVol(F, K, D, T, PutPrice(F, K, D, sigma, T), false)
+		if errPut != nil {
+			t.Errorf(errPut.Error())
+		}
+
+		error := (math.Abs(volFromCall-sigma) + math.Abs(volFromPut-sigma)) / sigma
+		if math.IsNaN(error) || math.IsInf(error, 0) || error > testTolerance {
+			t.Errorf("F=%g, K=%g, r=%g, sigma=%g, T=%g, error=%g is greater than tolerance.\n",
+				F, K, r, sigma, T, error)
+		}
+	}
+}
+
+// TestBachelierDeepOutOfTheMoney checks the prices far out of the money, where the terms of the Bachelier formula
+// nearly cancel, against sigma sqrt(T) (phi(d) - d N(-d)) for d = |F - K| / (sigma sqrt(T)) evaluated with gonum
+func TestBachelierDeepOutOfTheMoney(t *testing.T) {
+	const testTolerance float64 = 1e-9 // relative
+	const F, D, sigma, T = 100.0, 0.95, 10.0, 1.0
+
+	for _, distance := range []float64{20, 30, 40, 50, 60} {
+		d := distance / (sigma * math.Sqrt(T))
+		expected := D * sigma * math.Sqrt(T) * (distuv.UnitNormal.Prob(d) - d*distuv.UnitNormal.CDF(-d))
+		call, put := CallPrice(F, F+distance, D, sigma, T), PutPrice(F, F-distance, D, sigma, T)
+		error := (math.Abs(call-expected) + math.Abs(put-expected)) / expected
+		if math.IsNaN(error) || error > testTolerance {
+			t.Errorf("distance=%g: call=%g, put=%g, expected=%g\n", distance, call, put, expected)
+		}
+	}
+}
+
+// TestBachelierDegenerateInputs checks the limits of the prices and greeks at expiry, past expiry and with zero volatility
+func TestBachelierDegenerateInputs(t *testing.T) {
+	const tolerance float64 = 1e-6
+	const D float64 = 0.95
+
+	tables := []struct {
+		name                string
+		F, K, sigma, T      float64
+		call, put           float64
+		callDelta, putDelta float64
+	}{
+		{"expiry in the money", 110, 100, 5, 0, 10 * D, 0, D, 0},
+		{"expiry out of the money", 90, 100, 5, 0, 0, 10 * D, 0, -D},
+		{"expiry at the money", 100, 100, 5, 0, 0, 0, 0.5 * D, -0.5 * D},
+		{"past expiry", 110, 100, 5, -0.1, 10 * D, 0, D, 0},
+		{"zero volatility at the money", 100, 100, 0, 1, 0, 0, 0.5 * D, -0.5 * D},
+		{"zero volatility below strike", -1, 1, 0, 1, 0, 2 * D, 0, -D},
+	}
+	for _, table := range tables {
+		F, K, sigma, T := table.F, table.K, table.sigma, table.T
+		values := []struct {
+			name            string
+			value, expected float64
+		}{
+			{"call", CallPrice(F, K, D, sigma, T), table.call},
+			{"put", PutPrice(F, K, D, sigma, T), table.put},
+			{"call delta", CallDelta(F, K, D, sigma, T), table.callDelta},
+			{"put delta", PutDelta(F, K, D, sigma, T), table.putDelta},
+			{"gamma", Gamma(F, K, D, sigma, T), 0},
+		}
+		for _, v := range values {
+			error := math.Abs(v.value - v.expected)
+			if math.IsNaN(error) || math.IsInf(error, 0) || error > tolerance {
+				t.Errorf("%s: %s=%g, expected=%g\n", table.name, v.name, v.value, v.expected)
+			}
+		}
+		if vega := Vega(F, K, D, sigma, T); math.IsNaN(vega) || math.IsInf(vega, 0) {
+			t.Errorf("%s: vega=%g\n", table.name, vega)
+		}
+	}
+
+	if _, err := ImpliedVol(100, 100, D, 1, 0, true); err != ErrPriceBelowLowerBound {
+		t.Errorf("zero at the money price: expected %v, got %v\n", ErrPriceBelowLowerBound, err)
+	}
+	if _, err := ImpliedVol(100, 100, D, 0, 1, false); err != misc.ErrInvalidMaturity {
+		t.Errorf("at expiry: expected %v, got %v\n", misc.ErrInvalidMaturity, err)
+	}
+}
@@ -0,0 +1,249 @@
+package backtest
+
+import (
+	"errors"
+	"math"
+
+	"code.vegaprotocol.io/quant/misc"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// The forecasts follow the convention of the riskmeasures package: the value at risk and the expected shortfall
+// at level alpha are positive for losses, so that the P&L of a period exceeds the VaR when it is below -VaR.
+
+var (
+	// ErrLengthMismatch is returned when there isn't one VaR (and ES) forecast per realised P&L
+	ErrLengthMismatch = errors.New("there must be one forecast per realised P&L")
+	// ErrInvalidForecast is returned when a VaR forecast isn't finite or an ES forecast isn't positive and finite
+	ErrInvalidForecast = errors.New("forecasts must be finite with positive expected shortfall")
+)
+
+// Zone is the Basel traffic light zone of the number of VaR exceedances
+type Zone int
+
+const (
+	// Green means the number of exceedances is consistent with the VaR level
+	Green Zone = iota
+	// Yellow means the number of exceedances is unlikely but doesn't reject the VaR forecasts
+	Yellow
+	// Red means the number of exceedances all but rejects the VaR forecasts
+	Red
+)
+
+const (
+	// yellowZoneProbability is the cumulative probability of the number of exceedances where the yellow zone starts
+	yellowZoneProbability = 0.95
+	// redZoneProbability is the cumulative probability of the number of exceedances where the red zone starts
+	redZoneProbability = 0.9999
+)
+
+// String returns the name of the zone
+func (z Zone) String() string {
+	switch z {
+	case Green:
+		return "green"
+	case Yellow:
+		return "yellow"
+	case Red:
+		return "red"
+	}
+	return "unknown"
+}
+
+// TestResult is the statistic of a likelihood ratio test and its asymptotic chi-square p-value
+type TestResult struct {
+	Statistic float64
+	PValue    float64
+}
+
+// Reject returns true if the test rejects the forecasts at the given significance level
+func (r TestResult) Reject(significance float64) bool {
+	return r.PValue < significance
+}
+
+// Report is the outcome of backtesting VaR and ES forecasts at level Alpha against Observations realised P&Ls
+type Report struct {
+	Alpha               float64
+	Observations        int
+	Exceedances         int
+	ExpectedExceedances float64
+	Kupiec              TestResult
+	Independence        TestResult
+	ConditionalCoverage TestResult
+	TrafficLight        Zone
+	// AcerbiSzekelyZ1 and AcerbiSzekelyZ2 are NaN when no ES forecasts are given, see AcerbiSzekely
+	AcerbiSzekelyZ1 float64
+	AcerbiSzekelyZ2 float64
+}
+
+// Run backtests the VaR forecasts vars and the ES forecasts ess at level alpha against the realised pnl,
+// where the i-th forecasts are those made for the period of the i-th P&L. The ES tests are skipped when ess is nil.
+func Run(pnl, vars, ess []float64, alpha float64) (Report, error) {
+	hits, err := Exceedances(pnl, vars)
+	if err != nil {
+		return Report{}, err
+	}
+	if err := misc.ValidateProbability(alpha); err != nil {
+		return Report{}, err
+	}
+	report := Report{
+		Alpha:               alpha,
+		Observations:        len(hits),
+		Exceedances:         count(hits),
+		ExpectedExceedances: alpha * float64(len(hits)),
+		Kupiec:              Kupiec(hits, alpha),
+		Independence:        ChristoffersenIndependence(hits),
+		ConditionalCoverage: ChristoffersenConditionalCoverage(hits, alpha),
+		TrafficLight:        TrafficLight(count(hits), len(hits), alpha),
+		AcerbiSzekelyZ1:     math.NaN(),
+		AcerbiSzekelyZ2:     math.NaN(),
+	}
+	if ess != nil {
+		if report.AcerbiSzekelyZ1, report.AcerbiSzekelyZ2, err = AcerbiSzekely(pnl, vars, ess, alpha); err != nil {
+			return Report{}, err
+		}
+	}
+	return report, nil
+}
+
+// Exceedances returns the hit sequence, true for the periods in which the P&L is below minus the VaR forecast
+func Exceedances(pnl, vars []float64) ([]bool, error) {
+	if len(pnl) == 0 {
+		return nil, misc.ErrEmptySample
+	}
+	if len(vars) != len(pnl) {
+		return nil, ErrLengthMismatch
+	}
+	hits := make([]bool, len(pnl))
+	for i, x := range pnl {
+		if err := misc.FirstError(misc.ValidateFinite(x, misc.ErrInvalidSample), misc.ValidateFinite(vars[i], ErrInvalidForecast)); err != nil {
+			return nil, err
+		}
+		hits[i] = x < -vars[i]
+	}
+	return hits, nil
+}
+
+// Kupiec returns the proportion of failures test of Kupiec (Techniques for verifying the accuracy of risk measurement
+// models, Journal of Derivatives, 1995), the likelihood ratio of the observed exceedance frequency against alpha,
+// asymptotically chi-square with one degree of freedom
+func Kupiec(hits []bool, alpha float64) TestResult {
+	n, x := float64(len(hits)), float64(count(hits))
+	statistic := 2 * (bernoulliLogLikelihood(n-x, x, x/n) - bernoulliLogLikelihood(n-x, x, alpha))
+	return chiSquareTest(statistic, 1)
+}
+
+// ChristoffersenIndependence returns the independence test of Christoffersen (Evaluating interval forecasts,
+// International Economic Review, 1998), the likelihood ratio of a first order Markov chain of the hits against
+// independent ones, asymptotically chi-square with one degree of freedom. Clustered exceedances are rejected.
+func ChristoffersenIndependence(hits []bool) TestResult {
+	// transitions[i][j] counts the periods with hit j after a period with hit i
+	var transitions [2][2]float64
+	for t := 1; t < len(hits); t++ {
+		transitions[index(hits[t-1])][index(hits[t])]++
+	}
+	n00, n01, n10, n11 := transitions[0][0], transitions[0][1], transitions[1][0], transitions[1][1]
+	pi01 := n01 / (n00 + n01)
+	pi11 := n11 / (n10 + n11)
+	pi := (n01 + n11) / (n00 + n01 + n10 + n11)
+	statistic := 2 * (bernoulliLogLikelihood(n00, n01, pi01) + bernoulliLogLikelihood(n10, n11, pi11) -
+		bernoulliLogLikelihood(n00+n10, n01+n11, pi))
+	return chiSquareTest(statistic, 1)
+}
+
+// ChristoffersenConditionalCoverage returns the conditional coverage test of Christoffersen, the sum of the Kupiec
+// and independence statistics, asymptotically chi-square with two degrees of freedom. Kupiec's likelihood is
+// computed on the hits after the first, as the transitions are, so that the statistics add up.
+func ChristoffersenConditionalCoverage(hits []bool, alpha float64) TestResult {
+	var statistic float64
+	if len(hits) > 1 {
+		statistic = Kupiec(hits[1:], alpha).Statistic
+	}
+	statistic += ChristoffersenIndependence(hits).Statistic
+	return chiSquareTest(statistic, 2)
+}
+
+// TrafficLight returns the Basel zone of the number of exceedances out of n VaR forecasts at level alpha,
+// the yellow zone starts where the binomial probability of at most that many exceedances reaches 95%
+// and the red zone where it reaches 99.99%, i.e. 5 and 10 exceedances for 250 forecasts at 1%
+func TrafficLight(exceedances, n int, alpha float64) Zone {
+	cdf := distuv.Binomial{N: float64(n), P: alpha}.CDF(float64(exceedances))
+	if cdf >= redZoneProbability {
+		return Red
+	}
+	if cdf >= yellowZoneProbability {
+		return Yellow
+	}
+	return Green
+}
+
+// AcerbiSzekely returns the first two expected shortfall test statistics of Acerbi and Szekely (Backtesting expected
+// shortfall, Risk, 2014), Z1 = sum(X I / ES) / sum(I) + 1 which tests the ES given the VaR exceedances I and
+// Z2 = sum(X I / (alpha ES)) / T + 1 which tests the ES and the VaR together, for T periods with P&L X.
+// Both have expectation 0 when the forecasts are correct and are negative when the risk is underestimated.
+// Z1 is NaN without exceedances.
+func AcerbiSzekely(pnl, vars, ess []float64, alpha float64) (z1, z2 float64, err error) {
+	hits, err := Exceedances(pnl, vars)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := misc.ValidateProbability(alpha); err != nil {
+		return 0, 0, err
+	}
+	if len(ess) != len(pnl) {
+		return 0, 0, ErrLengthMismatch
+	}
+	var sum float64
+	for i, hit := range hits {
+		if err := misc.ValidatePositive(ess[i], ErrInvalidForecast); err != nil {
+			return 0, 0, err
+		}
+		if hit {
+			sum += pnl[i] / ess[i]
+		}
+	}
+	z1 = sum/float64(count(hits)) + 1
+	if count(hits) == 0 {
+		z1 = math.NaN()
+	}
+	z2 = sum/(alpha*float64(len(hits))) + 1
+	return z1, z2, nil
+}
+
+// bernoulliLogLikelihood returns the log-likelihood of the given numbers of failures and successes
+// with success probability p, where 0 log 0 = 0
+func bernoulliLogLikelihood(failures, successes, p float64) float64 {
+	var logLikelihood float64
+	if failures > 0 {
+		logLikelihood += failures * math.Log1p(-p)
+	}
+	if successes > 0 {
+		logLikelihood += successes * math.Log(p)
+	}
+	return logLikelihood
+}
+
+// chiSquareTest returns the statistic and its p-value for the chi-square distribution with k degrees of freedom
+func chiSquareTest(statistic, k float64) TestResult {
+	// the statistics are non-negative up to rounding
+	statistic = math.Max(statistic, 0)
+	return TestResult{Statistic: statistic, PValue: distuv.ChiSquared{K: k}.Survival(statistic)}
+}
+
+func count(hits []bool) int {
+	var n int
+	for _, hit := range hits {
+		if hit {
+			n++
+		}
+	}
+	return n
+}
+
+func index(hit bool) int {
+	if hit {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,179 @@
+package backtest
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/misc"
+	"code.vegaprotocol.io/quant/riskmeasures"
+
+	"golang.org/x/exp/rand"
+)
+
+// simulate returns normal P&Ls with standard deviation sigma and the VaR and ES forecasts at level alpha of normal
+// P&Ls with standard deviation forecastSigma
+func simulate(n int, sigma, forecastSigma, alpha float64, rng *rand.Rand) (pnl, vars, ess []float64) {
+	pnl, vars, ess = make([]float64, n), make([]float64, n), make([]float64, n)
+	for i := range pnl {
+		pnl[i] = sigma * rng.NormFloat64()
+		vars[i] = riskmeasures.NormalVaR(0, forecastSigma, alpha)
+		ess[i] = riskmeasures.NormalEs(0, forecastSigma, alpha)
+	}
+	return pnl, vars, ess
+}
+
+func TestTrafficLightBaselZones(t *testing.T) {
+	for exceedances := 0; exceedances <= 15; exceedances++ {
+		expected := Green
+		if exceedances >= 10 {
+			expected = Red
+		} else if exceedances >= 5 {
+			expected = Yellow
+		}
+		if zone := TrafficLight(exceedances, 250, 0.01); zone != expected {
+			t.Errorf("%d exceedances: expected %v zone, got %v\n", exceedances, expected, zone)
+		}
+	}
+}
+
+func TestKupiec(t *testing.T) {
+	const tolerance float64 = 1e-12
+	hits := make([]bool, 1000)
+	for i := 0; i < 10; i++ {
+		hits[100*i] = true
+	}
+	if result := Kupiec(hits, 0.01); math.Abs(result.Statistic) > tolerance || math.Abs(result.PValue-1) > tolerance {
+		t.Errorf("exceedance frequency equal to alpha: statistic=%g, p-value=%g\n", result.Statistic, result.PValue)
+	}
+	if result := Kupiec(hits, 0.001); !result.Reject(0.01) {
+		t.Errorf("10 times the expected exceedances not rejected: p-value=%g\n", result.PValue)
+	}
+	if result := Kupiec(make([]bool, 1000), 0.01); !result.Reject(0.01) {
+		t.Errorf("no exceedances out of 1000 at 1%% not rejected: p-value=%g\n", result.PValue)
+	}
+}
+
+// TestRejectionRatesOfCorrectForecasts checks the likelihood ratio tests reject correct forecasts about as often as
+// their significance level, within a factor of 2 as the asymptotic chi-square p-values of the discrete statistics
+// are only approximate with about 50 exceedances (the independence test over-rejects)
+func TestRejectionRatesOfCorrectForecasts(t *testing.T) {
+	const numRuns, numObservations int = 1000, 1000
+	const alpha, significance float64 = 0.05, 0.05
+
+	rng := rand.New(rand.NewSource(1))
+	var rejections [3]int
+	for run := 0; run < numRuns; run++ {
+		pnl, vars, ess := simulate(numObservations, 1, 1, alpha, rng)
+		report, err := Run(pnl, vars, ess, alpha)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, result := range []TestResult{report.Kupiec, report.Independence, report.ConditionalCoverage} {
+			if result.Reject(significance) {
+				rejections[i]++
+			}
+		}
+	}
+	for i, name := range []string{"Kupiec", "independence", "conditional coverage"} {
+		rate := float64(rejections[i]) / float64(numRuns)
+		if rate < 0.5*significance || rate > 2*significance {
+			t.Errorf("%s: rejected %d out of %d runs\n", name, rejections[i], numRuns)
+		}
+	}
+}
+
+func TestChristoffersenRejectsClusteredExceedances(t *testing.T) {
+	// 10 exceedances in a row out of 1000 at 1% have the right frequency but aren't independent
+	hits := make([]bool, 1000)
+	for i := 500; i < 510; i++ {
+		hits[i] = true
+	}
+	if result := Kupiec(hits, 0.01); result.Reject(0.05) {
+		t.Errorf("Kupiec rejected the right frequency: p-value=%g\n", result.PValue)
+	}
+	if result := ChristoffersenIndependence(hits); !result.Reject(0.01) {
+		t.Errorf("independence not rejected: p-value=%g\n", result.PValue)
+	}
+	if result := ChristoffersenConditionalCoverage(hits, 0.01); !result.Reject(0.01) {
+		t.Errorf("conditional coverage not rejected: p-value=%g\n", result.PValue)
+	}
+}
+
+func TestAcerbiSzekely(t *testing.T) {
+	const testToleranceForMC float64 = 0.05
+	const numObservations int = 200000
+	const alpha float64 = 0.025
+
+	pnl, vars, ess := simulate(numObservations, 1, 1, alpha, rand.New(rand.NewSource(1)))
+	z1, z2, err := AcerbiSzekely(pnl, vars, ess, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.IsNaN(z1+z2) || math.Abs(z1) > testToleranceForMC || math.Abs(z2) > testToleranceForMC {
+		t.Errorf("correct forecasts: Z1=%g, Z2=%g\n", z1, z2)
+	}
+
+	// ES underestimated by half gives Z1 = 1 - ES / (ES / 2) = -1 for exceedances of the right VaR
+	for i := range ess {
+		ess[i] *= 0.5
+	}
+	z1, _, err = AcerbiSzekely(pnl, vars, ess, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if error := math.Abs(z1 + 1); math.IsNaN(error) || error > testToleranceForMC {
+		t.Errorf("ES underestimated by half: Z1=%g\n", z1)
+	}
+
+	// risk underestimated by half in both VaR and ES
+	pnl, vars, ess = simulate(numObservations, 2, 1, alpha, rand.New(rand.NewSource(1)))
+	report, err := Run(pnl, vars, ess, alpha)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !(report.AcerbiSzekelyZ1 < -0.2) || !(report.AcerbiSzekelyZ2 < -5) || report.TrafficLight != Red || !report.Kupiec.Reject(0.01) {
+		t.Errorf("underestimated risk not detected: %+v\n", report)
+	}
+}
+
+func TestRunReport(t *testing.T) {
+	pnl := []float64{-1, 0.5, -3, 2, -0.2, -2.5, 1, 0.1}
+	vars := []float64{2, 2, 2, 2, 2, 2, 2, 2}
+	report, err := Run(pnl, vars, nil, 0.1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Observations != 8 || report.Exceedances != 2 || math.Abs(report.ExpectedExceedances-0.8) > 1e-15 {
+		t.Errorf("unexpected counts: %+v\n", report)
+	}
+	if !math.IsNaN(report.AcerbiSzekelyZ1) || !math.IsNaN(report.AcerbiSzekelyZ2) {
+		t.Errorf("expected NaN ES statistics without ES forecasts: %+v\n", report)
+	}
+	hits, _ := Exceedances(pnl, vars)
+	if report.Kupiec != Kupiec(hits, 0.1) || report.TrafficLight != TrafficLight(2, 8, 0.1) {
+		t.Errorf("report doesn't match the individual tests: %+v\n", report)
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tables := []struct {
+		pnl   []float64
+		vars  []float64
+		ess   []float64
+		alpha float64
+		err   error
+	}{
+		{nil, nil, nil, 0.01, misc.ErrEmptySample},
+		{[]float64{1, 2}, []float64{1}, nil, 0.01, ErrLengthMismatch},
+		{[]float64{1, 2}, []float64{1, 1}, []float64{1}, 0.01, ErrLengthMismatch},
+		{[]float64{1, math.NaN()}, []float64{1, 1}, nil, 0.01, misc.ErrInvalidSample},
+		{[]float64{1, 2}, []float64{1, math.Inf(1)}, nil, 0.01, ErrInvalidForecast},
+		{[]float64{1, 2}, []float64{1, 1}, []float64{1, 0}, 0.01, ErrInvalidForecast},
+		{[]float64{1, 2}, []float64{1, 1}, nil, 0, misc.ErrProbabilityOutOfRange},
+	}
+	for i, table := range tables {
+		if _, err := Run(table.pnl, table.vars, table.ess, table.alpha); err != table.err {
+			t.Errorf("case %d: expected %v, got %v\n", i, table.err, err)
+		}
+	}
+}
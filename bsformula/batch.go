@@ -0,0 +1,110 @@
+package bsformula
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// ErrLengthMismatch is returned by the batch functions when the input and output slices differ in length
+var ErrLengthMismatch = errors.New("input and output slices have different lengths")
+
+// BSMPrices calculates Black-Scholes-Merton prices for a chain of options, see Formula.Prices
+func BSMPrices(dst []float64, S, r, q float64, K, T, sigma []float64, isCall []bool, workers int) error {
+	return Fast.Prices(dst, S, r, q, K, T, sigma, isCall, workers)
+}
+
+// BSMImpliedVols calculates Black-Scholes-Merton implied volatilities for a chain of options, see Formula.ImpliedVols
+func BSMImpliedVols(dst []float64, S, r, q float64, K, T, prices []float64, isCall []bool, workers int) error {
+	return Fast.ImpliedVols(dst, S, r, q, K, T, prices, isCall, workers)
+}
+
+// Prices writes into dst the Black-Scholes-Merton prices of the options on the underlying S with strikes K,
+// maturities T, volatilities sigma and call or put type as indicated by isCall.
+// Options at or past expiry and with zero volatility are priced as by CallPrice and PutPrice.
+// Discount factors are shared between consecutive options with the same maturity so chains sorted by expiry are fastest.
+// Nothing is allocated per option, the work is split across the given number of goroutines when workers > 1.
+func (f Formula) Prices(dst []float64, S, r, q float64, K, T, sigma []float64, isCall []bool, workers int) error {
+	n := len(dst)
+	if len(K) != n || len(T) != n || len(sigma) != n || len(isCall) != n {
+		return ErrLengthMismatch
+	}
+	runInChunks(n, workers, func(from, to int) {
+		lastT := math.NaN()
+		var tau, sqrtT, discS, discK float64
+		for i := from; i < to; i++ {
+			if T[i] != lastT {
+				lastT = T[i]
+				tau = expiry(lastT)
+				sqrtT = math.Sqrt(tau)
+				discS = S * math.Exp(-q*tau)
+				discK = math.Exp(-r * tau)
+			}
+			d1 := d1Fn(S, K[i], r-q, sigma[i], tau)
+			d2 := d1 - sigma[i]*sqrtT
+			if isCall[i] {
+				dst[i] = discS*f.cdf(d1) - K[i]*discK*f.cdf(d2)
+			} else {
+				dst[i] = K[i]*discK*f.cdf(-d2) - discS*f.cdf(-d1)
+			}
+		}
+	})
+	return nil
+}
+
+// ImpliedVols writes into dst the Black-Scholes-Merton implied volatilities of the options on the underlying S
+// with strikes K, maturities T, prices and call or put type as indicated by isCall.
+// Options for which the implied volatility can't be found are set to NaN and the error for the first of them is returned.
+// The work is split across the given number of goroutines when workers > 1.
+func (f Formula) ImpliedVols(dst []float64, S, r, q float64, K, T, prices []float64, isCall []bool, workers int) error {
+	n := len(dst)
+	if len(K) != n || len(T) != n || len(prices) != n || len(isCall) != n {
+		return ErrLengthMismatch
+	}
+	firstFailure := n
+	var firstErr error
+	var mu sync.Mutex
+	runInChunks(n, workers, func(from, to int) {
+		for i := from; i < to; i++ {
+			var err error
+			dst[i], err = f.ImpliedVol(S, K[i], r, q, T[i], prices[i], isCall[i])
+			if err != nil {
+				mu.Lock()
+				if i < firstFailure {
+					firstFailure, firstErr = i, err
+				}
+				mu.Unlock()
+			}
+		}
+	})
+	if firstErr != nil {
+		return fmt.Errorf("option %d: %w", firstFailure, firstErr)
+	}
+	return nil
+}
+
+// runInChunks calls work on contiguous chunks covering [0, n), concurrently if workers > 1
+func runInChunks(n, workers int, work func(from, to int)) {
+	if workers <= 1 || n < 2 {
+		work(0, n)
+		return
+	}
+	if workers > n {
+		workers = n
+	}
+	chunk := (n + workers - 1) / workers
+	var wg sync.WaitGroup
+	for from := 0; from < n; from += chunk {
+		to := from + chunk
+		if to > n {
+			to = n
+		}
+		wg.Add(1)
+		go func(from, to int) {
+			defer wg.Done()
+			work(from, to)
+		}(from, to)
+	}
+	wg.Wait()
+}
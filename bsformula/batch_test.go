@@ -0,0 +1,153 @@
+package bsformula
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// makeChain returns strikes, maturities, volatilities and option types for a chain
+// with numStrikes strikes around S for each of the expiries, sorted by expiry
+func makeChain(S float64, numStrikes int, expiries []float64) (K, T, sigma []float64, isCall []bool) {
+	for _, expiry := range expiries {
+		for j := 0; j < numStrikes; j++ {
+			strike := S * (0.5 + float64(j)/float64(numStrikes))
+			K = append(K, strike)
+			T = append(T, expiry)
+			// a simple smile
+			moneyness := math.Log(strike / S)
+			sigma = append(sigma, 0.5+0.3*moneyness*moneyness-0.1*moneyness)
+			isCall = append(isCall, strike >= S)
+		}
+	}
+	return
+}
+
+func TestBatchPricesMatchSingle(t *testing.T) {
+	const testTolerance float64 = 1.0e-13
+	const S, r, q = 100.0, 0.03, 0.01
+	K, T, sigma, isCall := makeChain(S, 50, []float64{1.0 / 365.25, 0.1, 0.5, 1.0})
+
+	for _, formula := range []Formula{Fast, Precise} {
+		for _, workers := range []int{1, 4} {
+			dst := make([]float64, len(K))
+			if err := formula.Prices(dst, S, r, q, K, T, sigma, isCall, workers); err != nil {
+				t.Fatalf(err.Error())
+			}
+			for i := range dst {
+				expected := formula.CallPrice(S, K[i], r, q, sigma[i], T[i])
+				if !isCall[i] {
+					expected = formula.PutPrice(S, K[i], r, q, sigma[i], T[i])
+				}
+				error := math.Abs(dst[i]-expected) / S
+				if math.IsNaN(error) || error > testTolerance {
+					t.Errorf("workers=%d, K=%g, T=%g: batch=%g, single=%g\n", workers, K[i], T[i], dst[i], expected)
+				}
+			}
+		}
+	}
+}
+
+func TestBatchImpliedVolsRoundTrip(t *testing.T) {
+	const testTolerance float64 = 1.0e-8
+	const S, r, q = 100.0, 0.03, 0.01
+	K, T, sigma, isCall := makeChain(S, 50, []float64{1.0 / 365.25, 0.1, 0.5, 1.0})
+
+	prices := make([]float64, len(K))
+	if err := Precise.Prices(prices, S, r, q, K, T, sigma, isCall, 1); err != nil {
+		t.Fatalf(err.Error())
+	}
+	for _, workers := range []int{1, 3} {
+		vols := make([]float64, len(K))
+		if err := Precise.ImpliedVols(vols, S, r, q, K, T, prices, isCall, workers); err != nil {
+			t.Fatalf(err.Error())
+		}
+		for i := range vols {
+			error := math.Abs(vols[i] - sigma[i])
+			if math.IsNaN(error) || error > testTolerance {
+				t.Errorf("workers=%d, K=%g, T=%g: implied vol=%g, sigma=%g\n", workers, K[i], T[i], vols[i], sigma[i])
+			}
+		}
+	}
+}
+
+func TestBatchImpliedVolsReportsFailures(t *testing.T) {
+	K := []float64{90, 100, 110}
+	T := []float64{1, 1, 1}
+	isCall := []bool{true, true, true}
+	prices := []float64{BSCallPrice(100, 90, 0, 0.2, 1), -1, BSCallPrice(100, 110, 0, 0.2, 1)}
+	vols := make([]float64, 3)
+
+	err := BSMImpliedVols(vols, 100, 0, 0, K, T, prices, isCall, 2)
+	if !errors.Is(err, ErrPriceBelowLowerBound) {
+		t.Errorf("Expected ErrPriceBelowLowerBound, got %v", err)
+	}
+	if math.IsNaN(vols[0]) || !math.IsNaN(vols[1]) || math.IsNaN(vols[2]) {
+		t.Errorf("Expected only the failed option to be NaN, got %v", vols)
+	}
+
+	if err := BSMPrices(make([]float64, 2), 100, 0, 0, K, T, []float64{0.2, 0.2, 0.2}, isCall, 1); err != ErrLengthMismatch {
+		t.Errorf("Expected ErrLengthMismatch, got %v", err)
+	}
+}
+
+// TestBatchDoesNotAllocatePerOption checks that the number of allocations doesn't grow with the chain length
+func TestBatchDoesNotAllocatePerOption(t *testing.T) {
+	const S, r, q = 100.0, 0.03, 0.01
+
+	allocsForChain := func(numStrikes int) (float64, float64) {
+		K, T, sigma, isCall := makeChain(S, numStrikes, []float64{0.1, 0.5})
+		prices := make([]float64, len(K))
+		vols := make([]float64, len(K))
+		pricesAllocs := testing.AllocsPerRun(10, func() {
+			BSMPrices(prices, S, r, q, K, T, sigma, isCall, 1)
+		})
+		volsAllocs := testing.AllocsPerRun(10, func() {
+			BSMImpliedVols(vols, S, r, q, K, T, prices, isCall, 1)
+		})
+		return pricesAllocs, volsAllocs
+	}
+
+	smallPrices, smallVols := allocsForChain(10)
+	largePrices, largeVols := allocsForChain(1000)
+	if largePrices > smallPrices || largeVols > smallVols {
+		t.Errorf("Allocations grow with the chain length: prices %g -> %g, implied vols %g -> %g",
+			smallPrices, largePrices, smallVols, largeVols)
+	}
+}
+
+func TestTimeTakenForBatchPricing(t *testing.T) {
+	const S, r, q = 100.0, 0.03, 0.01
+	const timingN int = 1000
+	K, T, sigma, isCall := makeChain(S, 250, []float64{1.0 / 365.25, 7.0 / 365.25, 0.1, 0.25, 0.5, 1.0})
+	dst := make([]float64, len(K))
+
+	start := time.Now()
+	for timingIdx := 0; timingIdx < timingN; timingIdx++ {
+		for i := range K {
+			if isCall[i] {
+				dst[i] = BSMCallPrice(S, K[i], r, q, sigma[i], T[i])
+			} else {
+				dst[i] = BSMPutPrice(S, K[i], r, q, sigma[i], T[i])
+			}
+		}
+	}
+	elapsedSingle := time.Since(start)
+
+	start = time.Now()
+	for timingIdx := 0; timingIdx < timingN; timingIdx++ {
+		BSMPrices(dst, S, r, q, K, T, sigma, isCall, 1)
+	}
+	elapsedBatch := time.Since(start)
+
+	start = time.Now()
+	for timingIdx := 0; timingIdx < timingN; timingIdx++ {
+		BSMPrices(dst, S, r, q, K, T, sigma, isCall, 4)
+	}
+	elapsedParallel := time.Since(start)
+
+	fmt.Printf("Num of times we can price a chain of %v options: %.1f per second one by one, %.1f per second in batch, %.1f per second in batch on 4 goroutines.\n",
+		len(K), float64(timingN)/elapsedSingle.Seconds(), float64(timingN)/elapsedBatch.Seconds(), float64(timingN)/elapsedParallel.Seconds())
+}
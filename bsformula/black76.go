@@ -0,0 +1,125 @@
+package bsformula
+
+import (
+	"math"
+
+	"code.vegaprotocol.io/quant/misc"
+)
+
+// The Black-76 model prices options on a futures (or forward) price F.
+// Instead of the interest rate the functions below take the discount factor D
+// from option expiry back to today, e.g. D = exp(-rT).
+
+// Black76CallProb1 returns the P_1 in call = D(F P_1 - K P_2)
+func Black76CallProb1(F, K, sigma, T float64) float64 {
+	return Fast.Black76CallProb1(F, K, sigma, T)
+}
+
+// Black76CallProb1 returns the P_1 in call = D(F P_1 - K P_2)
+func (f Formula) Black76CallProb1(F, K, sigma, T float64) float64 {
+	T = expiry(T)
+	return f.cdf(d1Fn(F, K, 0, sigma, T))
+}
+
+// Black76CallProb2 returns the P_2 in call = D(F P_1 - K P_2)
+func Black76CallProb2(F, K, sigma, T float64) float64 {
+	return Fast.Black76CallProb2(F, K, sigma, T)
+}
+
+// Black76CallProb2 returns the P_2 in call = D(F P_1 - K P_2)
+func (f Formula) Black76CallProb2(F, K, sigma, T float64) float64 {
+	T = expiry(T)
+	return f.cdf(d1Fn(F, K, 0, sigma, T) - sigma*math.Sqrt(T))
+}
+
+// Black76CallPrice calculates the call option price according to the Black-76 formula
+func Black76CallPrice(F, K, D, sigma, T float64) float64 {
+	return Fast.Black76CallPrice(F, K, D, sigma, T)
+}
+
+// Black76CallPrice calculates the call option price according to the Black-76 formula
+func (f Formula) Black76CallPrice(F, K, D, sigma, T float64) float64 {
+	T = expiry(T)
+	var d1 = d1Fn(F, K, 0, sigma, T)
+	var d2 = d1 - sigma*math.Sqrt(T)
+	return D * (F*f.cdf(d1) - K*f.cdf(d2))
+}
+
+// Black76PutPrice calculates the put option price according to the Black-76 formula
+func Black76PutPrice(F, K, D, sigma, T float64) float64 {
+	return Fast.Black76PutPrice(F, K, D, sigma, T)
+}
+
+// Black76PutPrice calculates the put option price according to the Black-76 formula
+func (f Formula) Black76PutPrice(F, K, D, sigma, T float64) float64 {
+	T = expiry(T)
+	var d1 = d1Fn(F, K, 0, sigma, T)
+	var d2 = d1 - sigma*math.Sqrt(T)
+	return D * (K*f.cdf(-d2) - F*f.cdf(-d1))
+}
+
+// Black76CallDelta calculates the Black-76 Delta (partial derivative w.r.t. F)
+func Black76CallDelta(F, K, D, sigma, T float64) float64 {
+	return Fast.Black76CallDelta(F, K, D, sigma, T)
+}
+
+// Black76CallDelta calculates the Black-76 Delta (partial derivative w.r.t. F)
+func (f Formula) Black76CallDelta(F, K, D, sigma, T float64) float64 {
+	T = expiry(T)
+	return D * f.cdf(d1Fn(F, K, 0, sigma, T))
+}
+
+// Black76PutDelta calculates the Black-76 Delta (partial derivative w.r.t. F)
+func Black76PutDelta(F, K, D, sigma, T float64) float64 {
+	return Fast.Black76PutDelta(F, K, D, sigma, T)
+}
+
+// Black76PutDelta calculates the Black-76 Delta (partial derivative w.r.t. F)
+func (f Formula) Black76PutDelta(F, K, D, sigma, T float64) float64 {
+	T = expiry(T)
+	return -D * f.cdf(-d1Fn(F, K, 0, sigma, T))
+}
+
+// Black76Gamma calculates the Black-76 Gamma (second partial derivative w.r.t. F)
+// Note that it's identical for both puts and calls
+func Black76Gamma(F, K, D, sigma, T float64) float64 {
+	T = expiry(T)
+	if degenerate(sigma, T) {
+		return 0
+	}
+	d1 := d1Fn(F, K, 0, sigma, T)
+	return D * misc.GaussDensity(d1) / (F * sigma * math.Sqrt(T))
+}
+
+// Black76Vega calculates the Black-76 Vega (partial derivative w.r.t. sigma)
+// Note that it's identical for both puts and calls
+func Black76Vega(F, K, D, sigma, T float64) float64 {
+	T = expiry(T)
+	d1 := d1Fn(F, K, 0, sigma, T)
+	return D * F * misc.GaussDensity(d1) * math.Sqrt(T)
+}
+
+// Black76ImpliedVol calculates the Black-76 implied volatility
+// from call or put price as indicated by isCall
+func Black76ImpliedVol(F, K, D, T, price float64, isCall bool) (float64, error) {
+	return Fast.Black76ImpliedVol(F, K, D, T, price, isCall)
+}
+
+// Black76ImpliedVol calculates the Black-76 implied volatility
+// from call or put price as indicated by isCall.
+// It returns ErrPriceBelowLowerBound or ErrPriceAboveUpperBound when the price violates the no-arbitrage bounds
+// and ErrImpliedVolNotFound when the solver fails.
+func (f Formula) Black76ImpliedVol(F, K, D, T, price float64, isCall bool) (float64, error) {
+	priceAsFnOfSigma := func(sigma float64) float64 {
+		return f.Black76CallPrice(F, K, D, sigma, T)
+	}
+	if !isCall {
+		priceAsFnOfSigma = func(sigma float64) float64 {
+			return f.Black76PutPrice(F, K, D, sigma, T)
+		}
+	}
+	vegaAsFnOfSigma := func(sigma float64) float64 {
+		return Black76Vega(F, K, D, sigma, T)
+	}
+	return solveImpliedVol(priceAsFnOfSigma, vegaAsFnOfSigma, D*F, D*K, T, price, isCall)
+}
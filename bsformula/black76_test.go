@@ -0,0 +1,95 @@
+package bsformula
+
+import (
+	"math"
+	"testing"
+)
+
+// the inputs are F, K, r, sigma, T; the discount factor is exp(-rT)
+var testValuesBlack76 = []struct {
+	F     float64
+	K     float64
+	r     float64
+	sigma float64
+	T     float64
+}{
+	{1.0, 1.0, 0.02, 0.5, 0.25},  // test at the money
+	{1.0, 1.0, -0.02, 0.5, 0.25}, // test negative interest rate
+	{1.0, 1.5, 0.02, 0.5, 0.25},  // test high strike
+	{1.0, 0.5, 0.02, 0.5, 0.25},  // test low strike
+	{1.0, 0.5, 0.0, 0.1, 2},      // test long maturity
+	{75, 80, 0.03, 0.1, 2},       // test non-unit strike / mat
+}
+
+// TestBlack76MatchesBSMWithFullCarry checks Black-76 against BSM with q = r,
+// i.e. an underlying with zero cost of carry
+func TestBlack76MatchesBSMWithFullCarry(t *testing.T) {
+	const testTolerance float64 = 1.0e-12
+
+	for _, table := range testValuesBlack76 {
+		F, K, r, sigma, T := table.F, table.K, table.r, table.sigma, table.T
+		D := math.Exp(-r * T)
+
+		error := math.Abs(Black76CallPrice(F, K, D, sigma, T)-BSMCallPrice(F, K, r, r, sigma, T)) +
+			math.Abs(Black76PutPrice(F, K, D, sigma, T)-BSMPutPrice(F, K, r, r, sigma, T)) +
+			math.Abs(Black76CallDelta(F, K, D, sigma, T)-BSMCallDelta(F, K, r, r, sigma, T)) +
+			math.Abs(Black76PutDelta(F, K, D, sigma, T)-BSMPutDelta(F, K, r, r, sigma, T)) +
+			math.Abs(Black76Gamma(F, K, D, sigma, T)-BSMGamma(F, K, r, r, sigma, T)) +
+			math.Abs(Black76Vega(F, K, D, sigma, T)-BSMVega(F, K, r, r, sigma, T))
+		error /= F
+
+		if math.IsNaN(error) || math.IsInf(error, 0) || error > testTolerance {
+			t.Errorf("F=%g, K=%g, r=%g, sigma=%g, T=%g, error=%g is greater than tolerance.\n",
+				F, K, r, sigma, T, error)
+		}
+
+		call := Black76CallPrice(F, K, D, sigma, T)
+		direct := D * (F*Black76CallProb1(F, K, sigma, T) - K*Black76CallProb2(F, K, sigma, T))
+		if math.Abs(call-direct)/F > testTolerance {
+			t.Errorf("F=%g, K=%g, r=%g, sigma=%g, T=%g, call price not consistent with probabilities.\n",
+				F, K, r, sigma, T)
+		}
+	}
+}
+
+func TestBlack76GreeksVsFiniteDifference(t *testing.T) {
+	const bump float64 = 1.0e-7
+
+	for _, table := range testValuesBlack76 {
+		F, K, r, sigma, T := table.F, table.K, table.r, table.sigma, table.T
+		D := math.Exp(-r * T)
+
+		checkGreekVsFiniteDifference(t, "call delta", Black76CallDelta(F, K, D, sigma, T),
+			func(h float64) float64 { return Black76CallPrice(F+h, K, D, sigma, T) }, bump, F, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "put delta", Black76PutDelta(F, K, D, sigma, T),
+			func(h float64) float64 { return Black76PutPrice(F+h, K, D, sigma, T) }, bump, F, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "gamma", Black76Gamma(F, K, D, sigma, T),
+			func(h float64) float64 { return Black76CallDelta(F+h, K, D, sigma, T) }, bump, F, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "vega", Black76Vega(F, K, D, sigma, T),
+			func(h float64) float64 { return Black76PutPrice(F, K, D, sigma+h, T) }, bump, F, K, r, sigma, T)
+	}
+}
+
+func TestBlack76ImpliedVolCalcs(t *testing.T) {
+	const testTolerance float64 = 1.0e-5
+
+	for _, table := range testValuesBlack76 {
+		F, K, r, sigma, T := table.F, table.K, table.r, table.sigma, table.T
+		D := math.Exp(-r * T)
+
+		volFromCall, errCall := Black76ImpliedVol(F, K, D, T, Black76CallPrice(F, K, D, sigma, T), true)
+		if errCall != nil {
+			t.Errorf(errCall.Error())
+		}
+		volFromPut, errPut := Black76ImpliedVol(F, K, D, T, Black76PutPrice(F, K, D, sigma, T), false)
+		if errPut != nil {
+			t.Errorf(errPut.Error())
+		}
+
+		error := math.Abs(volFromCall-sigma) + math.Abs(volFromPut-sigma)
+		if math.IsNaN(error) || math.IsInf(error, 0) || error > testTolerance {
+			t.Errorf("F=%g, K=%g, r=%g, sigma=%g, T=%g, error=%g is greater than tolerance.\n",
+				F, K, r, sigma, T, error)
+		}
+	}
+}
@@ -6,80 +6,209 @@ import (
 	"code.vegaprotocol.io/quant/misc"
 )
 
-func d1Fn(S, K, r, sigma, T float64) float64 {
-	return (math.Log(S/K) + (r+sigma*sigma*0.5)*T) / (sigma * math.Sqrt(T))
+// Formula evaluates the Black-Scholes(-Merton) and Black-76 formulas using Cdf
+// as the distribution of N(0,1), allowing callers to choose between speed and accuracy.
+// The zero value uses misc.ApproxGaussCdf as Fast does.
+type Formula struct {
+	Cdf func(float64) float64
+}
+
+var (
+	// Fast uses misc.ApproxGaussCdf which is accurate to about 1e-5, it is what the package level functions use
+	Fast = Formula{Cdf: misc.ApproxGaussCdf}
+	// Precise uses misc.GaussCdf which is accurate to double precision
+	Precise = Formula{Cdf: misc.GaussCdf}
+)
+
+// cdf returns the distribution of N(0,1) at x, falling back to misc.ApproxGaussCdf when Cdf isn't set
+func (f Formula) cdf(x float64) float64 {
+	if f.Cdf == nil {
+		return misc.ApproxGaussCdf(x)
+	}
+	return f.Cdf(x)
+}
+
+// expiry returns the time to maturity T with the options past expiry treated as at expiry,
+// i.e. worth their intrinsic value
+func expiry(T float64) float64 {
+	return math.Max(T, 0)
+}
+
+// degenerate reports whether sigma sqrt(T) is zero, i.e. the volatility is zero or the option is at expiry,
+// so that the price is the (discounted) intrinsic value on the forward and the deltas are step functions
+func degenerate(sigma, T float64) bool {
+	return sigma == 0 || T == 0
+}
+
+// d1Fn returns d1 for the cost of carry b (b = r for the plain BS model and b = r - q with a yield q).
+// When sigma sqrt(T) is zero it returns the limit of d1 (and d2): +Inf if the forward is above the strike,
+// -Inf if it is below and 0 at the strike.
+func d1Fn(S, K, b, sigma, T float64) float64 {
+	if degenerate(sigma, T) {
+		x := math.Log(S/K) + b*T
+		switch {
+		case x > 0:
+			return math.Inf(1)
+		case x < 0:
+			return math.Inf(-1)
+		}
+		return x
+	}
+	return (math.Log(S/K) + (b+sigma*sigma*0.5)*T) / (sigma * math.Sqrt(T))
 }
 
 // BSCallProb1 returns the P_1 in call = S P_1 - Ke^(-rT)P_2
 func BSCallProb1(S, K, r, sigma, T float64) float64 {
-	var d1 = d1Fn(S, K, r, sigma, T)
-	return misc.ApproxGaussCdf(d1)
+	return Fast.CallProb1(S, K, r, 0, sigma, T)
+}
+
+// BSMCallProb1 returns the P_1 in call = Se^(-qT) P_1 - Ke^(-rT)P_2
+// where q is the continuous dividend (or carry) yield
+func BSMCallProb1(S, K, r, q, sigma, T float64) float64 {
+	return Fast.CallProb1(S, K, r, q, sigma, T)
+}
+
+// CallProb1 returns the P_1 in call = Se^(-qT) P_1 - Ke^(-rT)P_2
+// where q is the continuous dividend (or carry) yield
+func (f Formula) CallProb1(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	var d1 = d1Fn(S, K, r-q, sigma, T)
+	return f.cdf(d1)
 }
 
 // BSCallProb2 returns the P_2 in call = S P_1 - Ke^(-rT)P_2
 func BSCallProb2(S, K, r, sigma, T float64) float64 {
-	var d1 = d1Fn(S, K, r, sigma, T)
+	return Fast.CallProb2(S, K, r, 0, sigma, T)
+}
+
+// BSMCallProb2 returns the P_2 in call = Se^(-qT) P_1 - Ke^(-rT)P_2
+// where q is the continuous dividend (or carry) yield
+func BSMCallProb2(S, K, r, q, sigma, T float64) float64 {
+	return Fast.CallProb2(S, K, r, q, sigma, T)
+}
+
+// CallProb2 returns the P_2 in call = Se^(-qT) P_1 - Ke^(-rT)P_2
+// where q is the continuous dividend (or carry) yield
+func (f Formula) CallProb2(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	var d1 = d1Fn(S, K, r-q, sigma, T)
 	var d2 = d1 - sigma*math.Sqrt(T)
-	return misc.ApproxGaussCdf(d2)
+	return f.cdf(d2)
 }
 
 // BSCallPrice calculates the call option price according to the BS formula
 func BSCallPrice(S, K, r, sigma, T float64) float64 {
-	var d1 = d1Fn(S, K, r, sigma, T)
-	var d2 = d1 - sigma*math.Sqrt(T)
-	return S*misc.ApproxGaussCdf(d1) - K*math.Exp(-r*T)*misc.ApproxGaussCdf(d2)
+	return Fast.CallPrice(S, K, r, 0, sigma, T)
 }
 
-func getPutFromCallPrice(S, K, r, T, callPrice float64) float64 {
-	return callPrice - S + K*math.Exp(-r*T)
+// BSMCallPrice calculates the call option price according to the Black-Scholes-Merton formula
+// with continuous dividend (or carry) yield q. Use q = r_f for FX options with foreign rate r_f.
+func BSMCallPrice(S, K, r, q, sigma, T float64) float64 {
+	return Fast.CallPrice(S, K, r, q, sigma, T)
+}
+
+// CallPrice calculates the call option price according to the Black-Scholes-Merton formula
+// with continuous dividend (or carry) yield q
+func (f Formula) CallPrice(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	var d1 = d1Fn(S, K, r-q, sigma, T)
+	var d2 = d1 - sigma*math.Sqrt(T)
+	return S*math.Exp(-q*T)*f.cdf(d1) - K*math.Exp(-r*T)*f.cdf(d2)
 }
 
 // BSPutPrice calculates the put option price according to the BS formula
 func BSPutPrice(S, K, r, sigma, T float64) float64 {
-	return getPutFromCallPrice(S, K, r, T, BSCallPrice(S, K, r, sigma, T))
+	return Fast.PutPrice(S, K, r, 0, sigma, T)
+}
+
+// BSMPutPrice calculates the put option price according to the Black-Scholes-Merton formula
+// with continuous dividend (or carry) yield q
+func BSMPutPrice(S, K, r, q, sigma, T float64) float64 {
+	return Fast.PutPrice(S, K, r, q, sigma, T)
+}
+
+// PutPrice calculates the put option price according to the Black-Scholes-Merton formula
+// with continuous dividend (or carry) yield q
+func (f Formula) PutPrice(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	var d1 = d1Fn(S, K, r-q, sigma, T)
+	var d2 = d1 - sigma*math.Sqrt(T)
+	return K*math.Exp(-r*T)*f.cdf(-d2) - S*math.Exp(-q*T)*f.cdf(-d1)
 }
 
 // BSCallDelta calculates the BS Delta (partial derivative w.r.t. S)
 func BSCallDelta(S, K, r, sigma, T float64) float64 {
-	return misc.ApproxGaussCdf(d1Fn(S, K, r, sigma, T))
+	return Fast.CallDelta(S, K, r, 0, sigma, T)
+}
+
+// BSMCallDelta calculates the BSM Delta (partial derivative w.r.t. S) with yield q
+func BSMCallDelta(S, K, r, q, sigma, T float64) float64 {
+	return Fast.CallDelta(S, K, r, q, sigma, T)
+}
+
+// CallDelta calculates the BSM Delta (partial derivative w.r.t. S) with yield q
+func (f Formula) CallDelta(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	return math.Exp(-q*T) * f.cdf(d1Fn(S, K, r-q, sigma, T))
 }
 
 // BSPutDelta calculates the BS Delta (partial derivative w.r.t. S)
 func BSPutDelta(S, K, r, sigma, T float64) float64 {
-	return -misc.ApproxGaussCdf(-d1Fn(S, K, r, sigma, T))
+	return Fast.PutDelta(S, K, r, 0, sigma, T)
+}
+
+// BSMPutDelta calculates the BSM Delta (partial derivative w.r.t. S) with yield q
+func BSMPutDelta(S, K, r, q, sigma, T float64) float64 {
+	return Fast.PutDelta(S, K, r, q, sigma, T)
+}
+
+// PutDelta calculates the BSM Delta (partial derivative w.r.t. S) with yield q
+func (f Formula) PutDelta(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	return -math.Exp(-q*T) * f.cdf(-d1Fn(S, K, r-q, sigma, T))
 }
 
 // BSVega calculates the BS Vega (partial derivative w.r.t. sigma)
 // Note that it's identical for both puts and calls
 func BSVega(S, K, r, sigma, T float64) float64 {
-	d1 := d1Fn(S, K, r, sigma, T)
-	return S * misc.GaussDensity(d1) * math.Sqrt(T)
+	return BSMVega(S, K, r, 0, sigma, T)
+}
+
+// BSMVega calculates the BSM Vega (partial derivative w.r.t. sigma) with yield q
+// Note that it's identical for both puts and calls
+func BSMVega(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	d1 := d1Fn(S, K, r-q, sigma, T)
+	return S * math.Exp(-q*T) * misc.GaussDensity(d1) * math.Sqrt(T)
 }
 
 // ImpliedVol calculates the implied volatility
 // from call or put price as indicated by isCall
 func ImpliedVol(S, K, r, T, price float64, isCall bool) (float64, error) {
-	const solverTol = 1e-12
-	const solverMaxIt = 100
-
-	var guessVol = 0.8
-	var bsAsFnOfSigma func(sigma float64) float64
-	if isCall {
-		bsAsFnOfSigma = func(sigma float64) float64 {
-			return BSCallPrice(S, K, r, sigma, T) - price
-		}
-	} else {
-		bsAsFnOfSigma = func(sigma float64) float64 {
-			return BSPutPrice(S, K, r, sigma, T) - price
-		}
+	return Fast.ImpliedVol(S, K, r, 0, T, price, isCall)
+}
+
+// BSMImpliedVol calculates the implied volatility in the Black-Scholes-Merton model
+// with yield q from call or put price as indicated by isCall
+func BSMImpliedVol(S, K, r, q, T, price float64, isCall bool) (float64, error) {
+	return Fast.ImpliedVol(S, K, r, q, T, price, isCall)
+}
+
+// ImpliedVol calculates the implied volatility in the Black-Scholes-Merton model
+// with yield q from call or put price as indicated by isCall.
+// It returns ErrPriceBelowLowerBound or ErrPriceAboveUpperBound when the price violates the no-arbitrage bounds
+// and ErrImpliedVolNotFound when the solver fails.
+func (f Formula) ImpliedVol(S, K, r, q, T, price float64, isCall bool) (float64, error) {
+	priceAsFnOfSigma := func(sigma float64) float64 {
+		return f.CallPrice(S, K, r, q, sigma, T)
 	}
-	bsAsFnOfSigmaPrime := func(sigma float64) float64 {
-		return BSVega(S, K, r, sigma, T)
+	if !isCall {
+		priceAsFnOfSigma = func(sigma float64) float64 {
+			return f.PutPrice(S, K, r, q, sigma, T)
+		}
 	}
-
-	impliedVol, err := misc.FindRoot(bsAsFnOfSigma, bsAsFnOfSigmaPrime, guessVol, solverMaxIt, solverTol)
-	if err != nil {
-		return math.NaN(), err
+	vegaAsFnOfSigma := func(sigma float64) float64 {
+		return BSMVega(S, K, r, q, sigma, T)
 	}
-	return impliedVol, nil
+	return solveImpliedVol(priceAsFnOfSigma, vegaAsFnOfSigma, S*math.Exp(-q*T), K*math.Exp(-r*T), T, price, isCall)
 }
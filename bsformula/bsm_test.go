@@ -0,0 +1,141 @@
+package bsformula
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// the inputs are S, K, r, q, sigma, T
+var testValuesBSM = []struct {
+	S     float64
+	K     float64
+	r     float64
+	q     float64
+	sigma float64
+	T     float64
+}{
+	{1.0, 1.0, 0.02, 0.03, 0.5, 0.25},  // test at the money
+	{1.0, 1.0, -0.02, 0.01, 0.5, 0.25}, // test negative interest rate
+	{1.0, 1.5, 0.02, 0.05, 0.5, 0.25},  // test high strike
+	{1.0, 0.5, 0.02, 0.05, 0.5, 0.25},  // test low strike
+	{1.0, 0.5, 0.0, -0.01, 0.5, 0.25},  // test negative yield
+	{1.0, 0.5, 0.0, 0.02, 0.1, 2},      // test long maturity
+	{75, 80, 0.01, 0.01, 0.1, 2},       // test futures-like carry (b = 0)
+}
+
+// TestBSMPricesUsingMonteCarlo we use MC simulation to check call
+// and put prices with a yield.
+func TestBSMPricesUsingMonteCarlo(t *testing.T) {
+	const testToleranceForMC float64 = 1.0e-3
+	const numIndepMCSamples int = 20000
+
+	numMCSamples := 2 * numIndepMCSamples
+	Z := make([]float64, numMCSamples)
+	for i := 0; i < numIndepMCSamples; i++ {
+		z := distuv.UnitNormal.Rand()
+		Z[i] = z
+		Z[numIndepMCSamples+i] = -z // antithetic sample
+	}
+
+	for _, table := range testValuesBSM {
+		S, K, r, q, sigma, T := table.S, table.K, table.r, table.q, table.sigma, table.T
+
+		bsmCall := BSMCallPrice(S, K, r, q, sigma, T)
+		bsmPut := BSMPutPrice(S, K, r, q, sigma, T)
+		var callPayoff float64
+		var putPayoff float64
+		for i := 0; i < numMCSamples; i++ {
+			SatT := S * math.Exp((r-q-0.5*sigma*sigma)*T+sigma*math.Sqrt(T)*Z[i])
+			callPayoff += math.Max(SatT-K, 0.0)
+			putPayoff += math.Max(K-SatT, 0.0)
+		}
+		mcCall := math.Exp(-r*T) * callPayoff / float64(numMCSamples)
+		mcPut := math.Exp(-r*T) * putPayoff / float64(numMCSamples)
+		error := (math.Abs(mcCall-bsmCall) + math.Abs(mcPut-bsmPut)) / S
+		if math.IsNaN(error) || math.IsInf(error, 0) || error > testToleranceForMC {
+			t.Errorf("S=%g, K=%g, r=%g, q=%g, sigma=%g, T=%g, error=%g is greater than tolerance.\n",
+				S, K, r, q, sigma, T, error)
+		}
+	}
+}
+
+// TestBSMReducesToBS checks that a zero yield gives back the plain BS formulas
+func TestBSMReducesToBS(t *testing.T) {
+	for _, table := range testValues {
+		S, K, r, sigma, T := table.S, table.K, table.r, table.sigma, table.T
+
+		if BSMCallPrice(S, K, r, 0, sigma, T) != BSCallPrice(S, K, r, sigma, T) ||
+			BSMPutPrice(S, K, r, 0, sigma, T) != BSPutPrice(S, K, r, sigma, T) ||
+			BSMCallDelta(S, K, r, 0, sigma, T) != BSCallDelta(S, K, r, sigma, T) ||
+			BSMGamma(S, K, r, 0, sigma, T) != BSGamma(S, K, r, sigma, T) ||
+			BSMVega(S, K, r, 0, sigma, T) != BSVega(S, K, r, sigma, T) {
+			t.Errorf("S=%g, K=%g, r=%g, sigma=%g, T=%g: BSM with q=0 differs from BS.\n", S, K, r, sigma, T)
+		}
+	}
+}
+
+// TestBSMGreeksVsFiniteDifference we use finite differences of the BSM prices
+// and greeks to check the closed-form greeks with a yield
+func TestBSMGreeksVsFiniteDifference(t *testing.T) {
+	const bump float64 = 1.0e-7
+
+	for _, table := range testValuesBSM {
+		S, K, r, q, sigma, T := table.S, table.K, table.r, table.q, table.sigma, table.T
+
+		checkGreekVsFiniteDifference(t, "call delta", BSMCallDelta(S, K, r, q, sigma, T),
+			func(h float64) float64 { return BSMCallPrice(S+h, K, r, q, sigma, T) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "put delta", BSMPutDelta(S, K, r, q, sigma, T),
+			func(h float64) float64 { return BSMPutPrice(S+h, K, r, q, sigma, T) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "vega", BSMVega(S, K, r, q, sigma, T),
+			func(h float64) float64 { return BSMCallPrice(S, K, r, q, sigma+h, T) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "gamma", BSMGamma(S, K, r, q, sigma, T),
+			func(h float64) float64 { return BSMCallDelta(S+h, K, r, q, sigma, T) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "call theta", BSMCallTheta(S, K, r, q, sigma, T),
+			func(h float64) float64 { return -BSMCallPrice(S, K, r, q, sigma, T+h) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "put theta", BSMPutTheta(S, K, r, q, sigma, T),
+			func(h float64) float64 { return -BSMPutPrice(S, K, r, q, sigma, T+h) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "call rho", BSMCallRho(S, K, r, q, sigma, T),
+			func(h float64) float64 { return BSMCallPrice(S, K, r+h, q, sigma, T) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "put rho", BSMPutRho(S, K, r, q, sigma, T),
+			func(h float64) float64 { return BSMPutPrice(S, K, r+h, q, sigma, T) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "call phi", BSMCallPhi(S, K, r, q, sigma, T),
+			func(h float64) float64 { return BSMCallPrice(S, K, r, q+h, sigma, T) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "put phi", BSMPutPhi(S, K, r, q, sigma, T),
+			func(h float64) float64 { return BSMPutPrice(S, K, r, q+h, sigma, T) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "vanna", BSMVanna(S, K, r, q, sigma, T),
+			func(h float64) float64 { return BSMVega(S+h, K, r, q, sigma, T) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "volga", BSMVolga(S, K, r, q, sigma, T),
+			func(h float64) float64 { return BSMVega(S, K, r, q, sigma+h, T) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "call charm", BSMCallCharm(S, K, r, q, sigma, T),
+			func(h float64) float64 { return -BSMCallDelta(S, K, r, q, sigma, T+h) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "put charm", BSMPutCharm(S, K, r, q, sigma, T),
+			func(h float64) float64 { return -BSMPutDelta(S, K, r, q, sigma, T+h) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "speed", BSMSpeed(S, K, r, q, sigma, T),
+			func(h float64) float64 { return BSMGamma(S+h, K, r, q, sigma, T) }, bump, S, K, r, sigma, T)
+	}
+}
+
+func TestBSMImpliedVolCalcs(t *testing.T) {
+	const testTolerance float64 = 1.0e-5
+
+	for _, table := range testValuesBSM {
+		S, K, r, q, sigma, T := table.S, table.K, table.r, table.q, table.sigma, table.T
+
+		volFromCall, errCall := BSMImpliedVol(S, K, r, q, T, BSMCallPrice(S, K, r, q, sigma, T), true)
+		if errCall != nil {
+			t.Errorf(errCall.Error())
+		}
+		volFromPut, errPut := BSMImpliedVol(S, K, r, q, T, BSMPutPrice(S, K, r, q, sigma, T), false)
+		if errPut != nil {
+			t.Errorf(errPut.Error())
+		}
+
+		error := math.Abs(volFromCall-sigma) + math.Abs(volFromPut-sigma)
+		if math.IsNaN(error) || math.IsInf(error, 0) || error > testTolerance {
+			t.Errorf("S=%g, K=%g, r=%g, q=%g, sigma=%g, T=%g, error=%g is greater than tolerance.\n",
+				S, K, r, q, sigma, T, error)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package bsformula
+
+import (
+	"math"
+	"testing"
+)
+
+// TestDegenerateInputs checks the limits of the prices and greeks at expiry, past expiry and with zero volatility
+func TestDegenerateInputs(t *testing.T) {
+	const r, q = 0.05, 0.02
+	dfR, dfQ := math.Exp(-r), math.Exp(-q)
+
+	tables := []struct {
+		name                   string
+		S, K, sigma, T         float64
+		call, put              float64
+		callDelta, putDelta    float64
+		vega, gamma, callTheta float64
+	}{
+		{"expiry in the money", 110, 100, 0.3, 0, 10, 0, 1, 0, 0, 0, -r*100 + q*110},
+		{"expiry out of the money", 90, 100, 0.3, 0, 0, 10, 0, -1, 0, 0, 0},
+		{"expiry at the money", 100, 100, 0.3, 0, 0, 0, 0.5, -0.5, 0, 0, 0.5 * (-r*100 + q*100)},
+		{"past expiry", 110, 100, 0.3, -0.1, 10, 0, 1, 0, 0, 0, -r*100 + q*110},
+		{"zero volatility forward above strike", 100, 100, 0, 1, 100*dfQ - 100*dfR, 0, dfQ, 0, 0, 0, -r*100*dfR + q*100*dfQ},
+		{"zero volatility forward below strike", 100, 110, 0, 1, 0, 110*dfR - 100*dfQ, 0, -dfQ, 0, 0, 0},
+		{"zero volatility and expiry", 100, 90, 0, 0, 10, 0, 1, 0, 0, 0, -r*90 + q*100},
+	}
+
+	for _, f := range []struct {
+		name      string
+		formula   Formula
+		tolerance float64
+	}{{"fast", Fast, 1e-5}, {"precise", Precise, 1e-12}} {
+		for _, table := range tables {
+			S, K, sigma, T := table.S, table.K, table.sigma, table.T
+			values := []struct {
+				name            string
+				value, expected float64
+			}{
+				{"call", f.formula.CallPrice(S, K, r, q, sigma, T), table.call},
+				{"put", f.formula.PutPrice(S, K, r, q, sigma, T), table.put},
+				{"call delta", f.formula.CallDelta(S, K, r, q, sigma, T), table.callDelta},
+				{"put delta", f.formula.PutDelta(S, K, r, q, sigma, T), table.putDelta},
+				{"vega", BSMVega(S, K, r, q, sigma, T), table.vega},
+				{"gamma", BSMGamma(S, K, r, q, sigma, T), table.gamma},
+				{"call theta", f.formula.CallTheta(S, K, r, q, sigma, T), table.callTheta},
+			}
+			for _, v := range values {
+				error := math.Abs(v.value - v.expected)
+				if math.IsNaN(error) || math.IsInf(error, 0) || error > f.tolerance*math.Max(1, math.Abs(v.expected)) {
+					t.Errorf("%s, %s: %s=%g, expected=%g\n", f.name, table.name, v.name, v.value, v.expected)
+				}
+			}
+
+			greeks := []float64{
+				f.formula.CallProb1(S, K, r, q, sigma, T), f.formula.CallProb2(S, K, r, q, sigma, T),
+				f.formula.PutTheta(S, K, r, q, sigma, T), f.formula.CallRho(S, K, r, q, sigma, T), f.formula.PutRho(S, K, r, q, sigma, T),
+				f.formula.CallPhi(S, K, r, q, sigma, T), f.formula.PutPhi(S, K, r, q, sigma, T),
+				BSMVanna(S, K, r, q, sigma, T), BSMVolga(S, K, r, q, sigma, T), BSMSpeed(S, K, r, q, sigma, T),
+				f.formula.CallCharm(S, K, r, q, sigma, T), f.formula.PutCharm(S, K, r, q, sigma, T),
+				f.formula.Black76CallPrice(S, K, dfR, sigma, T), f.formula.Black76PutPrice(S, K, dfR, sigma, T),
+				f.formula.Black76CallDelta(S, K, dfR, sigma, T), f.formula.Black76PutDelta(S, K, dfR, sigma, T),
+				Black76Gamma(S, K, dfR, sigma, T), Black76Vega(S, K, dfR, sigma, T),
+			}
+			for i, greek := range greeks {
+				if math.IsNaN(greek) || math.IsInf(greek, 0) {
+					t.Errorf("%s, %s: greek %d=%g\n", f.name, table.name, i, greek)
+				}
+			}
+
+			prices := make([]float64, 2)
+			if err := f.formula.Prices(prices, S, r, q, []float64{K, K}, []float64{T, T}, []float64{sigma, sigma},
+				[]bool{true, false}, 1); err != nil {
+				t.Fatalf(err.Error())
+			}
+			for i, expected := range []float64{table.call, table.put} {
+				error := math.Abs(prices[i] - expected)
+				if math.IsNaN(error) || error > f.tolerance*math.Max(1, math.Abs(expected)) {
+					t.Errorf("%s, %s: batch price %d=%g, expected=%g\n", f.name, table.name, i, prices[i], expected)
+				}
+			}
+		}
+	}
+}
+
+// TestExpiryIsLimit checks that away from the strike the prices and deltas at expiry are the limits as T goes to 0
+// and with zero volatility the limits as sigma goes to 0
+func TestExpiryIsLimit(t *testing.T) {
+	const testTolerance float64 = 1e-8
+	const r, q = 0.05, 0.02
+
+	for _, K := range []float64{80, 95, 105, 120} {
+		for _, limit := range []struct{ sigma, T, sigmaLimit, TLimit float64 }{
+			{0.5, 1e-10, 0.5, 0},
+			{1e-10, 0.5, 0, 0.5},
+		} {
+			for _, fn := range []func(S, K, r, q, sigma, T float64) float64{
+				Precise.CallPrice, Precise.PutPrice, Precise.CallDelta, Precise.PutDelta, BSMVega, BSMGamma,
+			} {
+				value := fn(100, K, r, q, limit.sigma, limit.T)
+				expected := fn(100, K, r, q, limit.sigmaLimit, limit.TLimit)
+				error := math.Abs(value - expected)
+				if math.IsNaN(error) || error > testTolerance {
+					t.Errorf("K=%g, sigma=%g, T=%g: value=%g, limit=%g\n", K, limit.sigma, limit.T, value, expected)
+				}
+			}
+		}
+	}
+}
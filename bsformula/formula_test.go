@@ -0,0 +1,97 @@
+package bsformula
+
+import (
+	"math"
+	"testing"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// referencePrices returns call and put prices computed with the gonum normal CDF
+func referencePrices(S, K, r, q, sigma, T float64) (float64, float64) {
+	d1 := (math.Log(S/K) + (r-q+sigma*sigma*0.5)*T) / (sigma * math.Sqrt(T))
+	d2 := d1 - sigma*math.Sqrt(T)
+	call := S*math.Exp(-q*T)*distuv.UnitNormal.CDF(d1) - K*math.Exp(-r*T)*distuv.UnitNormal.CDF(d2)
+	put := K*math.Exp(-r*T)*distuv.UnitNormal.CDF(-d2) - S*math.Exp(-q*T)*distuv.UnitNormal.CDF(-d1)
+	return call, put
+}
+
+// TestPriceErrorAgainstGonumReference measures the price error of the fast and precise
+// formulas against prices computed with the gonum UnitNormal CDF
+func TestPriceErrorAgainstGonumReference(t *testing.T) {
+	// the fast approximation is only accurate to about 1e-5 so the errors in
+	// S N(d1) and K N(d2) are of order 1e-5 (S + K)
+	const fastTolerance float64 = 5.0e-5
+	const preciseTolerance float64 = 1.0e-14
+
+	var maxFastError, maxPreciseError float64
+	for _, K := range []float64{0.25, 0.5, 0.8, 0.95, 1.0, 1.05, 1.2, 2.0, 4.0} {
+		for _, sigma := range []float64{0.05, 0.2, 0.5, 1.5} {
+			for _, T := range []float64{1.0 / 365.25, 0.25, 1.0, 5.0} {
+				const S, r, q = 1.0, 0.03, 0.01
+				refCall, refPut := referencePrices(S, K, r, q, sigma, T)
+
+				fastError := math.Abs(Fast.CallPrice(S, K, r, q, sigma, T)-refCall) +
+					math.Abs(Fast.PutPrice(S, K, r, q, sigma, T)-refPut)
+				preciseError := math.Abs(Precise.CallPrice(S, K, r, q, sigma, T)-refCall) +
+					math.Abs(Precise.PutPrice(S, K, r, q, sigma, T)-refPut)
+
+				maxFastError = math.Max(maxFastError, fastError/(S+K))
+				maxPreciseError = math.Max(maxPreciseError, preciseError/(S+K))
+			}
+		}
+	}
+
+	t.Logf("max price error: fast=%g, precise=%g\n", maxFastError, maxPreciseError)
+	if math.IsNaN(maxFastError) || maxFastError > fastTolerance {
+		t.Errorf("Fast price error=%g is greater than tolerance %g\n", maxFastError, fastTolerance)
+	}
+	if math.IsNaN(maxPreciseError) || maxPreciseError > preciseTolerance {
+		t.Errorf("Precise price error=%g is greater than tolerance %g\n", maxPreciseError, preciseTolerance)
+	}
+}
+
+// TestPreciseDeepOutOfTheMoney checks that deep out of the money prices are non-negative
+// and keep their relative accuracy
+func TestPreciseDeepOutOfTheMoney(t *testing.T) {
+	const relTolerance float64 = 1.0e-8
+	const S, r, q, sigma, T = 1.0, 0.0, 0.0, 0.2, 0.25
+
+	for _, K := range []float64{1.5, 2.0, 3.0} {
+		refCall, _ := referencePrices(S, K, r, q, sigma, T)
+		call := Precise.CallPrice(S, K, r, q, sigma, T)
+		if call < 0 || math.Abs(call-refCall) > relTolerance*refCall {
+			t.Errorf("K=%g: precise call=%g, reference=%g\n", K, call, refCall)
+		}
+	}
+}
+
+// TestPreciseImpliedVol checks that the precise formula recovers the volatility
+// to the accuracy of the solver, or to what double precision prices allow when vega is small
+func TestPreciseImpliedVol(t *testing.T) {
+
+	for _, table := range testValuesBSM {
+		S, K, r, q, sigma, T := table.S, table.K, table.r, table.q, table.sigma, table.T
+
+		vol, err := Precise.ImpliedVol(S, K, r, q, T, Precise.CallPrice(S, K, r, q, sigma, T), true)
+		if err != nil {
+			t.Errorf(err.Error())
+		}
+		testTolerance := math.Max(1.0e-10, 1.0e-14*S/BSMVega(S, K, r, q, sigma, T))
+		error := math.Abs(vol - sigma)
+		if math.IsNaN(error) || error > testTolerance {
+			t.Errorf("S=%g, K=%g, r=%g, q=%g, sigma=%g, T=%g, error=%g is greater than tolerance.\n",
+				S, K, r, q, sigma, T, error)
+		}
+	}
+}
+
+// TestZeroFormulaIsFast checks the zero value Formula uses the fast CDF
+func TestZeroFormulaIsFast(t *testing.T) {
+	const S, K, r, q, sigma, T = 100.0, 95.0, 0.03, 0.01, 0.2, 0.5
+	var f Formula
+	if f.CallPrice(S, K, r, q, sigma, T) != Fast.CallPrice(S, K, r, q, sigma, T) ||
+		f.PutDelta(S, K, r, q, sigma, T) != Fast.PutDelta(S, K, r, q, sigma, T) {
+		t.Errorf("zero value Formula differs from Fast\n")
+	}
+}
@@ -0,0 +1,246 @@
+package bsformula
+
+import (
+	"math"
+
+	"code.vegaprotocol.io/quant/misc"
+)
+
+// BSGamma calculates the BS Gamma (second partial derivative w.r.t. S)
+// Note that it's identical for both puts and calls
+func BSGamma(S, K, r, sigma, T float64) float64 {
+	return BSMGamma(S, K, r, 0, sigma, T)
+}
+
+// BSMGamma calculates the BSM Gamma (second partial derivative w.r.t. S) with yield q
+// Note that it's identical for both puts and calls
+// When the volatility is zero or the option is at expiry this and the other greeks of second and higher order
+// are taken as zero, their mass at the strike isn't represented
+func BSMGamma(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	if degenerate(sigma, T) {
+		return 0
+	}
+	d1 := d1Fn(S, K, r-q, sigma, T)
+	return math.Exp(-q*T) * misc.GaussDensity(d1) / (S * sigma * math.Sqrt(T))
+}
+
+// BSCallTheta calculates the BS Theta of a call, i.e. the rate of change of the price
+// with the passage of time (minus the partial derivative w.r.t. T)
+func BSCallTheta(S, K, r, sigma, T float64) float64 {
+	return BSMCallTheta(S, K, r, 0, sigma, T)
+}
+
+// BSMCallTheta calculates the BSM Theta of a call with yield q, i.e. the rate of change of the price
+// with the passage of time (minus the partial derivative w.r.t. T)
+func BSMCallTheta(S, K, r, q, sigma, T float64) float64 {
+	return Fast.CallTheta(S, K, r, q, sigma, T)
+}
+
+// CallTheta calculates the BSM Theta of a call with yield q, i.e. the rate of change of the price
+// with the passage of time (minus the partial derivative w.r.t. T)
+func (f Formula) CallTheta(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	d1 := d1Fn(S, K, r-q, sigma, T)
+	d2 := d1 - sigma*math.Sqrt(T)
+	discS := S * math.Exp(-q*T)
+	return -discS*thetaDensityTerm(d1, sigma, T) - r*K*math.Exp(-r*T)*f.cdf(d2) + q*discS*f.cdf(d1)
+}
+
+// thetaDensityTerm returns the part of the BSM Theta due to the volatility, it is taken as zero when sigma sqrt(T) is zero
+func thetaDensityTerm(d1, sigma, T float64) float64 {
+	if degenerate(sigma, T) {
+		return 0
+	}
+	return misc.GaussDensity(d1) * sigma / (2 * math.Sqrt(T))
+}
+
+// BSPutTheta calculates the BS Theta of a put, i.e. the rate of change of the price
+// with the passage of time (minus the partial derivative w.r.t. T)
+func BSPutTheta(S, K, r, sigma, T float64) float64 {
+	return BSMPutTheta(S, K, r, 0, sigma, T)
+}
+
+// BSMPutTheta calculates the BSM Theta of a put with yield q, i.e. the rate of change of the price
+// with the passage of time (minus the partial derivative w.r.t. T)
+func BSMPutTheta(S, K, r, q, sigma, T float64) float64 {
+	return Fast.PutTheta(S, K, r, q, sigma, T)
+}
+
+// PutTheta calculates the BSM Theta of a put with yield q, i.e. the rate of change of the price
+// with the passage of time (minus the partial derivative w.r.t. T)
+func (f Formula) PutTheta(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	d1 := d1Fn(S, K, r-q, sigma, T)
+	d2 := d1 - sigma*math.Sqrt(T)
+	discS := S * math.Exp(-q*T)
+	return -discS*thetaDensityTerm(d1, sigma, T) + r*K*math.Exp(-r*T)*f.cdf(-d2) - q*discS*f.cdf(-d1)
+}
+
+// BSCallRho calculates the BS Rho of a call (partial derivative w.r.t. r)
+func BSCallRho(S, K, r, sigma, T float64) float64 {
+	return BSMCallRho(S, K, r, 0, sigma, T)
+}
+
+// BSMCallRho calculates the BSM Rho of a call (partial derivative w.r.t. r, keeping q fixed)
+func BSMCallRho(S, K, r, q, sigma, T float64) float64 {
+	return Fast.CallRho(S, K, r, q, sigma, T)
+}
+
+// CallRho calculates the BSM Rho of a call (partial derivative w.r.t. r, keeping q fixed)
+func (f Formula) CallRho(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	d2 := d1Fn(S, K, r-q, sigma, T) - sigma*math.Sqrt(T)
+	return K * T * math.Exp(-r*T) * f.cdf(d2)
+}
+
+// BSPutRho calculates the BS Rho of a put (partial derivative w.r.t. r)
+func BSPutRho(S, K, r, sigma, T float64) float64 {
+	return BSMPutRho(S, K, r, 0, sigma, T)
+}
+
+// BSMPutRho calculates the BSM Rho of a put (partial derivative w.r.t. r, keeping q fixed)
+func BSMPutRho(S, K, r, q, sigma, T float64) float64 {
+	return Fast.PutRho(S, K, r, q, sigma, T)
+}
+
+// PutRho calculates the BSM Rho of a put (partial derivative w.r.t. r, keeping q fixed)
+func (f Formula) PutRho(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	d2 := d1Fn(S, K, r-q, sigma, T) - sigma*math.Sqrt(T)
+	return -K * T * math.Exp(-r*T) * f.cdf(-d2)
+}
+
+// BSMCallPhi calculates the BSM Phi of a call (partial derivative w.r.t. the yield q),
+// for FX options this is the sensitivity to the foreign interest rate
+func BSMCallPhi(S, K, r, q, sigma, T float64) float64 {
+	return Fast.CallPhi(S, K, r, q, sigma, T)
+}
+
+// CallPhi calculates the BSM Phi of a call (partial derivative w.r.t. the yield q),
+// for FX options this is the sensitivity to the foreign interest rate
+func (f Formula) CallPhi(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	d1 := d1Fn(S, K, r-q, sigma, T)
+	return -T * S * math.Exp(-q*T) * f.cdf(d1)
+}
+
+// BSMPutPhi calculates the BSM Phi of a put (partial derivative w.r.t. the yield q),
+// for FX options this is the sensitivity to the foreign interest rate
+func BSMPutPhi(S, K, r, q, sigma, T float64) float64 {
+	return Fast.PutPhi(S, K, r, q, sigma, T)
+}
+
+// PutPhi calculates the BSM Phi of a put (partial derivative w.r.t. the yield q),
+// for FX options this is the sensitivity to the foreign interest rate
+func (f Formula) PutPhi(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	d1 := d1Fn(S, K, r-q, sigma, T)
+	return T * S * math.Exp(-q*T) * f.cdf(-d1)
+}
+
+// BSVanna calculates the BS Vanna (second partial derivative w.r.t. S and sigma)
+// Note that it's identical for both puts and calls
+func BSVanna(S, K, r, sigma, T float64) float64 {
+	return BSMVanna(S, K, r, 0, sigma, T)
+}
+
+// BSMVanna calculates the BSM Vanna (second partial derivative w.r.t. S and sigma) with yield q
+// Note that it's identical for both puts and calls
+func BSMVanna(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	if degenerate(sigma, T) {
+		return 0
+	}
+	d1 := d1Fn(S, K, r-q, sigma, T)
+	d2 := d1 - sigma*math.Sqrt(T)
+	return -math.Exp(-q*T) * misc.GaussDensity(d1) * d2 / sigma
+}
+
+// BSVolga calculates the BS Volga, also known as Vomma (second partial derivative w.r.t. sigma)
+// Note that it's identical for both puts and calls
+func BSVolga(S, K, r, sigma, T float64) float64 {
+	return BSMVolga(S, K, r, 0, sigma, T)
+}
+
+// BSMVolga calculates the BSM Volga, also known as Vomma (second partial derivative w.r.t. sigma) with yield q
+// Note that it's identical for both puts and calls
+func BSMVolga(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	if degenerate(sigma, T) {
+		return 0
+	}
+	d1 := d1Fn(S, K, r-q, sigma, T)
+	d2 := d1 - sigma*math.Sqrt(T)
+	return BSMVega(S, K, r, q, sigma, T) * d1 * d2 / sigma
+}
+
+// charmCommonTerm returns the part of the BSM Charm shared by calls and puts
+func charmCommonTerm(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	if degenerate(sigma, T) {
+		return 0
+	}
+	d1 := d1Fn(S, K, r-q, sigma, T)
+	sqrtT := math.Sqrt(T)
+	d2 := d1 - sigma*sqrtT
+	return -math.Exp(-q*T) * misc.GaussDensity(d1) * (2*(r-q)*T - d2*sigma*sqrtT) / (2 * T * sigma * sqrtT)
+}
+
+// BSCallCharm calculates the BS Charm of a call, i.e. the rate of change of the delta
+// with the passage of time (minus the second partial derivative w.r.t. S and T)
+func BSCallCharm(S, K, r, sigma, T float64) float64 {
+	return BSMCallCharm(S, K, r, 0, sigma, T)
+}
+
+// BSMCallCharm calculates the BSM Charm of a call with yield q, i.e. the rate of change of the delta
+// with the passage of time (minus the second partial derivative w.r.t. S and T)
+func BSMCallCharm(S, K, r, q, sigma, T float64) float64 {
+	return Fast.CallCharm(S, K, r, q, sigma, T)
+}
+
+// CallCharm calculates the BSM Charm of a call with yield q, i.e. the rate of change of the delta
+// with the passage of time (minus the second partial derivative w.r.t. S and T)
+func (f Formula) CallCharm(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	d1 := d1Fn(S, K, r-q, sigma, T)
+	return q*math.Exp(-q*T)*f.cdf(d1) + charmCommonTerm(S, K, r, q, sigma, T)
+}
+
+// BSPutCharm calculates the BS Charm of a put, i.e. the rate of change of the delta
+// with the passage of time (minus the second partial derivative w.r.t. S and T)
+// Without a carry term the put delta differs from the call delta by a constant so the two coincide.
+func BSPutCharm(S, K, r, sigma, T float64) float64 {
+	return BSMPutCharm(S, K, r, 0, sigma, T)
+}
+
+// BSMPutCharm calculates the BSM Charm of a put with yield q, i.e. the rate of change of the delta
+// with the passage of time (minus the second partial derivative w.r.t. S and T)
+func BSMPutCharm(S, K, r, q, sigma, T float64) float64 {
+	return Fast.PutCharm(S, K, r, q, sigma, T)
+}
+
+// PutCharm calculates the BSM Charm of a put with yield q, i.e. the rate of change of the delta
+// with the passage of time (minus the second partial derivative w.r.t. S and T)
+func (f Formula) PutCharm(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	d1 := d1Fn(S, K, r-q, sigma, T)
+	return -q*math.Exp(-q*T)*f.cdf(-d1) + charmCommonTerm(S, K, r, q, sigma, T)
+}
+
+// BSSpeed calculates the BS Speed (third partial derivative w.r.t. S)
+// Note that it's identical for both puts and calls
+func BSSpeed(S, K, r, sigma, T float64) float64 {
+	return BSMSpeed(S, K, r, 0, sigma, T)
+}
+
+// BSMSpeed calculates the BSM Speed (third partial derivative w.r.t. S) with yield q
+// Note that it's identical for both puts and calls
+func BSMSpeed(S, K, r, q, sigma, T float64) float64 {
+	T = expiry(T)
+	if degenerate(sigma, T) {
+		return 0
+	}
+	d1 := d1Fn(S, K, r-q, sigma, T)
+	return -BSMGamma(S, K, r, q, sigma, T) / S * (d1/(sigma*math.Sqrt(T)) + 1)
+}
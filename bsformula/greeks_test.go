@@ -0,0 +1,82 @@
+package bsformula
+
+import (
+	"math"
+	"testing"
+)
+
+// checkGreekVsFiniteDifference compares a closed-form greek against a central
+// finite difference of fn, where fn is bumped by the caller-supplied shift
+func checkGreekVsFiniteDifference(t *testing.T, name string, greek float64, fn func(shift float64) float64, bump float64, S, K, r, sigma, T float64) {
+	const testTolerance float64 = 1.0e-3
+
+	fd := (fn(bump) - fn(-bump)) / (2 * bump)
+	error := math.Abs(greek-fd) / S
+
+	if math.IsNaN(error) || math.IsInf(error, 0) {
+		t.Errorf("%s: S=%g, K=%g, r=%g, sigma=%g, T=%g, error=%g is NaN or Inf!\n",
+			name, S, K, r, sigma, T, error)
+	}
+
+	if error > testTolerance {
+		t.Errorf("%s: S=%g, K=%g, r=%g, sigma=%g, T=%g, closed form=%g, finite difference=%g, error=%g is greater than tolerance.\n",
+			name, S, K, r, sigma, T, greek, fd, error)
+	}
+}
+
+// TestBSFirstOrderGreeksVsFiniteDifference we use finite differences of the BS prices
+// to check theta and rho
+func TestBSFirstOrderGreeksVsFiniteDifference(t *testing.T) {
+	const bump float64 = 1.0e-7
+
+	for _, table := range testValues {
+		S := table.S
+		K := table.K
+		r := table.r
+		sigma := table.sigma
+		T := table.T
+
+		// theta is minus the derivative w.r.t. time to maturity
+		checkGreekVsFiniteDifference(t, "call theta", BSCallTheta(S, K, r, sigma, T),
+			func(h float64) float64 { return -BSCallPrice(S, K, r, sigma, T+h) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "put theta", BSPutTheta(S, K, r, sigma, T),
+			func(h float64) float64 { return -BSPutPrice(S, K, r, sigma, T+h) }, bump, S, K, r, sigma, T)
+
+		checkGreekVsFiniteDifference(t, "call rho", BSCallRho(S, K, r, sigma, T),
+			func(h float64) float64 { return BSCallPrice(S, K, r+h, sigma, T) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "put rho", BSPutRho(S, K, r, sigma, T),
+			func(h float64) float64 { return BSPutPrice(S, K, r+h, sigma, T) }, bump, S, K, r, sigma, T)
+	}
+}
+
+// TestBSSecondOrderGreeksVsFiniteDifference we use finite differences of the
+// first order greeks to check the second (and third) order ones
+func TestBSSecondOrderGreeksVsFiniteDifference(t *testing.T) {
+	const bump float64 = 1.0e-7
+
+	for _, table := range testValues {
+		S := table.S
+		K := table.K
+		r := table.r
+		sigma := table.sigma
+		T := table.T
+
+		checkGreekVsFiniteDifference(t, "call gamma", BSGamma(S, K, r, sigma, T),
+			func(h float64) float64 { return BSCallDelta(S+h, K, r, sigma, T) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "put gamma", BSGamma(S, K, r, sigma, T),
+			func(h float64) float64 { return BSPutDelta(S+h, K, r, sigma, T) }, bump, S, K, r, sigma, T)
+
+		checkGreekVsFiniteDifference(t, "vanna", BSVanna(S, K, r, sigma, T),
+			func(h float64) float64 { return BSVega(S+h, K, r, sigma, T) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "volga", BSVolga(S, K, r, sigma, T),
+			func(h float64) float64 { return BSVega(S, K, r, sigma+h, T) }, bump, S, K, r, sigma, T)
+
+		checkGreekVsFiniteDifference(t, "call charm", BSCallCharm(S, K, r, sigma, T),
+			func(h float64) float64 { return -BSCallDelta(S, K, r, sigma, T+h) }, bump, S, K, r, sigma, T)
+		checkGreekVsFiniteDifference(t, "put charm", BSPutCharm(S, K, r, sigma, T),
+			func(h float64) float64 { return -BSPutDelta(S, K, r, sigma, T+h) }, bump, S, K, r, sigma, T)
+
+		checkGreekVsFiniteDifference(t, "speed", BSSpeed(S, K, r, sigma, T),
+			func(h float64) float64 { return BSGamma(S+h, K, r, sigma, T) }, bump, S, K, r, sigma, T)
+	}
+}
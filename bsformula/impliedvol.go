@@ -0,0 +1,86 @@
+package bsformula
+
+import (
+	"errors"
+	"math"
+
+	"code.vegaprotocol.io/quant/misc"
+)
+
+var (
+	// ErrPriceBelowLowerBound is returned when the option price doesn't exceed its intrinsic (discounted forward) value
+	// so that no positive volatility reproduces it
+	ErrPriceBelowLowerBound = errors.New("option price is at or below its no-arbitrage lower bound")
+	// ErrPriceAboveUpperBound is returned when the call price isn't below the discounted underlying
+	// (or the put price below the discounted strike) so that no finite volatility reproduces it
+	ErrPriceAboveUpperBound = errors.New("option price is at or above its no-arbitrage upper bound")
+	// ErrImpliedVolNotFound is returned when neither Newton's method nor the bracketing fallback converged
+	ErrImpliedVolNotFound = errors.New("implied volatility solver did not converge")
+)
+
+const (
+	impliedVolNewtonMaxIt = 50
+	impliedVolBrentMaxIt  = 200
+	impliedVolPriceTol    = 1e-13 // relative to the option price
+	impliedVolSigmaTol    = 1e-15
+	impliedVolMinSigma    = 1e-8
+	impliedVolMaxSigma    = 1e3
+)
+
+// solveImpliedVol finds sigma such that optionPrice(sigma) equals price, where optionPrice is the call or put price as indicated by isCall
+// and discS and discK are the underlying and strike discounted from expiry, i.e. Se^(-qT) and Ke^(-rT) (DF and DK for Black-76).
+// The price is first checked against the no-arbitrage bounds, Newton's method is started from the Corrado-Miller
+// approximation and Brent's method on a bracket is used when Newton fails.
+func solveImpliedVol(optionPrice, vega func(sigma float64) float64, discS, discK, T, price float64, isCall bool) (float64, error) {
+	lowerBound := math.Max(discS-discK, 0)
+	upperBound := discS
+	call := price
+	if !isCall {
+		lowerBound = math.Max(discK-discS, 0)
+		upperBound = discK
+		call = price + discS - discK
+	}
+	if price <= lowerBound {
+		return math.NaN(), ErrPriceBelowLowerBound
+	}
+	if price >= upperBound {
+		return math.NaN(), ErrPriceAboveUpperBound
+	}
+
+	f := func(sigma float64) float64 {
+		return optionPrice(sigma) - price
+	}
+
+	guess := impliedVolGuess(discS, discK, T, call)
+	sigma, err := misc.FindRoot(f, vega, guess, impliedVolNewtonMaxIt, impliedVolPriceTol*price)
+	if err == nil && sigma > 0 && !math.IsNaN(sigma) {
+		return sigma, nil
+	}
+
+	// Newton stalled, find a bracket and fall back to Brent's method
+	lo := impliedVolMinSigma
+	for f(lo) > 0 && lo > 1e-300 {
+		lo /= 100
+	}
+	hi := math.Max(2*guess, 1)
+	for f(hi) < 0 && hi < impliedVolMaxSigma {
+		hi *= 2
+	}
+	sigma, err = misc.FindRootBrent(f, lo, hi, impliedVolBrentMaxIt, impliedVolSigmaTol)
+	if err != nil {
+		return math.NaN(), ErrImpliedVolNotFound
+	}
+	return sigma, nil
+}
+
+// impliedVolGuess returns the Corrado-Miller approximation to the implied volatility of a call,
+// falling back on the Brenner-Subrahmanyam at-the-money approximation when the former isn't defined
+func impliedVolGuess(discS, discK, T, call float64) float64 {
+	a := call - 0.5*(discS-discK)
+	disc := a*a - (discS-discK)*(discS-discK)/math.Pi
+	guess := math.Sqrt(2*math.Pi/T) / (discS + discK) * (a + math.Sqrt(math.Max(disc, 0)))
+	if guess > 0 && !math.IsNaN(guess) && !math.IsInf(guess, 0) {
+		return guess
+	}
+	return math.Sqrt(2*math.Pi/T) * call / discS
+}
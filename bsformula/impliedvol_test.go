@@ -0,0 +1,125 @@
+package bsformula
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestImpliedVolAcrossMoneyness checks the implied volatility over a wide range of strikes,
+// maturities and volatilities, including the deep in / out of the money and short maturity
+// cases where Newton's method started at a fixed guess fails
+func TestImpliedVolAcrossMoneyness(t *testing.T) {
+	const S, r, q = 1.0, 0.02, 0.01
+
+	for _, K := range []float64{0.2, 0.5, 0.8, 0.95, 1.0, 1.05, 1.25, 2.0, 5.0} {
+		for _, T := range []float64{1.0 / 365.25 / 24, 1.0 / 365.25, 0.1, 1.0, 10.0} {
+			for _, sigma := range []float64{0.01, 0.1, 0.5, 1.5, 3.0} {
+				for _, isCall := range []bool{true, false} {
+					price := Precise.CallPrice(S, K, r, q, sigma, T)
+					lowerBound := math.Max(S*math.Exp(-q*T)-K*math.Exp(-r*T), 0)
+					if !isCall {
+						price = Precise.PutPrice(S, K, r, q, sigma, T)
+						lowerBound = math.Max(K*math.Exp(-r*T)-S*math.Exp(-q*T), 0)
+					}
+					// skip options whose time value is lost in double precision
+					if price-lowerBound < 1e-12*S {
+						continue
+					}
+
+					vol, err := Precise.ImpliedVol(S, K, r, q, T, price, isCall)
+					if err != nil {
+						t.Errorf("K=%g, T=%g, sigma=%g, isCall=%v: %s\n", K, T, sigma, isCall, err.Error())
+						continue
+					}
+
+					testTolerance := math.Max(1.0e-9*sigma, 1.0e-14*(S+K)/BSMVega(S, K, r, q, sigma, T))
+					error := math.Abs(vol - sigma)
+					if math.IsNaN(error) || error > testTolerance {
+						t.Errorf("K=%g, T=%g, sigma=%g, isCall=%v: implied vol=%g, error=%g is greater than tolerance.\n",
+							K, T, sigma, isCall, vol, error)
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestImpliedVolFastFormulaHardCases checks the package level function on cases
+// that used to fail with "derivative too small"
+func TestImpliedVolFastFormulaHardCases(t *testing.T) {
+	const testTolerance float64 = 1.0e-3
+
+	tables := []struct {
+		S     float64
+		K     float64
+		r     float64
+		sigma float64
+		T     float64
+	}{
+		{1.0, 0.5, 0.0, 1.5, 0.02},         // deep in the money call
+		{1.0, 0.8, 0.0, 1.5, 1.0 / 365.25}, // in the money call, short maturity
+		{1.0, 1.2, 0.0, 1.5, 1.0 / 365.25}, // out of the money call, short maturity
+		{1.0, 1.5, 0.0, 1.5, 0.02},         // deep out of the money call
+	}
+
+	for _, table := range tables {
+		S, K, r, sigma, T := table.S, table.K, table.r, table.sigma, table.T
+
+		for _, isCall := range []bool{true, false} {
+			price := BSCallPrice(S, K, r, sigma, T)
+			if !isCall {
+				price = BSPutPrice(S, K, r, sigma, T)
+			}
+			vol, err := ImpliedVol(S, K, r, T, price, isCall)
+			if err != nil {
+				t.Errorf("S=%g, K=%g, sigma=%g, T=%g, isCall=%v: %s\n", S, K, sigma, T, isCall, err.Error())
+				continue
+			}
+			error := math.Abs(vol-sigma) / sigma
+			if math.IsNaN(error) || error > testTolerance {
+				t.Errorf("S=%g, K=%g, sigma=%g, T=%g, isCall=%v: implied vol=%g, error=%g is greater than tolerance.\n",
+					S, K, sigma, T, isCall, vol, error)
+			}
+		}
+	}
+}
+
+// TestImpliedVolArbitrageBounds checks that prices outside the no-arbitrage bounds are rejected
+func TestImpliedVolArbitrageBounds(t *testing.T) {
+	const S, K, r, q, T = 100.0, 90.0, 0.05, 0.01, 1.0
+	discS := S * math.Exp(-q*T)
+	discK := K * math.Exp(-r*T)
+
+	tables := []struct {
+		price  float64
+		isCall bool
+		err    error
+	}{
+		{discS - discK, true, ErrPriceBelowLowerBound},
+		{discS - discK - 1, true, ErrPriceBelowLowerBound},
+		{0, false, ErrPriceBelowLowerBound},
+		{-1, false, ErrPriceBelowLowerBound},
+		{discS, true, ErrPriceAboveUpperBound},
+		{discK + 1, false, ErrPriceAboveUpperBound},
+	}
+
+	for _, table := range tables {
+		vol, err := BSMImpliedVol(S, K, r, q, T, table.price, table.isCall)
+		if !errors.Is(err, table.err) {
+			t.Errorf("price=%g, isCall=%v: expected error %v, got %v (vol=%g)\n", table.price, table.isCall, table.err, err, vol)
+		}
+		if !math.IsNaN(vol) {
+			t.Errorf("price=%g, isCall=%v: expected NaN vol, got %g\n", table.price, table.isCall, vol)
+		}
+	}
+
+	// Black-76 bounds are in terms of the discounted futures price and strike
+	const F, D = 100.0, 0.95
+	if _, err := Black76ImpliedVol(F, K, D, T, D*(F-K), true); !errors.Is(err, ErrPriceBelowLowerBound) {
+		t.Errorf("Black-76: expected error %v, got %v\n", ErrPriceBelowLowerBound, err)
+	}
+	if _, err := Black76ImpliedVol(F, K, D, T, D*K, false); !errors.Is(err, ErrPriceAboveUpperBound) {
+		t.Errorf("Black-76: expected error %v, got %v\n", ErrPriceAboveUpperBound, err)
+	}
+}
@@ -0,0 +1,254 @@
+package bsformula
+
+import (
+	"code.vegaprotocol.io/quant/misc"
+)
+
+// ValidateInputs checks the inputs of the Black-Scholes-Merton formulas: the underlying price S and strike K
+// must be positive and finite, the volatility sigma and time to maturity T non-negative and finite (zero gives
+// the limiting prices and greeks) and the rates r and q finite.
+// It returns the first violation as one of the errors from the misc package (e.g. misc.ErrInvalidSigma).
+func ValidateInputs(S, K, r, q, sigma, T float64) error {
+	return misc.FirstError(
+		misc.ValidatePositive(S, misc.ErrInvalidPrice),
+		misc.ValidatePositive(K, misc.ErrInvalidStrike),
+		misc.ValidateFinite(r, misc.ErrInvalidRate),
+		misc.ValidateFinite(q, misc.ErrInvalidRate),
+		misc.ValidateNonNegative(sigma, misc.ErrInvalidSigma),
+		misc.ValidateNonNegative(T, misc.ErrInvalidMaturity),
+	)
+}
+
+// checked evaluates fn once the inputs pass ValidateInputs
+func checked(fn func(S, K, r, q, sigma, T float64) float64, S, K, r, q, sigma, T float64) (float64, error) {
+	if err := ValidateInputs(S, K, r, q, sigma, T); err != nil {
+		return 0, err
+	}
+	return fn(S, K, r, q, sigma, T), nil
+}
+
+// CallPriceChecked is CallPrice returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func (f Formula) CallPriceChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(f.CallPrice, S, K, r, q, sigma, T)
+}
+
+// PutPriceChecked is PutPrice returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func (f Formula) PutPriceChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(f.PutPrice, S, K, r, q, sigma, T)
+}
+
+// CallDeltaChecked is CallDelta returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func (f Formula) CallDeltaChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(f.CallDelta, S, K, r, q, sigma, T)
+}
+
+// PutDeltaChecked is PutDelta returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func (f Formula) PutDeltaChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(f.PutDelta, S, K, r, q, sigma, T)
+}
+
+// BSCallPriceChecked is BSCallPrice returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSCallPriceChecked(S, K, r, sigma, T float64) (float64, error) {
+	return Fast.CallPriceChecked(S, K, r, 0, sigma, T)
+}
+
+// BSMCallPriceChecked is BSMCallPrice returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMCallPriceChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return Fast.CallPriceChecked(S, K, r, q, sigma, T)
+}
+
+// BSPutPriceChecked is BSPutPrice returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSPutPriceChecked(S, K, r, sigma, T float64) (float64, error) {
+	return Fast.PutPriceChecked(S, K, r, 0, sigma, T)
+}
+
+// BSMPutPriceChecked is BSMPutPrice returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMPutPriceChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return Fast.PutPriceChecked(S, K, r, q, sigma, T)
+}
+
+// BSMCallDeltaChecked is BSMCallDelta returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMCallDeltaChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return Fast.CallDeltaChecked(S, K, r, q, sigma, T)
+}
+
+// BSMPutDeltaChecked is BSMPutDelta returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMPutDeltaChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return Fast.PutDeltaChecked(S, K, r, q, sigma, T)
+}
+
+// BSMVegaChecked is BSMVega returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMVegaChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(BSMVega, S, K, r, q, sigma, T)
+}
+
+// BSMGammaChecked is BSMGamma returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMGammaChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(BSMGamma, S, K, r, q, sigma, T)
+}
+
+// CallThetaChecked is CallTheta returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func (f Formula) CallThetaChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(f.CallTheta, S, K, r, q, sigma, T)
+}
+
+// PutThetaChecked is PutTheta returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func (f Formula) PutThetaChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(f.PutTheta, S, K, r, q, sigma, T)
+}
+
+// BSMCallThetaChecked is BSMCallTheta returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMCallThetaChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return Fast.CallThetaChecked(S, K, r, q, sigma, T)
+}
+
+// BSMPutThetaChecked is BSMPutTheta returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMPutThetaChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return Fast.PutThetaChecked(S, K, r, q, sigma, T)
+}
+
+// CallRhoChecked is CallRho returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func (f Formula) CallRhoChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(f.CallRho, S, K, r, q, sigma, T)
+}
+
+// PutRhoChecked is PutRho returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func (f Formula) PutRhoChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(f.PutRho, S, K, r, q, sigma, T)
+}
+
+// BSMCallRhoChecked is BSMCallRho returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMCallRhoChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return Fast.CallRhoChecked(S, K, r, q, sigma, T)
+}
+
+// BSMPutRhoChecked is BSMPutRho returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMPutRhoChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return Fast.PutRhoChecked(S, K, r, q, sigma, T)
+}
+
+// CallPhiChecked is CallPhi returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func (f Formula) CallPhiChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(f.CallPhi, S, K, r, q, sigma, T)
+}
+
+// PutPhiChecked is PutPhi returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func (f Formula) PutPhiChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(f.PutPhi, S, K, r, q, sigma, T)
+}
+
+// BSMCallPhiChecked is BSMCallPhi returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMCallPhiChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return Fast.CallPhiChecked(S, K, r, q, sigma, T)
+}
+
+// BSMPutPhiChecked is BSMPutPhi returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMPutPhiChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return Fast.PutPhiChecked(S, K, r, q, sigma, T)
+}
+
+// BSMVannaChecked is BSMVanna returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMVannaChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(BSMVanna, S, K, r, q, sigma, T)
+}
+
+// BSMVolgaChecked is BSMVolga returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMVolgaChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(BSMVolga, S, K, r, q, sigma, T)
+}
+
+// CallCharmChecked is CallCharm returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func (f Formula) CallCharmChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(f.CallCharm, S, K, r, q, sigma, T)
+}
+
+// PutCharmChecked is PutCharm returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func (f Formula) PutCharmChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(f.PutCharm, S, K, r, q, sigma, T)
+}
+
+// BSMCallCharmChecked is BSMCallCharm returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMCallCharmChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return Fast.CallCharmChecked(S, K, r, q, sigma, T)
+}
+
+// BSMPutCharmChecked is BSMPutCharm returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMPutCharmChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return Fast.PutCharmChecked(S, K, r, q, sigma, T)
+}
+
+// BSMSpeedChecked is BSMSpeed returning an error instead of NaN or Inf for invalid inputs, see ValidateInputs
+func BSMSpeedChecked(S, K, r, q, sigma, T float64) (float64, error) {
+	return checked(BSMSpeed, S, K, r, q, sigma, T)
+}
+
+// ValidateBlack76Inputs checks the inputs of the Black-76 formulas: the futures price F, strike K and discount
+// factor D must be positive and finite, the volatility sigma and time to maturity T non-negative and finite.
+// It returns the first violation as one of the errors from the misc package (e.g. misc.ErrInvalidSigma).
+func ValidateBlack76Inputs(F, K, D, sigma, T float64) error {
+	return misc.FirstError(
+		misc.ValidatePositive(F, misc.ErrInvalidPrice),
+		misc.ValidatePositive(K, misc.ErrInvalidStrike),
+		misc.ValidatePositive(D, misc.ErrInvalidDiscountFactor),
+		misc.ValidateNonNegative(sigma, misc.ErrInvalidSigma),
+		misc.ValidateNonNegative(T, misc.ErrInvalidMaturity),
+	)
+}
+
+// checkedBlack76 evaluates fn once the inputs pass ValidateBlack76Inputs
+func checkedBlack76(fn func(F, K, D, sigma, T float64) float64, F, K, D, sigma, T float64) (float64, error) {
+	if err := ValidateBlack76Inputs(F, K, D, sigma, T); err != nil {
+		return 0, err
+	}
+	return fn(F, K, D, sigma, T), nil
+}
+
+// Black76CallPriceChecked is Black76CallPrice returning an error instead of NaN or Inf for invalid inputs, see ValidateBlack76Inputs
+func (f Formula) Black76CallPriceChecked(F, K, D, sigma, T float64) (float64, error) {
+	return checkedBlack76(f.Black76CallPrice, F, K, D, sigma, T)
+}
+
+// Black76PutPriceChecked is Black76PutPrice returning an error instead of NaN or Inf for invalid inputs, see ValidateBlack76Inputs
+func (f Formula) Black76PutPriceChecked(F, K, D, sigma, T float64) (float64, error) {
+	return checkedBlack76(f.Black76PutPrice, F, K, D, sigma, T)
+}
+
+// Black76CallDeltaChecked is Black76CallDelta returning an error instead of NaN or Inf for invalid inputs, see ValidateBlack76Inputs
+func (f Formula) Black76CallDeltaChecked(F, K, D, sigma, T float64) (float64, error) {
+	return checkedBlack76(f.Black76CallDelta, F, K, D, sigma, T)
+}
+
+// Black76PutDeltaChecked is Black76PutDelta returning an error instead of NaN or Inf for invalid inputs, see ValidateBlack76Inputs
+func (f Formula) Black76PutDeltaChecked(F, K, D, sigma, T float64) (float64, error) {
+	return checkedBlack76(f.Black76PutDelta, F, K, D, sigma, T)
+}
+
+// Black76CallPriceChecked is Black76CallPrice returning an error instead of NaN or Inf for invalid inputs, see ValidateBlack76Inputs
+func Black76CallPriceChecked(F, K, D, sigma, T float64) (float64, error) {
+	return Fast.Black76CallPriceChecked(F, K, D, sigma, T)
+}
+
+// Black76PutPriceChecked is Black76PutPrice returning an error instead of NaN or Inf for invalid inputs, see ValidateBlack76Inputs
+func Black76PutPriceChecked(F, K, D, sigma, T float64) (float64, error) {
+	return Fast.Black76PutPriceChecked(F, K, D, sigma, T)
+}
+
+// Black76CallDeltaChecked is Black76CallDelta returning an error instead of NaN or Inf for invalid inputs, see ValidateBlack76Inputs
+func Black76CallDeltaChecked(F, K, D, sigma, T float64) (float64, error) {
+	return Fast.Black76CallDeltaChecked(F, K, D, sigma, T)
+}
+
+// Black76PutDeltaChecked is Black76PutDelta returning an error instead of NaN or Inf for invalid inputs, see ValidateBlack76Inputs
+func Black76PutDeltaChecked(F, K, D, sigma, T float64) (float64, error) {
+	return Fast.Black76PutDeltaChecked(F, K, D, sigma, T)
+}
+
+// Black76GammaChecked is Black76Gamma returning an error instead of NaN or Inf for invalid inputs, see ValidateBlack76Inputs
+func Black76GammaChecked(F, K, D, sigma, T float64) (float64, error) {
+	return checkedBlack76(Black76Gamma, F, K, D, sigma, T)
+}
+
+// Black76VegaChecked is Black76Vega returning an error instead of NaN or Inf for invalid inputs, see ValidateBlack76Inputs
+func Black76VegaChecked(F, K, D, sigma, T float64) (float64, error) {
+	return checkedBlack76(Black76Vega, F, K, D, sigma, T)
+}
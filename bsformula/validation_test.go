@@ -0,0 +1,105 @@
+package bsformula
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/misc"
+)
+
+func TestValidateInputs(t *testing.T) {
+	const S, K, r, q, sigma, T = 100.0, 110.0, 0.02, 0.01, 0.3, 0.5
+
+	tables := []struct {
+		S, K, r, q, sigma, T float64
+		err                  error
+	}{
+		{S, K, r, q, sigma, T, nil},
+		{S, K, -0.01, 0.05, sigma, T, nil},
+		{0, K, r, q, sigma, T, misc.ErrInvalidPrice},
+		{math.Inf(1), K, r, q, sigma, T, misc.ErrInvalidPrice},
+		{S, -K, r, q, sigma, T, misc.ErrInvalidStrike},
+		{S, K, math.NaN(), q, sigma, T, misc.ErrInvalidRate},
+		{S, K, r, math.Inf(-1), sigma, T, misc.ErrInvalidRate},
+		{S, K, r, q, 0, T, nil},
+		{S, K, r, q, sigma, 0, nil},
+		{S, K, r, q, -sigma, T, misc.ErrInvalidSigma},
+		{S, K, r, q, math.NaN(), T, misc.ErrInvalidSigma},
+		{S, K, r, q, sigma, -T, misc.ErrInvalidMaturity},
+	}
+
+	for i, table := range tables {
+		checks := []func(S, K, r, q, sigma, T float64) (float64, error){
+			Fast.CallPriceChecked, Precise.PutPriceChecked, Fast.CallDeltaChecked, Fast.PutDeltaChecked,
+			BSMCallPriceChecked, BSMPutPriceChecked, BSMCallDeltaChecked, BSMPutDeltaChecked, BSMVegaChecked,
+			BSMGammaChecked, Precise.CallThetaChecked, BSMPutThetaChecked, BSMCallRhoChecked, Precise.PutRhoChecked,
+			BSMCallPhiChecked, BSMPutPhiChecked, BSMVannaChecked, BSMVolgaChecked, BSMCallCharmChecked,
+			Precise.PutCharmChecked, BSMSpeedChecked,
+		}
+		for j, check := range checks {
+			value, err := check(table.S, table.K, table.r, table.q, table.sigma, table.T)
+			if err != table.err {
+				t.Errorf("case %d, function %d: expected error %v, got %v\n", i, j, table.err, err)
+			}
+			if err == nil && (math.IsNaN(value) || math.IsInf(value, 0)) {
+				t.Errorf("case %d, function %d: value=%g\n", i, j, value)
+			}
+		}
+	}
+}
+
+func TestCheckedMatchesFastPath(t *testing.T) {
+	const S, K, r, q, sigma, T = 100.0, 110.0, 0.02, 0.01, 0.3, 0.5
+
+	call, err1 := BSCallPriceChecked(S, K, r, sigma, T)
+	put, err2 := BSPutPriceChecked(S, K, r, sigma, T)
+	callBSM, err3 := BSMCallPriceChecked(S, K, r, q, sigma, T)
+	vega, err4 := BSMVegaChecked(S, K, r, q, sigma, T)
+	if err := misc.FirstError(err1, err2, err3, err4); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if call != BSCallPrice(S, K, r, sigma, T) || put != BSPutPrice(S, K, r, sigma, T) ||
+		callBSM != BSMCallPrice(S, K, r, q, sigma, T) || vega != BSMVega(S, K, r, q, sigma, T) {
+		t.Errorf("checked variants differ from the unchecked ones\n")
+	}
+
+	if _, err := BSCallPriceChecked(S, K, r, sigma, -T); err != misc.ErrInvalidMaturity {
+		t.Errorf("expected error %v, got %v\n", misc.ErrInvalidMaturity, err)
+	}
+}
+
+func TestValidateBlack76Inputs(t *testing.T) {
+	const F, K, D, sigma, T = 100.0, 110.0, 0.99, 0.3, 0.5
+
+	tables := []struct {
+		F, K, D, sigma, T float64
+		err               error
+	}{
+		{F, K, D, sigma, T, nil},
+		{F, K, 1.01, sigma, T, nil},
+		{F, K, D, 0, T, nil},
+		{F, K, D, sigma, 0, nil},
+		{0, K, D, sigma, T, misc.ErrInvalidPrice},
+		{F, math.NaN(), D, sigma, T, misc.ErrInvalidStrike},
+		{F, K, 0, sigma, T, misc.ErrInvalidDiscountFactor},
+		{F, K, math.Inf(1), sigma, T, misc.ErrInvalidDiscountFactor},
+		{F, K, D, -sigma, T, misc.ErrInvalidSigma},
+		{F, K, D, sigma, math.Inf(1), misc.ErrInvalidMaturity},
+	}
+
+	for i, table := range tables {
+		checks := []func(F, K, D, sigma, T float64) (float64, error){
+			Black76CallPriceChecked, Precise.Black76PutPriceChecked, Black76CallDeltaChecked, Black76PutDeltaChecked,
+			Black76GammaChecked, Black76VegaChecked,
+		}
+		for j, check := range checks {
+			value, err := check(table.F, table.K, table.D, table.sigma, table.T)
+			if err != table.err {
+				t.Errorf("case %d, function %d: expected error %v, got %v\n", i, j, table.err, err)
+			}
+			if err == nil && (math.IsNaN(value) || math.IsInf(value, 0)) {
+				t.Errorf("case %d, function %d: value=%g\n", i, j, value)
+			}
+		}
+	}
+}
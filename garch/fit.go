@@ -0,0 +1,109 @@
+package garch
+
+import (
+	"errors"
+	"math"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+var (
+	// ErrTooFewReturns is returned when there are too few returns to fit the model
+	ErrTooFewReturns = errors.New("at least 10 returns are needed to fit a GARCH model")
+	// ErrFitFailed is returned when the optimiser doesn't find parameters
+	ErrFitFailed = errors.New("GARCH fit failed")
+)
+
+const minReturns = 10
+
+// Fit estimates the GARCH(1,1), or GJR-GARCH(1,1) if gjr is set, parameters by maximising the Gaussian likelihood
+// of the log-returns observed every dt years. The returns are demeaned with their sample mean and the variance
+// recursion starts from their sample variance, the returned model holds the variance of the return following the last one.
+// The search is over the unconditional variance, the persistence and the shares of the persistence due to the
+// shocks which keeps the parameters valid and stationary.
+func Fit(returns []float64, dt float64, gjr bool) (Model, error) {
+	if !(dt > 0) || math.IsInf(dt, 1) {
+		return Model{}, ErrInvalidStep
+	}
+	n := len(returns)
+	if n < minReturns {
+		return Model{}, ErrTooFewReturns
+	}
+	var mean, variance float64
+	for _, r := range returns {
+		mean += r / float64(n)
+	}
+	residuals := make([]float64, n)
+	for i, r := range returns {
+		residuals[i] = r - mean
+		variance += residuals[i] * residuals[i] / float64(n)
+	}
+	if !(variance > 0) {
+		return Model{}, ErrFitFailed
+	}
+
+	toParams := func(x []float64) Params {
+		v := variance * math.Exp(x[0])
+		persistence := 1 / (1 + math.Exp(-x[1]))
+		// softmax shares of the persistence for Alpha, Gamma / 2 and Beta
+		shares := []float64{math.Exp(x[2]), 0, 1}
+		if gjr {
+			shares[1] = math.Exp(x[3])
+		}
+		total := shares[0] + shares[1] + shares[2]
+		return Params{
+			Omega: v * (1 - persistence),
+			Alpha: persistence * shares[0] / total,
+			Gamma: 2 * persistence * shares[1] / total,
+			Beta:  persistence * shares[2] / total,
+		}
+	}
+	problem := optimize.Problem{
+		Func: func(x []float64) float64 {
+			return -logLikelihood(toParams(x), residuals, variance)
+		},
+	}
+	// start at Alpha = 0.05, Gamma = 0.05 and Beta = 0.88 for GJR or Alpha = 0.08 and Beta = 0.9
+	x0 := []float64{0, math.Log(0.98 / 0.02), math.Log(0.08 / 0.9)}
+	if gjr {
+		x0 = []float64{0, math.Log(0.955 / 0.045), math.Log(0.05 / 0.88), math.Log(0.025 / 0.88)}
+	}
+	settings := &optimize.Settings{
+		Converger:       &optimize.FunctionConverge{Absolute: 1e-12, Relative: 1e-12, Iterations: 200},
+		MajorIterations: 20000,
+	}
+	result, err := optimize.Minimize(problem, x0, settings, &optimize.NelderMead{})
+	if result == nil || math.IsNaN(result.F) || math.IsInf(result.F, 0) {
+		if err == nil {
+			err = ErrFitFailed
+		}
+		return Model{}, err
+	}
+
+	params := toParams(result.X)
+	h := variance
+	for _, e := range residuals {
+		h = params.nextVariance(e, h)
+	}
+	return Model{Params: params, Dt: dt, Mean: mean, Variance: h}, nil
+}
+
+// LogLikelihood returns the Gaussian log-likelihood of the log-returns under the model with the parameters p,
+// the returns are taken relative to mean and the variance recursion starts from h0
+func LogLikelihood(p Params, returns []float64, mean, h0 float64) float64 {
+	residuals := make([]float64, len(returns))
+	for i, r := range returns {
+		residuals[i] = r - mean
+	}
+	return logLikelihood(p, residuals, h0)
+}
+
+func logLikelihood(p Params, residuals []float64, h0 float64) float64 {
+	h := h0
+	var ll float64
+	for _, e := range residuals {
+		ll -= 0.5 * (math.Log(2*math.Pi*h) + e*e/h)
+		h = p.nextVariance(e, h)
+	}
+	return ll
+}
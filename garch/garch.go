@@ -0,0 +1,149 @@
+package garch
+
+import (
+	"errors"
+	"math"
+
+	"code.vegaprotocol.io/quant/interfaces"
+	"code.vegaprotocol.io/quant/riskmeasures"
+	"code.vegaprotocol.io/quant/riskmodelbs"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// The GARCH(1,1) model of Bollerslev and its GJR extension of Glosten, Jagannathan and Runkle give the variance of
+// the next log-return r_t = Mean + e_t, observed every Dt years, as
+// h_t = Omega + (Alpha + Gamma 1{e_{t-1} < 0}) e_{t-1}^2 + Beta h_{t-1}
+// so that the variance is high after large (and with Gamma > 0 negative) returns and reverts to
+// Omega / (1 - Persistence) with Persistence = Alpha + Gamma / 2 + Beta.
+
+const probabilityTolerance = 1e-3
+
+var (
+	// ErrInvalidParams is returned when Omega isn't positive or Alpha, Beta or Alpha + Gamma are negative
+	ErrInvalidParams = errors.New("GARCH parameters must have Omega > 0 and Alpha, Beta, Alpha + Gamma >= 0")
+	// ErrNotStationary is returned when the persistence isn't below 1 so that the variance doesn't revert
+	ErrNotStationary = errors.New("GARCH persistence must be below 1")
+	// ErrInvalidStep is returned when the time between returns isn't positive
+	ErrInvalidStep = errors.New("time between returns must be positive")
+)
+
+// Params are the parameters of the GARCH(1,1) model, Gamma is zero for GARCH and the leverage effect for GJR-GARCH
+type Params struct {
+	Omega float64
+	Alpha float64
+	Beta  float64
+	Gamma float64
+}
+
+// Persistence returns Alpha + Gamma / 2 + Beta, the rate at which the variance forecasts revert
+// to the unconditional variance for returns symmetric around their mean
+func (p Params) Persistence() float64 {
+	return p.Alpha + 0.5*p.Gamma + p.Beta
+}
+
+// UnconditionalVariance returns the long run variance of a single return Omega / (1 - Persistence)
+func (p Params) UnconditionalVariance() float64 {
+	return p.Omega / (1 - p.Persistence())
+}
+
+// Validate checks that the parameters give a positive and stationary variance
+func (p Params) Validate() error {
+	if !(p.Omega > 0) || !(p.Alpha >= 0) || !(p.Beta >= 0) || !(p.Alpha+p.Gamma >= 0) || math.IsInf(p.Omega, 1) {
+		return ErrInvalidParams
+	}
+	if !(p.Persistence() < 1) {
+		return ErrNotStationary
+	}
+	return nil
+}
+
+// nextVariance returns the variance following the residual e and the variance h
+func (p Params) nextVariance(e, h float64) float64 {
+	shock := p.Alpha
+	if e < 0 {
+		shock += p.Gamma
+	}
+	return p.Omega + shock*e*e + p.Beta*h
+}
+
+// Model is a GARCH(1,1) model for log-returns observed every Dt years with the given mean per return,
+// Variance is the conditional variance of the next return.
+// Model implements interfaces.AnalyticalModel with the price lognormal with the total variance forecast over the horizon.
+type Model struct {
+	Params   Params
+	Dt       float64
+	Mean     float64
+	Variance float64
+}
+
+// Update rolls the model forward by the observed log-return r, i.e. sets Variance to the variance of the following return
+func (m *Model) Update(r float64) {
+	m.Variance = m.Params.nextVariance(r-m.Mean, m.Variance)
+}
+
+// ForecastVariance returns the variances of the next steps returns, E[h_{t+k}] = v + Persistence^k (h_t - v)
+// where v is the unconditional variance
+func (m Model) ForecastVariance(steps int) []float64 {
+	v, persistence := m.Params.UnconditionalVariance(), m.Params.Persistence()
+	forecast := make([]float64, steps)
+	deviation := m.Variance - v
+	for k := range forecast {
+		forecast[k] = v + deviation
+		deviation *= persistence
+	}
+	return forecast
+}
+
+// HorizonVariance returns the variance of the log-return over the horizon tau (in years), i.e. the sum of the
+// forecast variances of the K = tau / Dt returns within the horizon K v + (h - v) (1 - Persistence^K) / (1 - Persistence),
+// which is also used for a fractional number of returns
+func (m Model) HorizonVariance(tau float64) float64 {
+	v, persistence := m.Params.UnconditionalVariance(), m.Params.Persistence()
+	steps := tau / m.Dt
+	if persistence == 0 {
+		return steps*v + (m.Variance-v)*math.Min(steps, 1)
+	}
+	return steps*v + (m.Variance-v)*(1-math.Pow(persistence, steps))/(1-persistence)
+}
+
+// HorizonSigma returns the annualised volatility consistent with the variance forecast over the horizon tau
+func (m Model) HorizonSigma(tau float64) float64 {
+	return math.Sqrt(m.HorizonVariance(tau) / tau)
+}
+
+// ModelParamsBS returns the Black-Scholes model parameters with the volatility forecast over the horizon tau
+// and the growth rate matching the mean return, so that the risk factors from riskmodelbs.RiskFactorsForward
+// over the horizon tau are those of RiskFactorsForward
+func (m Model) ModelParamsBS(tau, r float64) riskmodelbs.ModelParamsBS {
+	sigma := m.HorizonSigma(tau)
+	return riskmodelbs.ModelParamsBS{Mu: m.Mean/m.Dt + 0.5*sigma*sigma, R: r, Sigma: sigma}
+}
+
+// logReturnParams returns the mean and standard deviation of the log-return over the horizon tau
+func (m Model) logReturnParams(tau float64) (mean, stdDev float64) {
+	return m.Mean * tau / m.Dt, math.Sqrt(m.HorizonVariance(tau))
+}
+
+// GetProbabilityDistribution returns the lognormal distribution of the price over the horizon tau given the current price S
+func (m Model) GetProbabilityDistribution(S, tau float64) interfaces.AnalyticalDistribution {
+	mean, stdDev := m.logReturnParams(tau)
+	return &distuv.LogNormal{Mu: math.Log(S) + mean, Sigma: stdDev}
+}
+
+// GetProbabilityTolerance specifies the probability tolerance alphaModel that the model supports
+func (m Model) GetProbabilityTolerance() float64 {
+	return probabilityTolerance
+}
+
+// RiskFactorsForward calculates the risk factors with the price lognormal over the horizon tau
+// with the variance forecast by the GARCH model from the current conditions
+func RiskFactorsForward(lambd, tau float64, m Model) riskmodelbs.RiskFactors {
+	muBar, sigmaBar := m.logReturnParams(tau)
+
+	riskFactorShort := riskmeasures.NegativeLogNormalEs(muBar, sigmaBar, lambd) - 1.0
+	riskFactorLong := riskmeasures.LogNormalEs(muBar, sigmaBar, lambd) + 1.0
+
+	factors := riskmodelbs.RiskFactors{Long: riskFactorLong, Short: riskFactorShort}
+	return factors
+}
@@ -0,0 +1,161 @@
+package garch
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/interfaces"
+	"code.vegaprotocol.io/quant/riskmodelbs"
+
+	"golang.org/x/exp/rand"
+)
+
+const dt = 1.0 / 365.25
+
+// simulate returns log-returns from the model starting at its unconditional variance
+func simulate(rng *rand.Rand, p Params, mean float64, n int) []float64 {
+	returns := make([]float64, n)
+	h := p.UnconditionalVariance()
+	for i := range returns {
+		e := math.Sqrt(h) * rng.NormFloat64()
+		returns[i] = mean + e
+		h = p.nextVariance(e, h)
+	}
+	return returns
+}
+
+func TestFitRecoversParams(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	tables := []struct {
+		name   string
+		params Params
+		gjr    bool
+	}{
+		{"garch", Params{Omega: 2e-5, Alpha: 0.1, Beta: 0.85}, false},
+		{"gjr", Params{Omega: 2e-5, Alpha: 0.03, Beta: 0.85, Gamma: 0.15}, true},
+	}
+
+	for _, table := range tables {
+		returns := simulate(rng, table.params, 1e-4, 10000)
+		model, err := Fit(returns, dt, table.gjr)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		p := model.Params
+		error := math.Abs(p.Alpha-table.params.Alpha) + math.Abs(p.Beta-table.params.Beta) + math.Abs(p.Gamma-table.params.Gamma)
+		if math.IsNaN(error) || error > 0.06 || math.Abs(p.UnconditionalVariance()/table.params.UnconditionalVariance()-1) > 0.15 {
+			t.Errorf("%s: fitted %+v, expected %+v\n", table.name, p, table.params)
+		}
+		if err := p.Validate(); err != nil {
+			t.Errorf("%s: fitted %+v aren't valid: %v\n", table.name, p, err)
+		}
+
+		// the fit maximises the likelihood
+		h0 := 0.0
+		for _, r := range returns {
+			h0 += (r - model.Mean) * (r - model.Mean) / float64(len(returns))
+		}
+		llFit := LogLikelihood(p, returns, model.Mean, h0)
+		if llTrue := LogLikelihood(table.params, returns, model.Mean, h0); llTrue > llFit {
+			t.Errorf("%s: true log-likelihood %g above the fitted %g\n", table.name, llTrue, llFit)
+		}
+
+		// the model holds the variance following the last return
+		expected := Model{Params: p, Dt: dt, Mean: model.Mean, Variance: h0}
+		for _, r := range returns {
+			expected.Update(r)
+		}
+		if math.Abs(model.Variance-expected.Variance) > 1e-15 {
+			t.Errorf("%s: variance=%g, expected=%g\n", table.name, model.Variance, expected.Variance)
+		}
+	}
+}
+
+func TestForecast(t *testing.T) {
+	const testTolerance float64 = 1e-12
+	p := Params{Omega: 2e-5, Alpha: 0.05, Beta: 0.9, Gamma: 0.04}
+	v := p.UnconditionalVariance()
+
+	for _, h := range []float64{0.2 * v, v, 5 * v} {
+		m := Model{Params: p, Dt: dt, Mean: 1e-4, Variance: h}
+		forecast := m.ForecastVariance(1000)
+		if math.Abs(forecast[0]-h) > testTolerance || math.Abs(forecast[999]/v-1) > 1e-6 {
+			t.Errorf("h=%g: forecast starts at %g and ends at %g, unconditional variance %g\n", h, forecast[0], forecast[999], v)
+		}
+		// the horizon variance sums the forecasts
+		var total float64
+		for k, f := range forecast[:10] {
+			total += f
+			if error := math.Abs(m.HorizonVariance(float64(k+1)*dt) - total); error > testTolerance {
+				t.Errorf("h=%g, k=%d: horizon variance=%g, sum of forecasts=%g\n", h, k+1, m.HorizonVariance(float64(k+1)*dt), total)
+			}
+		}
+		// over a long horizon the volatility is the unconditional one
+		if error := math.Abs(m.HorizonSigma(100)/math.Sqrt(v/dt) - 1); error > 5e-3 {
+			t.Errorf("h=%g: long run sigma=%g, expected %g\n", h, m.HorizonSigma(100), math.Sqrt(v/dt))
+		}
+	}
+}
+
+func TestRiskFactorsReactToConditions(t *testing.T) {
+	const testTolerance float64 = 1e-12
+	const lambda, tau = 0.01, 2.0 / 365.25
+	p := Params{Omega: 2e-5, Alpha: 0.1, Beta: 0.85}
+	calm := Model{Params: p, Dt: dt, Mean: 1e-4, Variance: 0.5 * p.UnconditionalVariance()}
+	stressed := calm
+	stressed.Update(-0.1)
+
+	calmFactors, stressedFactors := RiskFactorsForward(lambda, tau, calm), RiskFactorsForward(lambda, tau, stressed)
+	if stressedFactors.Long <= calmFactors.Long || stressedFactors.Short <= calmFactors.Short {
+		t.Errorf("risk factors after a large return %+v aren't above %+v\n", stressedFactors, calmFactors)
+	}
+
+	// the horizon-consistent Black-Scholes parameters give the same risk factors
+	for _, m := range []Model{calm, stressed} {
+		bs := riskmodelbs.RiskFactorsForward(lambda, tau, m.ModelParamsBS(tau, 0.01))
+		factors := RiskFactorsForward(lambda, tau, m)
+		error := math.Abs(bs.Long-factors.Long) + math.Abs(bs.Short-factors.Short)
+		if math.IsNaN(error) || error > testTolerance {
+			t.Errorf("risk factors %+v, from Black-Scholes %+v\n", factors, bs)
+		}
+	}
+
+	// the distribution is lognormal with the horizon variance
+	var model interfaces.AnalyticalModel = stressed
+	d := model.GetProbabilityDistribution(100, tau)
+	mean, stdDev := stressed.logReturnParams(tau)
+	expectedMean := 100 * math.Exp(mean+0.5*stdDev*stdDev)
+	if math.Abs(d.Mean()/expectedMean-1) > testTolerance || stdDev*stdDev != stressed.HorizonVariance(tau) {
+		t.Errorf("mean=%g, expected=%g\n", d.Mean(), expectedMean)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	paramTables := []struct {
+		params Params
+		err    error
+	}{
+		{Params{Omega: 1e-5, Alpha: 0.1, Beta: 0.8}, nil},
+		{Params{Omega: 0, Alpha: 0.1, Beta: 0.8}, ErrInvalidParams},
+		{Params{Omega: 1e-5, Alpha: -0.1, Beta: 0.8}, ErrInvalidParams},
+		{Params{Omega: 1e-5, Alpha: 0.1, Beta: 0.8, Gamma: -0.2}, ErrInvalidParams},
+		{Params{Omega: 1e-5, Alpha: 0.1, Beta: 0.9}, ErrNotStationary},
+		{Params{Omega: 1e-5, Alpha: 0.1, Beta: 0.8, Gamma: 0.2}, ErrNotStationary},
+	}
+	for i, table := range paramTables {
+		if err := table.params.Validate(); err != table.err {
+			t.Errorf("case %d: expected error %v, got %v\n", i, table.err, err)
+		}
+	}
+
+	returns := simulate(rand.New(rand.NewSource(1)), Params{Omega: 1e-5, Alpha: 0.1, Beta: 0.8}, 0, 100)
+	if _, err := Fit(returns[:minReturns-1], dt, false); err != ErrTooFewReturns {
+		t.Errorf("expected error %v, got %v\n", ErrTooFewReturns, err)
+	}
+	if _, err := Fit(returns, 0, false); err != ErrInvalidStep {
+		t.Errorf("expected error %v, got %v\n", ErrInvalidStep, err)
+	}
+	if _, err := Fit(make([]float64, 20), dt, false); err != ErrFitFailed {
+		t.Errorf("expected error %v, got %v\n", ErrFitFailed, err)
+	}
+}
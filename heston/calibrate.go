@@ -0,0 +1,84 @@
+package heston
+
+import (
+	"errors"
+	"math"
+
+	"code.vegaprotocol.io/quant/bsformula"
+	"code.vegaprotocol.io/quant/volsurface"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+var (
+	// ErrTooFewQuotes is returned when there are fewer quotes than the 5 calibrated parameters
+	ErrTooFewQuotes = errors.New("at least 5 quotes are needed to calibrate the Heston model")
+	// ErrInvalidQuote is returned when a quote has a non-positive expiry, strike or volatility
+	ErrInvalidQuote = errors.New("quote expiry, strike and volatility must be positive")
+	// ErrCalibrationFailed is returned when the optimiser doesn't find parameters
+	ErrCalibrationFailed = errors.New("Heston calibration failed")
+)
+
+// Calibrate returns the parameters V0, Kappa, Theta, VolOfVol and Rho that minimise the sum of squared differences between
+// the model and the Black-Scholes-Merton prices at the quoted implied volatilities, each divided by the vega of the quote
+// so that the errors are approximately in volatility terms. The search starts from initial, which also provides Mu, R and Q.
+func Calibrate(S float64, quotes []volsurface.Quote, initial ModelParamsHeston) (ModelParamsHeston, error) {
+	if len(quotes) < 5 {
+		return ModelParamsHeston{}, ErrTooFewQuotes
+	}
+	r, q := initial.R, initial.Q
+	prices := make([]float64, len(quotes))
+	vegas := make([]float64, len(quotes))
+	for i, quote := range quotes {
+		if !(quote.Expiry > 0) || !(quote.Strike > 0) || !(quote.Vol > 0) {
+			return ModelParamsHeston{}, ErrInvalidQuote
+		}
+		prices[i] = bsformula.Precise.CallPrice(S, quote.Strike, r, q, quote.Vol, quote.Expiry)
+		vegas[i] = bsformula.BSMVega(S, quote.Strike, r, q, quote.Vol, quote.Expiry)
+	}
+
+	// positive parameters are optimised in logs and the correlation via tanh scaled into (-maxCorrelation, maxCorrelation),
+	// which stays away from +-1 even when tanh rounds to it
+	toParams := func(x []float64) ModelParamsHeston {
+		p := initial
+		p.V0, p.Kappa, p.Theta, p.VolOfVol = math.Exp(x[0]), math.Exp(x[1]), math.Exp(x[2]), math.Exp(x[3])
+		p.Rho = maxCorrelation * math.Tanh(x[4])
+		return p
+	}
+	problem := optimize.Problem{
+		Func: func(x []float64) float64 {
+			p := toParams(x)
+			if p.Validate() != nil {
+				return math.Inf(1)
+			}
+			sse := 0.0
+			for i, quote := range quotes {
+				e := (p.CallPrice(S, quote.Strike, quote.Expiry) - prices[i]) / math.Max(vegas[i], 1e-8*S)
+				sse += e * e
+			}
+			if math.IsNaN(sse) {
+				return math.Inf(1)
+			}
+			return sse
+		},
+	}
+
+	x0 := []float64{math.Log(initial.V0), math.Log(initial.Kappa), math.Log(initial.Theta),
+		math.Log(initial.VolOfVol), math.Atanh(math.Max(math.Min(initial.Rho, 0.99), -0.99) / maxCorrelation)}
+	settings := &optimize.Settings{
+		Converger:       &optimize.FunctionConverge{Absolute: 1e-20, Relative: 1e-10, Iterations: 100},
+		MajorIterations: 5000,
+	}
+	result, err := optimize.Minimize(problem, x0, settings, &optimize.NelderMead{})
+	if result == nil || math.IsInf(result.F, 0) || math.IsNaN(result.F) {
+		if err == nil {
+			err = ErrCalibrationFailed
+		}
+		return ModelParamsHeston{}, err
+	}
+	calibrated := toParams(result.X)
+	if err := calibrated.Validate(); err != nil {
+		return ModelParamsHeston{}, err
+	}
+	return calibrated, nil
+}
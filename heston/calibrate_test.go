@@ -0,0 +1,44 @@
+package heston
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/bsformula"
+	"code.vegaprotocol.io/quant/volsurface"
+)
+
+func TestCalibrateRecoversSmile(t *testing.T) {
+	const testTolerance float64 = 1.0e-4
+	const S float64 = 100.0
+	p := ModelParamsHeston{R: 0.02, Q: 0.01, V0: 0.3, Kappa: 3, Theta: 0.4, VolOfVol: 1.2, Rho: -0.4}
+
+	var quotes []volsurface.Quote
+	for _, T := range []float64{0.1, 0.5, 1} {
+		for _, K := range []float64{70, 85, 100, 115, 130} {
+			vol, err := bsformula.Precise.ImpliedVol(S, K, p.R, p.Q, T, p.CallPrice(S, K, T), true)
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+			quotes = append(quotes, volsurface.Quote{Expiry: T, Strike: K, Vol: vol})
+		}
+	}
+
+	initial := ModelParamsHeston{R: p.R, Q: p.Q, V0: 0.2, Kappa: 1, Theta: 0.2, VolOfVol: 0.5, Rho: 0}
+	calibrated, err := Calibrate(S, quotes, initial)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	for _, quote := range quotes {
+		price := calibrated.CallPrice(S, quote.Strike, quote.Expiry)
+		vol, err := bsformula.Precise.ImpliedVol(S, quote.Strike, p.R, p.Q, quote.Expiry, price, true)
+		error := math.Abs(vol - quote.Vol)
+		if err != nil || math.IsNaN(error) || error > testTolerance {
+			t.Errorf("K=%g, T=%g: vol=%g, quote=%g (calibrated %+v)\n", quote.Strike, quote.Expiry, vol, quote.Vol, calibrated)
+		}
+	}
+
+	if _, err := Calibrate(S, quotes[:4], initial); err != ErrTooFewQuotes {
+		t.Errorf("Expected ErrTooFewQuotes, got %v", err)
+	}
+}
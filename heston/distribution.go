@@ -0,0 +1,97 @@
+package heston
+
+import (
+	"math"
+	"math/cmplx"
+
+	"code.vegaprotocol.io/quant/interfaces"
+	"code.vegaprotocol.io/quant/misc"
+)
+
+const (
+	probabilityTolerance = 1e-3
+	quantileMaxIter      = 200
+	quantileTol          = 1e-12
+)
+
+// Distribution is the real-world distribution of the risky asset's price at the horizon tau in the Heston model,
+// the CDF is found by Fourier inversion of the characteristic function and the quantile by Brent's method.
+// It implements interfaces.AnalyticalDistribution.
+type Distribution struct {
+	params ModelParamsHeston
+	S      float64
+	tau    float64
+}
+
+// GetProbabilityDistribution returns the distribution of the price at the horizon tau given the current price S
+func (modelParams ModelParamsHeston) GetProbabilityDistribution(S, tau float64) interfaces.AnalyticalDistribution {
+	return &Distribution{params: modelParams, S: S, tau: tau}
+}
+
+// GetProbabilityTolerance specifies the probability tolerance alphaModel that the model supports. It shouldn't be used for any calculations involving alpha < alphaModel or alpha > 1-alphaModel
+func (modelParams ModelParamsHeston) GetProbabilityTolerance() (alphaModel float64) {
+	alphaModel = probabilityTolerance
+	return alphaModel
+}
+
+// Mean returns the expected price S exp(mu tau)
+func (d *Distribution) Mean() float64 {
+	return d.S * math.Exp(d.params.Mu*d.tau)
+}
+
+// Variance returns the variance of the price, which is infinite when the second moment explodes before tau
+func (d *Distribution) Variance() float64 {
+	// E[exp(2X)] is the characteristic function at u = -2i
+	secondMoment := real(d.params.charFunc(-2i, d.tau))
+	if math.IsNaN(secondMoment) || secondMoment <= 0 {
+		return math.Inf(1)
+	}
+	mean := d.Mean()
+	return mean * mean * (secondMoment - 1)
+}
+
+// CDF returns the probability that the price at the horizon doesn't exceed x using the Gil-Pelaez inversion formula
+func (d *Distribution) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if math.IsInf(x, 1) {
+		return 1
+	}
+	y := math.Log(x/d.S) - d.params.Mu*d.tau
+	integrand := func(u float64) float64 {
+		if u == 0 {
+			return 0
+		}
+		return imag(cmplx.Exp(complex(0, -u*y))*d.params.charFunc(complex(u, 0), d.tau)) / u
+	}
+	cdf := 0.5 - d.params.integrate(integrand, d.tau, y)/math.Pi
+	return math.Max(math.Min(cdf, 1), 0)
+}
+
+// Quantile returns the price x such that CDF(x) = p
+func (d *Distribution) Quantile(p float64) float64 {
+	if p <= 0 {
+		return 0
+	}
+	if p >= 1 {
+		return math.Inf(1)
+	}
+	// bracket the log-return around the mean in units of its standard deviation
+	sd := math.Sqrt(d.params.expectedVariance(d.tau))
+	f := func(y float64) float64 {
+		return d.CDF(d.S*math.Exp(y+d.params.Mu*d.tau)) - p
+	}
+	lo, hi := -5*sd, 5*sd
+	for f(lo) > 0 && lo > -1e3*sd {
+		lo *= 2
+	}
+	for f(hi) < 0 && hi < 1e3*sd {
+		hi *= 2
+	}
+	y, err := misc.FindRootBrent(f, lo, hi, quantileMaxIter, quantileTol*sd)
+	if err != nil {
+		return math.NaN()
+	}
+	return d.S * math.Exp(y+d.params.Mu*d.tau)
+}
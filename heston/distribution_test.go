@@ -0,0 +1,90 @@
+package heston
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"code.vegaprotocol.io/quant/pricedistribution"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// simulate returns sorted samples of the price at the horizon tau using the full truncation Euler scheme
+func simulate(p ModelParamsHeston, S, tau float64, numPaths, numSteps int) []float64 {
+	rng := rand.New(rand.NewSource(1))
+	normal := distuv.Normal{Mu: 0, Sigma: 1, Src: rng}
+	dt := tau / float64(numSteps)
+	samples := make([]float64, numPaths)
+	for path := range samples {
+		logS, v := math.Log(S), p.V0
+		for step := 0; step < numSteps; step++ {
+			z1 := normal.Rand()
+			z2 := p.Rho*z1 + math.Sqrt(1-p.Rho*p.Rho)*normal.Rand()
+			vPlus := math.Max(v, 0)
+			logS += (p.Mu-0.5*vPlus)*dt + math.Sqrt(vPlus*dt)*z1
+			v += p.Kappa*(p.Theta-vPlus)*dt + p.VolOfVol*math.Sqrt(vPlus*dt)*z2
+		}
+		samples[path] = math.Exp(logS)
+	}
+	sort.Float64s(samples)
+	return samples
+}
+
+func TestDistributionAgainstMC(t *testing.T) {
+	const S float64 = 100.0
+	const numPaths, numSteps int = 40000, 100
+	p := fangOosterlee
+	p.Mu = 0.05
+
+	for _, tau := range []float64{1.0 / 365.25, 0.5} {
+		samples := simulate(p, S, tau, numPaths, numSteps)
+		d := p.GetProbabilityDistribution(S, tau)
+
+		for _, prob := range []float64{0.001, 0.01, 0.1, 0.5, 0.9, 0.99, 0.999} {
+			x := stat.Quantile(prob, stat.Empirical, samples, nil)
+			error := math.Abs(d.CDF(x) - prob)
+			tolerance := 4*math.Sqrt(prob*(1-prob)/float64(numPaths)) + 1e-3
+			if math.IsNaN(error) || error > tolerance {
+				t.Errorf("tau=%g, p=%g: CDF=%g at the MC quantile %g\n", tau, prob, d.CDF(x), x)
+			}
+		}
+
+		mean, variance := stat.MeanVariance(samples, nil)
+		if error := math.Abs(d.Mean()-mean) / math.Sqrt(variance); error > 0.02 {
+			t.Errorf("tau=%g: mean=%g, MC mean=%g\n", tau, d.Mean(), mean)
+		}
+		if error := math.Abs(d.Variance()-variance) / variance; error > 0.05 {
+			t.Errorf("tau=%g: variance=%g, MC variance=%g\n", tau, d.Variance(), variance)
+		}
+	}
+}
+
+func TestQuantileInvertsCDF(t *testing.T) {
+	const testTolerance float64 = 1.0e-9
+	p := fangOosterlee
+	p.Mu = -0.1
+
+	for _, tau := range []float64{1.0 / 365.25 / 24, 1.0 / 365.25, 0.25, 2} {
+		d := p.GetProbabilityDistribution(100, tau)
+		for _, prob := range []float64{p.GetProbabilityTolerance(), 0.05, 0.5, 0.95, 1 - p.GetProbabilityTolerance()} {
+			x := d.Quantile(prob)
+			error := math.Abs(d.CDF(x) - prob)
+			if math.IsNaN(error) || error > testTolerance {
+				t.Errorf("tau=%g, p=%g: quantile=%g, CDF(quantile)=%g\n", tau, prob, x, d.CDF(x))
+			}
+		}
+
+		// the distribution works with the price distribution functions
+		minPrice, maxPrice := pricedistribution.PriceRange(d, 0.99)
+		if !(minPrice < 100 && 100 < maxPrice) {
+			t.Errorf("tau=%g: price range [%g, %g] doesn't contain the current price\n", tau, minPrice, maxPrice)
+		}
+		probability := pricedistribution.ProbabilityOfTrading(d, 100, true, true, minPrice, maxPrice)
+		if !(probability > 0 && probability < 1) {
+			t.Errorf("tau=%g: probability of trading %g\n", tau, probability)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package heston
+
+import (
+	"math"
+	"math/cmplx"
+
+	"gonum.org/v1/gonum/integrate/quad"
+)
+
+// ModelParamsHeston collect the parameters of the Heston stochastic volatility model in which the variance v of
+// the risky asset's returns follows dv = Kappa (Theta - v) dt + VolOfVol sqrt(v) dZ starting from V0
+// with correlation Rho between Z and the brownian motion driving the asset.
+// Here mu is the real-world measure growth rate, r is the risk-free interest rate and q is the continuous dividend
+// (or carry) yield used when pricing options, the variance follows the same dynamics under both measures.
+// VolOfVol must be positive, for a constant variance use the Black-Scholes model instead, see Validate.
+type ModelParamsHeston struct {
+	Mu       float64
+	R        float64
+	Q        float64
+	V0       float64
+	Kappa    float64
+	Theta    float64
+	VolOfVol float64
+	Rho      float64
+}
+
+const (
+	// quadratureNodes is the number of Gauss-Legendre nodes in each panel of the Fourier integrals
+	quadratureNodes = 16
+	// minPanelWidth is the width of the first panel of the Fourier integrals
+	minPanelWidth = 0.25
+	// maxPanels caps the number of panels of the Fourier integrals
+	maxPanels = 4096
+	// tailExponent is such that the characteristic function is below exp(-tailExponent) beyond the integration range
+	tailExponent = 40.0
+	// maxCorrelation bounds the calibrated |Rho| away from 1 where the characteristic function stops decaying exponentially
+	maxCorrelation = 0.9999
+)
+
+var legendreNodes, legendreWeights = func() ([]float64, []float64) {
+	x := make([]float64, quadratureNodes)
+	weight := make([]float64, quadratureNodes)
+	quad.Legendre{}.FixedLocations(x, weight, 0, 1)
+	return x, weight
+}()
+
+// charFunc returns the characteristic function E[exp(iuX)] of X = ln(S_T / S) - bT where b is the drift of the asset,
+// in the formulation of Albrecher et al. (The little Heston trap, Wilmott, 2007) which avoids the branch cut of the complex logarithm
+func (p ModelParamsHeston) charFunc(u complex128, T float64) complex128 {
+	xi2 := p.VolOfVol * p.VolOfVol
+	iu := 1i * u
+	beta := complex(p.Kappa, 0) - complex(p.Rho*p.VolOfVol, 0)*iu
+	d := cmplx.Sqrt(beta*beta + complex(xi2, 0)*(iu+u*u))
+	g := (beta - d) / (beta + d)
+	expDT := cmplx.Exp(-d * complex(T, 0))
+
+	C := complex(p.Kappa*p.Theta/xi2, 0) * ((beta-d)*complex(T, 0) - 2*cmplx.Log((1-g*expDT)/(1-g)))
+	D := (beta - d) / complex(xi2, 0) * (1 - expDT) / (1 - g*expDT)
+	return cmplx.Exp(C + D*complex(p.V0, 0))
+}
+
+// expectedVariance returns the expected total variance over [0, T]
+func (p ModelParamsHeston) expectedVariance(T float64) float64 {
+	if p.Kappa*T < 1e-8 {
+		return p.V0 * T
+	}
+	return p.Theta*T + (p.V0-p.Theta)*(1-math.Exp(-p.Kappa*T))/p.Kappa
+}
+
+// integrate returns the integral of f over [0, inf) for a characteristic function of the expiry T
+// and an integrand oscillating as exp(iux), it is NaN when the parameters fail Validate
+func (p ModelParamsHeston) integrate(f func(u float64) float64, T, x float64) float64 {
+	if p.Validate() != nil {
+		return math.NaN()
+	}
+	// the characteristic function decays as exp(-w u^2 / 2) for moderate u and as exp(-rate u) asymptotically
+	sqrtW := math.Sqrt(p.expectedVariance(T))
+	rate := (p.V0 + p.Kappa*p.Theta*T) * math.Sqrt(1-p.Rho*p.Rho) / p.VolOfVol
+	upper := math.Max(math.Sqrt(2*tailExponent)/sqrtW, tailExponent/rate)
+	if math.IsInf(upper, 0) || math.IsNaN(upper) {
+		return math.NaN()
+	}
+	maxWidth := math.Min(1/sqrtW, 5/rate)
+	if x != 0 {
+		maxWidth = math.Min(maxWidth, math.Pi/math.Abs(x))
+	}
+	maxWidth = math.Max(maxWidth, upper/maxPanels)
+
+	// the integrands have poles at a distance of order one from the real axis near u = 0
+	// so the panels start narrow and grow geometrically up to maxWidth
+	integral := 0.0
+	for from := 0.0; from < upper; {
+		width := math.Min(math.Max(from, minPanelWidth), maxWidth)
+		for i, node := range legendreNodes {
+			integral += legendreWeights[i] * width * f(from+node*width)
+		}
+		from += width
+	}
+	return integral
+}
+
+// CallPrice calculates the call option price on the underlying S with strike K and maturity T
+// using the Fourier inversion formula of Lewis (A simple option formula for general jump-diffusion and other exponential Levy processes, 2001)
+func (p ModelParamsHeston) CallPrice(S, K, T float64) float64 {
+	k := math.Log(S/K) + (p.R-p.Q)*T
+	integrand := func(u float64) float64 {
+		phi := p.charFunc(complex(u, -0.5), T)
+		return real(cmplx.Exp(complex(0, u*k))*phi) / (u*u + 0.25)
+	}
+	integral := p.integrate(integrand, T, k)
+	return S*math.Exp(-p.Q*T) - math.Sqrt(S*K)*math.Exp(-0.5*(p.R+p.Q)*T)/math.Pi*integral
+}
+
+// PutPrice calculates the put option price from the call price via put-call parity
+func (p ModelParamsHeston) PutPrice(S, K, T float64) float64 {
+	return p.CallPrice(S, K, T) - S*math.Exp(-p.Q*T) + K*math.Exp(-p.R*T)
+}
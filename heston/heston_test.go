@@ -0,0 +1,62 @@
+package heston
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/bsformula"
+)
+
+// the test case of Fang and Oosterlee (A novel pricing method for European options based on Fourier-cosine series expansions, 2008)
+var fangOosterlee = ModelParamsHeston{R: 0, Q: 0, V0: 0.0175, Kappa: 1.5768, Theta: 0.0398, VolOfVol: 0.5751, Rho: -0.5711}
+
+func TestCallPriceReferenceValue(t *testing.T) {
+	const testTolerance float64 = 1.0e-7
+	const expected = 5.785155450
+
+	price := fangOosterlee.CallPrice(100, 100, 1)
+	error := math.Abs(price - expected)
+	if math.IsNaN(error) || error > testTolerance {
+		t.Errorf("price=%.10f, expected=%.10f\n", price, expected)
+	}
+}
+
+// TestPricesReduceToBlackScholes checks that with a constant variance and little volatility of variance
+// the prices are the Black-Scholes-Merton ones (the volatility of variance can't be much smaller as the
+// characteristic function divides by its square)
+func TestPricesReduceToBlackScholes(t *testing.T) {
+	const testTolerance float64 = 1.0e-6
+	const S, r, q, sigma = 100.0, 0.03, 0.01, 0.3
+	p := ModelParamsHeston{R: r, Q: q, V0: sigma * sigma, Kappa: 2, Theta: sigma * sigma, VolOfVol: 1e-3, Rho: 0}
+
+	for _, K := range []float64{50, 80, 100, 120, 200} {
+		for _, T := range []float64{1.0 / 365.25, 0.1, 1, 5} {
+			call := p.CallPrice(S, K, T)
+			put := p.PutPrice(S, K, T)
+			error := math.Abs(call-bsformula.Precise.CallPrice(S, K, r, q, sigma, T)) +
+				math.Abs(put-bsformula.Precise.PutPrice(S, K, r, q, sigma, T))
+			if math.IsNaN(error) || error > testTolerance*S {
+				t.Errorf("K=%g, T=%g: call=%g, put=%g, error=%g\n", K, T, call, put, error)
+			}
+		}
+	}
+}
+
+// TestPricesHaveSkew checks that negative correlation gives a downward sloping smile around the money
+func TestPricesHaveSkew(t *testing.T) {
+	const S, T = 100.0, 0.5
+	p := fangOosterlee
+	var vols []float64
+	for _, K := range []float64{80, 90, 100, 110} {
+		vol, err := bsformula.Precise.ImpliedVol(S, K, 0, 0, T, p.CallPrice(S, K, T), true)
+		if err != nil {
+			t.Fatalf("K=%g: %s", K, err.Error())
+		}
+		vols = append(vols, vol)
+	}
+	for i := 1; i < len(vols); i++ {
+		if vols[i] >= vols[i-1] {
+			t.Errorf("Implied vols aren't decreasing in the strike: %v", vols)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package heston
+
+import (
+	"errors"
+	"math"
+
+	"code.vegaprotocol.io/quant/misc"
+)
+
+var (
+	// ErrInvalidVariance is returned when V0, Kappa or Theta are negative or infinite, or the variance stays at zero
+	ErrInvalidVariance = errors.New("V0, Kappa and Theta must be non-negative and finite with a positive V0 or Kappa*Theta")
+	// ErrInvalidCorrelation is returned when Rho isn't strictly between -1 and 1
+	ErrInvalidCorrelation = errors.New("correlation must be strictly between -1 and 1")
+)
+
+// Validate checks that the growth rate Mu, interest rate R and yield Q are finite, the variance parameters V0, Kappa
+// and Theta are non-negative and finite with a positive V0 or Kappa*Theta so that the variance doesn't stay at zero,
+// VolOfVol is positive and finite and Rho is strictly between -1 and 1. It returns misc.ErrInvalidRate,
+// ErrInvalidVariance, misc.ErrInvalidSigma or ErrInvalidCorrelation otherwise.
+// The prices and the distribution are NaN for parameters that fail validation.
+func (p ModelParamsHeston) Validate() error {
+	return misc.FirstError(
+		misc.ValidateFinite(p.Mu, misc.ErrInvalidRate),
+		misc.ValidateFinite(p.R, misc.ErrInvalidRate),
+		misc.ValidateFinite(p.Q, misc.ErrInvalidRate),
+		misc.ValidateNonNegative(p.V0, ErrInvalidVariance),
+		misc.ValidateNonNegative(p.Kappa, ErrInvalidVariance),
+		misc.ValidateNonNegative(p.Theta, ErrInvalidVariance),
+		misc.ValidatePositive(p.V0+p.Kappa*p.Theta, ErrInvalidVariance),
+		misc.ValidatePositive(p.VolOfVol, misc.ErrInvalidSigma),
+		validateCorrelation(p.Rho),
+	)
+}
+
+func validateCorrelation(rho float64) error {
+	if !(math.Abs(rho) < 1) {
+		return ErrInvalidCorrelation
+	}
+	return nil
+}
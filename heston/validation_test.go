@@ -0,0 +1,60 @@
+package heston
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/misc"
+)
+
+func TestModelParamsValidate(t *testing.T) {
+	p := fangOosterlee
+	with := func(change func(p *ModelParamsHeston)) ModelParamsHeston {
+		q := p
+		change(&q)
+		return q
+	}
+
+	tables := []struct {
+		p   ModelParamsHeston
+		err error
+	}{
+		{p, nil},
+		{with(func(p *ModelParamsHeston) { p.Mu = math.NaN() }), misc.ErrInvalidRate},
+		{with(func(p *ModelParamsHeston) { p.Q = math.Inf(1) }), misc.ErrInvalidRate},
+		{with(func(p *ModelParamsHeston) { p.V0 = 0 }), nil},
+		{with(func(p *ModelParamsHeston) { p.Theta = 0 }), nil},
+		{with(func(p *ModelParamsHeston) { p.V0, p.Theta = 0, 0 }), ErrInvalidVariance},
+		{with(func(p *ModelParamsHeston) { p.V0, p.Kappa = 0, 0 }), ErrInvalidVariance},
+		{with(func(p *ModelParamsHeston) { p.V0 = -0.01 }), ErrInvalidVariance},
+		{with(func(p *ModelParamsHeston) { p.Kappa = math.Inf(1) }), ErrInvalidVariance},
+		{with(func(p *ModelParamsHeston) { p.VolOfVol = 0 }), misc.ErrInvalidSigma},
+		{with(func(p *ModelParamsHeston) { p.Rho = 1 }), ErrInvalidCorrelation},
+		{with(func(p *ModelParamsHeston) { p.Rho = -1 }), ErrInvalidCorrelation},
+		{with(func(p *ModelParamsHeston) { p.Rho = math.NaN() }), ErrInvalidCorrelation},
+	}
+
+	for i, table := range tables {
+		if err := table.p.Validate(); err != table.err {
+			t.Errorf("case %d: expected error %v, got %v\n", i, table.err, err)
+		}
+		price := table.p.CallPrice(100, 100, 1)
+		if (table.err == nil) == math.IsNaN(price) {
+			t.Errorf("case %d: expected a NaN price only for invalid parameters, got %g\n", i, price)
+		}
+	}
+}
+
+func TestExtremeCorrelationPrices(t *testing.T) {
+	// the correlations Calibrate can reach still give arbitrage-free prices
+	for _, rho := range []float64{-maxCorrelation, maxCorrelation} {
+		p := fangOosterlee
+		p.Rho = rho
+		for _, K := range []float64{80, 100, 120} {
+			price := p.CallPrice(100, K, 1)
+			if math.IsNaN(price) || price < math.Max(100-K, 0) || price > 100 {
+				t.Errorf("rho=%g, K=%g: price %g outside the no-arbitrage bounds\n", rho, K, price)
+			}
+		}
+	}
+}
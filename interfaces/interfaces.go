@@ -13,3 +13,8 @@ type AnalyticalModel interface {
 	GetProbabilityDistribution(S, tau float64) AnalyticalDistribution
 	GetProbabilityTolerance() float64
 }
+
+// VolatilitySurface returns the Black-Scholes implied volatility for strike K and time to maturity T (in years)
+type VolatilitySurface interface {
+	Vol(K, T float64) float64
+}
@@ -0,0 +1,95 @@
+package misc
+
+import (
+	"errors"
+	"math"
+)
+
+// the errors are allocated once so that failing to find a root doesn't allocate, e.g. in batch implied volatilities
+var (
+	// errBrentNotBracketed is returned by FindRootBrent when f has the same sign at both ends of the interval
+	errBrentNotBracketed = errors.New("Brent's method failed - root not bracketed")
+	// errBrentNoConvergence is returned by FindRootBrent when the number of iterations exceeds maxIter
+	errBrentNoConvergence = errors.New("Brent's method did not converge")
+)
+
+// FindRootBrent returns an approximate solution s to f(x)=0 in the interval [a,b] using Brent's method
+// (a combination of bisection, secant and inverse quadratic interpolation) such that the
+// bracketing interval has shrunk below tol (relative to s as it gets close to machine precision).
+// Results in error if f(a) and f(b) have the same sign or number of iterations exceeds maxIter.
+func FindRootBrent(f func(float64) float64, a, b float64, maxIter int, tol float64) (float64, error) {
+	fa := f(a)
+	fb := f(b)
+	if fa == 0 {
+		return a, nil
+	}
+	if fb == 0 {
+		return b, nil
+	}
+	if math.IsNaN(fa) || math.IsNaN(fb) || (fa > 0) == (fb > 0) {
+		return math.NaN(), errBrentNotBracketed
+	}
+
+	c, fc := a, fa
+	d := b - a
+	e := d
+	for i := 0; i < maxIter; i++ {
+		if (fb > 0) == (fc > 0) {
+			// keep the root between b and c
+			c, fc = a, fa
+			d = b - a
+			e = d
+		}
+		if math.Abs(fc) < math.Abs(fb) {
+			a, b, c = b, c, b
+			fa, fb, fc = fb, fc, fb
+		}
+
+		tol1 := 2*1e-16*math.Abs(b) + 0.5*tol
+		xm := 0.5 * (c - b)
+		if math.Abs(xm) <= tol1 || fb == 0 {
+			return b, nil
+		}
+
+		if math.Abs(e) >= tol1 && math.Abs(fa) > math.Abs(fb) {
+			// attempt interpolation
+			var p, q float64
+			s := fb / fa
+			if a == c {
+				p = 2 * xm * s
+				q = 1 - s
+			} else {
+				q = fa / fc
+				r := fb / fc
+				p = s * (2*xm*q*(q-r) - (b-a)*(r-1))
+				q = (q - 1) * (r - 1) * (s - 1)
+			}
+			if p > 0 {
+				q = -q
+			}
+			p = math.Abs(p)
+			if 2*p < math.Min(3*xm*q-math.Abs(tol1*q), math.Abs(e*q)) {
+				e = d
+				d = p / q
+			} else {
+				// interpolation failed, use bisection
+				d = xm
+				e = d
+			}
+		} else {
+			// bounds decreasing too slowly, use bisection
+			d = xm
+			e = d
+		}
+
+		a, fa = b, fb
+		if math.Abs(d) > tol1 {
+			b += d
+		} else {
+			b += math.Copysign(tol1, xm)
+		}
+		fb = f(b)
+	}
+
+	return math.NaN(), errBrentNoConvergence
+}
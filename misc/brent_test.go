@@ -0,0 +1,65 @@
+package misc
+
+import (
+	"math"
+	"testing"
+)
+
+// TestBrentViaSqrt we check whether Brent converges to sqrt(2)
+// by asking it to solve the nonlinear equation x^2 - 2 = 0;
+func TestBrentViaSqrt(t *testing.T) {
+	const tolerance float64 = 1e-12
+
+	f := func(x float64) float64 { return x*x - 2.0 }
+	x, err := FindRootBrent(f, 0, 2, 100, 1e-14)
+	if err != nil {
+		t.Errorf("Brent solver failed\n")
+	}
+
+	error := math.Abs(math.Sqrt(2.0) - x)
+	if math.IsNaN(error) || math.IsInf(error, 0) || error > tolerance {
+		t.Errorf("Brent solver failed, error=%g\n", error)
+	}
+}
+
+// TestBrentWhereNewtonFails uses the cubic on which Newton's method cycles
+func TestBrentWhereNewtonFails(t *testing.T) {
+	const tolerance float64 = 1e-12
+
+	f := func(x float64) float64 { return x*x*x - 2*x + 2 }
+	x, err := FindRootBrent(f, -3, 1, 100, 1e-14)
+	if err != nil {
+		t.Errorf("Brent solver failed\n")
+	}
+	if math.IsNaN(x) || math.Abs(f(x)) > tolerance {
+		t.Errorf("Brent solver failed, f(%g)=%g\n", x, f(x))
+	}
+}
+
+// TestBrentStepFunction checks that Brent ends up bisecting when interpolation doesn't help
+func TestBrentStepFunction(t *testing.T) {
+	const tolerance float64 = 1e-10
+
+	f := func(x float64) float64 {
+		if x < 0.3 {
+			return -1
+		}
+		return 1
+	}
+	x, err := FindRootBrent(f, 0, 1, 200, 1e-12)
+	if err != nil {
+		t.Errorf("Brent solver failed\n")
+	}
+	if math.IsNaN(x) || math.Abs(x-0.3) > tolerance {
+		t.Errorf("Brent solver failed, x=%g\n", x)
+	}
+}
+
+func TestBrentNotBracketedFail(t *testing.T) {
+	f := func(x float64) float64 { return x*x + 1.0 }
+	x, err := FindRootBrent(f, -1, 1, 100, 1e-12)
+	if err == nil {
+		t.Errorf("Brent solver shouldn't have worked in this case.\n")
+		t.Logf("Returned x=%g", x)
+	}
+}
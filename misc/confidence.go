@@ -0,0 +1,13 @@
+package misc
+
+// ConfidenceInterval is the interval from Lower to Upper which covers the estimated quantity with the stated probability
+type ConfidenceInterval struct {
+	Lower      float64
+	Upper      float64
+	Confidence float64
+}
+
+// Contains reports whether x lies within the interval
+func (ci ConfidenceInterval) Contains(x float64) bool {
+	return ci.Lower <= x && x <= ci.Upper
+}
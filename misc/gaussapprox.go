@@ -20,3 +20,9 @@ func ApproxGaussCdf(x float64) float64 {
 	}
 	return 1.0 - ApproxGaussCdf(-x)
 }
+
+// GaussCdf returns the distribution of N(0,1) computed from the complementary error function,
+// it is accurate to double precision (including the tails) but slower than ApproxGaussCdf
+func GaussCdf(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
@@ -53,3 +53,44 @@ func TestCDFApproxViaGonum(t *testing.T) {
 		}
 	}
 }
+
+func TestCDFViaGonum(t *testing.T) {
+	const tolerance float64 = 1e-15
+	const numPoints int = 10000
+	const minX float64 = -38.0
+	const maxX float64 = 9.0
+	h := (maxX - minX) / float64(numPoints)
+	for i := 0; i < numPoints; i++ {
+		x := minX + float64(i)*h
+		cdf := GaussCdf(x)
+		cdfGonum := distuv.UnitNormal.CDF(x)
+		// relative error so that the lower tail is checked too
+		error := math.Abs(cdf-cdfGonum) / math.Max(cdfGonum, 1e-300)
+		if math.IsNaN(error) || math.IsInf(error, 0) || error > tolerance {
+			t.Errorf("Gaussian CDF at x=%g is too far from Gonum value.\n", x)
+		}
+	}
+}
+
+func TestCDFAgainstPrecomputed(t *testing.T) {
+	const tolerance float64 = 1e-13 // relative
+	// values to 16 significant digits
+	tables := []struct {
+		x   float64
+		cdf float64
+	}{
+		{-20, 2.753624118606234e-89},
+		{-10, 7.619853024160527e-24},
+		{-5, 2.866515718791939e-07},
+		{-1, 1.586552539314571e-01},
+		{0, 0.5},
+		{1, 8.413447460685429e-01},
+		{3, 9.986501019683699e-01},
+	}
+	for _, table := range tables {
+		error := math.Abs(GaussCdf(table.x)-table.cdf) / table.cdf
+		if math.IsNaN(error) || math.IsInf(error, 0) || error > tolerance {
+			t.Errorf("Gaussian CDF at x=%g, relative error=%g is greater than tolerance.\n", table.x, error)
+		}
+	}
+}
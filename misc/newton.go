@@ -5,6 +5,14 @@ import "errors"
 
 const h float64 = 1e-6
 
+// the errors are allocated once so that failing to find a root doesn't allocate, e.g. in batch implied volatilities
+var (
+	// errNewtonDerivativeTooSmall is returned by FindRoot when the derivative vanishes
+	errNewtonDerivativeTooSmall = errors.New("NewtonsMethod failed - derivative too small")
+	// errNewtonNoConvergence is returned by FindRoot when the number of iterations exceeds maxIter
+	errNewtonNoConvergence = errors.New("NewtonsMethod did not converge")
+)
+
 //FindRootWithoutDerivative returns an approximate solution s to f(x)=0 using Newtons method starting at x0 and such that |f(x)| < maxError
 //where f is a function R->R
 //Results in error if derivative is too small or number of iterations exceeds maxIter.
@@ -31,11 +39,11 @@ func FindRoot(f, fPrime func(float64) float64,
 			return xn, nil
 		}
 		if math.Abs(fPrime(xn)) < 1e-16 {
-			return math.NaN(), errors.New("NewtonsMethod failed - derivative too small")
+			return math.NaN(), errNewtonDerivativeTooSmall
 		}
 		xn = xn - f(xn)/fPrime(xn)
 		i++
 	}
 
-	return math.NaN(), errors.New("NewtonsMethod did not converge")
+	return math.NaN(), errNewtonNoConvergence
 }
@@ -0,0 +1,77 @@
+package misc
+
+import (
+	"errors"
+	"math"
+)
+
+// Errors returned by the validated variants of the pricing and risk functions across the packages
+var (
+	// ErrInvalidPrice is returned when the price of the risky asset isn't positive and finite
+	ErrInvalidPrice = errors.New("price must be positive and finite")
+	// ErrInvalidStrike is returned when the strike of an option isn't positive and finite
+	ErrInvalidStrike = errors.New("strike must be positive and finite")
+	// ErrInvalidSigma is returned when the volatility (or scale) isn't positive and finite, or non-negative where zero is supported
+	ErrInvalidSigma = errors.New("volatility must be positive and finite, or non-negative where zero is supported")
+	// ErrInvalidHorizon is returned when the risk horizon isn't positive and finite
+	ErrInvalidHorizon = errors.New("time horizon must be positive and finite")
+	// ErrInvalidMaturity is returned when the time to maturity of an option isn't positive and finite, or non-negative where expiry is supported
+	ErrInvalidMaturity = errors.New("time to maturity must be positive and finite, or non-negative where expiry is supported")
+	// ErrInvalidDiscountFactor is returned when a discount factor isn't positive and finite
+	ErrInvalidDiscountFactor = errors.New("discount factor must be positive and finite")
+	// ErrInvalidRate is returned when a growth rate, interest rate, yield or location parameter isn't finite
+	ErrInvalidRate = errors.New("rates, yields and location parameters must be finite")
+	// ErrProbabilityOutOfRange is returned when a probability level isn't strictly between 0 and 1
+	ErrProbabilityOutOfRange = errors.New("probability must be strictly between 0 and 1")
+	// ErrInvalidDegreesOfFreedom is returned when the degrees of freedom of a Student-t distribution don't exceed 1
+	// so that its expected shortfall is infinite
+	ErrInvalidDegreesOfFreedom = errors.New("degrees of freedom must be greater than 1")
+	// ErrEmptySample is returned when a risk measure is requested for no samples
+	ErrEmptySample = errors.New("sample must not be empty")
+	// ErrInvalidSample is returned when a sample is NaN
+	ErrInvalidSample = errors.New("sample must not contain NaN")
+	// ErrNotFinite is returned when the inputs are valid but the result overflows
+	ErrNotFinite = errors.New("result is not finite")
+)
+
+// ValidatePositive returns err unless x is positive and finite
+func ValidatePositive(x float64, err error) error {
+	if !(x > 0) || math.IsInf(x, 1) {
+		return err
+	}
+	return nil
+}
+
+// ValidateNonNegative returns err unless x is non-negative and finite
+func ValidateNonNegative(x float64, err error) error {
+	if !(x >= 0) || math.IsInf(x, 1) {
+		return err
+	}
+	return nil
+}
+
+// ValidateFinite returns err unless x is finite
+func ValidateFinite(x float64, err error) error {
+	if math.IsNaN(x) || math.IsInf(x, 0) {
+		return err
+	}
+	return nil
+}
+
+// ValidateProbability returns ErrProbabilityOutOfRange unless 0 < p < 1
+func ValidateProbability(p float64) error {
+	if !(p > 0) || !(p < 1) {
+		return ErrProbabilityOutOfRange
+	}
+	return nil
+}
+
+// FirstError returns the first non-nil error, it is used to chain the validation of several inputs
+func FirstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,7 +1,17 @@
 package pricedistribution
 
 import (
+	"errors"
+
 	"code.vegaprotocol.io/quant/interfaces"
+	"code.vegaprotocol.io/quant/misc"
+)
+
+var (
+	// ErrInvalidBins is returned when there are fewer than 2 bins or they aren't increasing
+	ErrInvalidBins = errors.New("at least 2 increasing bins are needed")
+	// ErrInvalidPriceRange is returned when the minimum price isn't below the maximum price
+	ErrInvalidPriceRange = errors.New("minimum price must be below maximum price")
 )
 
 // PriceRange returns the minimum and maximum price implied by the supplied distribution and probability level
@@ -51,3 +61,38 @@ func ProbabilityOfTrading(d interfaces.AnalyticalDistribution, price float64, is
 	}
 	return (max - d.CDF(price)) / z
 }
+
+// PriceRangeChecked is PriceRange returning misc.ErrProbabilityOutOfRange unless 0 < alpha < 1
+func PriceRangeChecked(d interfaces.AnalyticalDistribution, alpha float64) (minPrice float64, maxPrice float64, err error) {
+	if err = misc.ValidateProbability(alpha); err != nil {
+		return
+	}
+	minPrice, maxPrice = PriceRange(d, alpha)
+	return
+}
+
+// PriceDistributionChecked is PriceDistribution returning ErrInvalidBins unless there are at least 2 bins
+// and they are finite and increasing
+func PriceDistributionChecked(d interfaces.AnalyticalDistribution, bins []float64) (probabilities []float64, err error) {
+	if len(bins) < 2 {
+		return nil, ErrInvalidBins
+	}
+	for i, bin := range bins {
+		if misc.ValidateFinite(bin, ErrInvalidBins) != nil || (i > 0 && !(bin > bins[i-1])) {
+			return nil, ErrInvalidBins
+		}
+	}
+	return PriceDistribution(d, bins), nil
+}
+
+// ProbabilityOfTradingChecked is ProbabilityOfTrading returning misc.ErrInvalidPrice unless the price is finite and,
+// if applyMinMax is set, ErrInvalidPriceRange unless minPrice < maxPrice
+func ProbabilityOfTradingChecked(d interfaces.AnalyticalDistribution, price float64, isBid bool, applyMinMax bool, minPrice float64, maxPrice float64) (float64, error) {
+	if err := misc.ValidateFinite(price, misc.ErrInvalidPrice); err != nil {
+		return 0, err
+	}
+	if applyMinMax && !(minPrice < maxPrice) {
+		return 0, ErrInvalidPriceRange
+	}
+	return ProbabilityOfTrading(d, price, isBid, applyMinMax, minPrice, maxPrice), nil
+}
@@ -4,6 +4,7 @@ import (
 	"math"
 	"testing"
 
+	"code.vegaprotocol.io/quant/misc"
 	"code.vegaprotocol.io/quant/riskmodelbs"
 	"gonum.org/v1/gonum/stat/distuv"
 )
@@ -135,6 +136,42 @@ func TestProbabilityOfTradingNormalisation(t *testing.T) {
 
 }
 
+func TestCheckedVariants(t *testing.T) {
+	uniform := distuv.Uniform{Min: 100, Max: 200}
+
+	for _, alpha := range []float64{0, 1, -0.5, math.NaN()} {
+		if _, _, err := PriceRangeChecked(uniform, alpha); err != misc.ErrProbabilityOutOfRange {
+			t.Errorf("alpha=%g: expected error %v, got %v\n", alpha, misc.ErrProbabilityOutOfRange, err)
+		}
+	}
+	min, max, err := PriceRangeChecked(uniform, 0.9)
+	expectedMin, expectedMax := PriceRange(uniform, 0.9)
+	if err != nil || min != expectedMin || max != expectedMax {
+		t.Errorf("expected [%g, %g], got [%g, %g] and error %v\n", expectedMin, expectedMax, min, max, err)
+	}
+
+	for _, bins := range [][]float64{nil, {150}, {150, 120}, {110, 110}, {110, math.NaN()}, {math.Inf(-1), 110}, {110, math.Inf(1)}} {
+		if _, err := PriceDistributionChecked(uniform, bins); err != ErrInvalidBins {
+			t.Errorf("bins=%v: expected error %v, got %v\n", bins, ErrInvalidBins, err)
+		}
+	}
+	if probabilities, err := PriceDistributionChecked(uniform, []float64{100, 150, 200}); err != nil || len(probabilities) != 2 {
+		t.Errorf("expected 2 probabilities, got %v and error %v\n", probabilities, err)
+	}
+
+	for _, price := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		if _, err := ProbabilityOfTradingChecked(uniform, price, true, false, 0, 0); err != misc.ErrInvalidPrice {
+			t.Errorf("price=%g: expected error %v, got %v\n", price, misc.ErrInvalidPrice, err)
+		}
+	}
+	if _, err := ProbabilityOfTradingChecked(uniform, 150, true, true, 180, 120); err != ErrInvalidPriceRange {
+		t.Errorf("expected error %v, got %v\n", ErrInvalidPriceRange, err)
+	}
+	if p, err := ProbabilityOfTradingChecked(uniform, 150, true, true, 120, 180); err != nil || p != ProbabilityOfTrading(uniform, 150, true, true, 120, 180) {
+		t.Errorf("expected probability %g, got %g and error %v\n", ProbabilityOfTrading(uniform, 150, true, true, 120, 180), p, err)
+	}
+}
+
 func assert(t *testing.T, label string, expected, actual, tolerance float64) {
 	if math.Abs(expected-actual) > tolerance {
 		t.Logf("expected %s=%g\n", label, expected)
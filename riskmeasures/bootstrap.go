@@ -0,0 +1,158 @@
+package riskmeasures
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"code.vegaprotocol.io/quant/misc"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat"
+)
+
+var (
+	// ErrInvalidReplications is returned when the number of bootstrap replications isn't positive
+	ErrInvalidReplications = errors.New("number of bootstrap replications must be positive")
+	// ErrInvalidBlockLength is returned when the block length is below 1 or above the number of samples
+	ErrInvalidBlockLength = errors.New("block length must be between 1 and the number of samples")
+	// ErrInvalidBootstrapMethod is returned when the method isn't one of IIDBootstrap, BlockBootstrap and StationaryBootstrap
+	ErrInvalidBootstrapMethod = errors.New("unknown bootstrap method")
+)
+
+// BootstrapMethod selects how the samples are resampled
+type BootstrapMethod int
+
+const (
+	// IIDBootstrap draws the samples independently with replacement, for independent samples
+	IIDBootstrap BootstrapMethod = iota
+	// BlockBootstrap draws blocks of consecutive samples of fixed length with replacement, wrapping around the end,
+	// so that the dependence between nearby samples is kept (the circular block bootstrap of Politis and Romano, 1992)
+	BlockBootstrap
+	// StationaryBootstrap draws blocks of consecutive samples with geometric lengths, wrapping around the end,
+	// which unlike fixed blocks makes the resampled series stationary (Politis and Romano, The stationary
+	// bootstrap, Journal of the American Statistical Association, 1994)
+	StationaryBootstrap
+)
+
+// Bootstrap sets how the sampling distribution of an estimator is approximated: the number of Replications,
+// the Method and for the block methods the (mean) BlockLength, which defaults to the cube root of the number of
+// samples when 0. Src is the source of randomness, the global one when nil, set it for reproducible results.
+type Bootstrap struct {
+	Method       BootstrapMethod
+	Replications int
+	BlockLength  float64
+	Confidence   float64
+	Src          rand.Source
+}
+
+// BootstrapResult is an Estimate from the samples together with its bootstrap standard error and percentile
+// confidence interval
+type BootstrapResult struct {
+	Estimate float64
+	StdErr   float64
+	Interval misc.ConfidenceInterval
+}
+
+// BootstrapEmpiricalVaR returns EmpiricalVaR of x with its bootstrap standard error and confidence interval,
+// x is ordered in time for the block methods and isn't modified
+func BootstrapEmpiricalVaR(x []float64, alpha float64, b Bootstrap) (BootstrapResult, error) {
+	if err := validateSample(x, alpha); err != nil {
+		return BootstrapResult{}, err
+	}
+	return b.Estimate(x, func(y []float64) float64 { return EmpiricalVaR(y, alpha, false) })
+}
+
+// BootstrapEmpiricalEs returns EmpiricalEs of x with its bootstrap standard error and confidence interval,
+// x is ordered in time for the block methods and isn't modified
+func BootstrapEmpiricalEs(x []float64, lambda float64, b Bootstrap) (BootstrapResult, error) {
+	if err := validateSample(x, lambda); err != nil {
+		return BootstrapResult{}, err
+	}
+	return b.Estimate(x, func(y []float64) float64 { return EmpiricalEs(y, lambda, false) })
+}
+
+// Estimate applies the estimator to a copy of x and to the bootstrap resamples of x, it returns the standard
+// deviation of the resampled estimates and the interval between their (1 - Confidence) / 2 and (1 + Confidence) / 2
+// quantiles. The estimator may modify the slice it is given.
+func (b Bootstrap) Estimate(x []float64, estimator func([]float64) float64) (BootstrapResult, error) {
+	n := len(x)
+	if n == 0 {
+		return BootstrapResult{}, misc.ErrEmptySample
+	}
+	if b.Method != IIDBootstrap && b.Method != BlockBootstrap && b.Method != StationaryBootstrap {
+		return BootstrapResult{}, ErrInvalidBootstrapMethod
+	}
+	if b.Replications < 1 {
+		return BootstrapResult{}, ErrInvalidReplications
+	}
+	if err := misc.ValidateProbability(b.Confidence); err != nil {
+		return BootstrapResult{}, err
+	}
+	blockLength := b.BlockLength
+	if blockLength == 0 {
+		blockLength = math.Cbrt(float64(n))
+	}
+	if b.Method != IIDBootstrap && (!(blockLength >= 1) || blockLength > float64(n)) {
+		return BootstrapResult{}, ErrInvalidBlockLength
+	}
+
+	sample := make([]float64, n)
+	copy(sample, x)
+	result := BootstrapResult{Estimate: estimator(sample)}
+
+	rng := rand.New(b.Src)
+	if b.Src == nil {
+		// the global source, as rand.New needs one
+		rng = rand.New(globalSource{})
+	}
+	estimates := make([]float64, b.Replications)
+	for r := range estimates {
+		b.resample(x, sample, blockLength, rng)
+		estimates[r] = estimator(sample)
+	}
+
+	result.StdErr = stat.StdDev(estimates, nil)
+	sort.Float64s(estimates)
+	result.Interval = misc.ConfidenceInterval{
+		Lower:      stat.Quantile(0.5*(1-b.Confidence), stat.Empirical, estimates, nil),
+		Upper:      stat.Quantile(0.5*(1+b.Confidence), stat.Empirical, estimates, nil),
+		Confidence: b.Confidence,
+	}
+	return result, nil
+}
+
+// resample fills sample with a bootstrap resample of x
+func (b Bootstrap) resample(x, sample []float64, blockLength float64, rng *rand.Rand) {
+	n := len(x)
+	switch b.Method {
+	case BlockBootstrap:
+		length := int(math.Round(blockLength))
+		for i := 0; i < n; i += length {
+			start := rng.Intn(n)
+			for j := 0; j < length && i+j < n; j++ {
+				sample[i+j] = x[(start+j)%n]
+			}
+		}
+	case StationaryBootstrap:
+		// each sample continues the current block with probability 1 - 1 / blockLength
+		position := rng.Intn(n)
+		for i := range sample {
+			if i > 0 && rng.Float64() < 1/blockLength {
+				position = rng.Intn(n)
+			}
+			sample[i] = x[position]
+			position = (position + 1) % n
+		}
+	case IIDBootstrap:
+		for i := range sample {
+			sample[i] = x[rng.Intn(n)]
+		}
+	}
+}
+
+// globalSource draws from the global source of golang.org/x/exp/rand
+type globalSource struct{}
+
+func (globalSource) Uint64() uint64   { return rand.Uint64() }
+func (globalSource) Seed(seed uint64) { rand.Seed(seed) }
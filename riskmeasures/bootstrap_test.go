@@ -0,0 +1,158 @@
+package riskmeasures
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/misc"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ar1 returns n samples of the AR(1) process with coefficient phi and unit stationary variance
+func ar1(n int, phi float64, rng *rand.Rand) []float64 {
+	x := make([]float64, n)
+	x[0] = rng.NormFloat64()
+	for i := 1; i < n; i++ {
+		x[i] = phi*x[i-1] + math.Sqrt(1-phi*phi)*rng.NormFloat64()
+	}
+	return x
+}
+
+func TestBootstrapIsReproducible(t *testing.T) {
+	x := ar1(1000, 0.5, rand.New(rand.NewSource(1)))
+	for _, method := range []BootstrapMethod{IIDBootstrap, BlockBootstrap, StationaryBootstrap} {
+		b := Bootstrap{Method: method, Replications: 200, Confidence: 0.95, Src: rand.NewSource(7)}
+		first, err := BootstrapEmpiricalEs(x, 0.05, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Src = rand.NewSource(7)
+		second, _ := BootstrapEmpiricalEs(x, 0.05, b)
+		b.Src = rand.NewSource(8)
+		other, _ := BootstrapEmpiricalEs(x, 0.05, b)
+		if first != second || first.Interval == other.Interval {
+			t.Errorf("method=%d: same seed gave %+v and %+v, other seed %+v\n", method, first, second, other)
+		}
+		if first.Estimate != EmpiricalEs(append([]float64{}, x...), 0.05, false) {
+			t.Errorf("method=%d: estimate=%g isn't EmpiricalEs\n", method, first.Estimate)
+		}
+	}
+}
+
+// TestBootstrapStdErrOfVaR compares the bootstrap standard error of the empirical quantile of normal samples
+// with its asymptotic value sqrt(alpha (1 - alpha) / n) / f(q) for the density f at the quantile q,
+// far in the tail it is too noisy for a tight comparison
+func TestBootstrapStdErrOfVaR(t *testing.T) {
+	const tolerance float64 = 0.15 // relative
+	const n int = 5000
+	rng := rand.New(rand.NewSource(1))
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = rng.NormFloat64()
+	}
+	for _, alpha := range []float64{0.05, 0.1} {
+		result, err := BootstrapEmpiricalVaR(x, alpha, Bootstrap{Replications: 1000, Confidence: 0.9, Src: rand.NewSource(1)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		q := distuv.UnitNormal.Quantile(alpha)
+		exact := math.Sqrt(alpha*(1-alpha)/float64(n)) / distuv.UnitNormal.Prob(q)
+		if error := math.Abs(result.StdErr/exact - 1); math.IsNaN(error) || error > tolerance {
+			t.Errorf("alpha=%g: bootstrap standard error=%g, asymptotic=%g\n", alpha, result.StdErr, exact)
+		}
+	}
+}
+
+// TestBootstrapCoverage checks the confidence intervals cover the exact ES of normal samples about as often as
+// their confidence level
+func TestBootstrapCoverage(t *testing.T) {
+	const numRuns, n int = 100, 1000
+	const lambda, confidence, tolerance float64 = 0.05, 0.9, 0.1
+	exact := NormalEs(0, 1, lambda)
+	rng := rand.New(rand.NewSource(1))
+	var covered int
+	for run := 0; run < numRuns; run++ {
+		x := make([]float64, n)
+		for i := range x {
+			x[i] = rng.NormFloat64()
+		}
+		result, err := BootstrapEmpiricalEs(x, lambda, Bootstrap{Replications: 200, Confidence: confidence, Src: rng})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Interval.Contains(exact) {
+			covered++
+		}
+	}
+	if error := math.Abs(float64(covered)/float64(numRuns) - confidence); error > tolerance {
+		t.Errorf("coverage %d out of %d runs\n", covered, numRuns)
+	}
+}
+
+// TestBlockBootstrapOfDependentSamples checks that the block methods capture the larger standard error of the ES
+// of autocorrelated samples, which resampling independently underestimates. The bootstrap standard errors are
+// averaged over a few series as those of a single series vary a lot.
+func TestBlockBootstrapOfDependentSamples(t *testing.T) {
+	const tolerance float64 = 0.2 // relative
+	const numRuns, numSeries, n int = 200, 5, 4000
+	const phi, lambda float64 = 0.8, 0.05
+
+	rng := rand.New(rand.NewSource(1))
+	estimates := make([]float64, numRuns)
+	for i := range estimates {
+		estimates[i] = EmpiricalEs(ar1(n, phi, rng), lambda, false)
+	}
+	exact := stat.StdDev(estimates, nil)
+
+	var stdErrs [3]float64
+	for s := 0; s < numSeries; s++ {
+		x := ar1(n, phi, rng)
+		for _, method := range []BootstrapMethod{IIDBootstrap, BlockBootstrap, StationaryBootstrap} {
+			b := Bootstrap{Method: method, Replications: 300, BlockLength: 50, Confidence: 0.9, Src: rand.NewSource(1)}
+			result, err := BootstrapEmpiricalEs(x, lambda, b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			stdErrs[method] += result.StdErr / float64(numSeries)
+		}
+	}
+	if !(stdErrs[IIDBootstrap] < 0.7*exact) {
+		t.Errorf("iid bootstrap standard error=%g doesn't underestimate %g\n", stdErrs[IIDBootstrap], exact)
+	}
+	for _, method := range []BootstrapMethod{BlockBootstrap, StationaryBootstrap} {
+		if error := math.Abs(stdErrs[method]/exact - 1); math.IsNaN(error) || error > tolerance {
+			t.Errorf("method=%d: bootstrap standard error=%g, Monte Carlo=%g\n", method, stdErrs[method], exact)
+		}
+	}
+}
+
+func TestBootstrapErrors(t *testing.T) {
+	x := []float64{-1, 0.5, 2, -0.3, 1.2}
+	tables := []struct {
+		x     []float64
+		alpha float64
+		b     Bootstrap
+		err   error
+	}{
+		{nil, 0.1, Bootstrap{Replications: 10, Confidence: 0.9}, misc.ErrEmptySample},
+		{x, 1, Bootstrap{Replications: 10, Confidence: 0.9}, misc.ErrProbabilityOutOfRange},
+		{x, 0.1, Bootstrap{Replications: 0, Confidence: 0.9}, ErrInvalidReplications},
+		{x, 0.1, Bootstrap{Replications: 10, Confidence: 0}, misc.ErrProbabilityOutOfRange},
+		{x, 0.1, Bootstrap{Method: BlockBootstrap, Replications: 10, BlockLength: 0.5, Confidence: 0.9}, ErrInvalidBlockLength},
+		{x, 0.1, Bootstrap{Method: StationaryBootstrap, Replications: 10, BlockLength: 6, Confidence: 0.9}, ErrInvalidBlockLength},
+		{x, 0.1, Bootstrap{Method: StationaryBootstrap + 1, Replications: 10, Confidence: 0.9}, ErrInvalidBootstrapMethod},
+		{x, 0.1, Bootstrap{Method: -1, Replications: 10, Confidence: 0.9}, ErrInvalidBootstrapMethod},
+	}
+	for i, table := range tables {
+		if _, err := BootstrapEmpiricalVaR(table.x, table.alpha, table.b); err != table.err {
+			t.Errorf("case %d: expected %v, got %v\n", i, table.err, err)
+		}
+	}
+	// the global source is used without Src
+	if _, err := BootstrapEmpiricalEs(x, 0.1, Bootstrap{Replications: 10, Confidence: 0.9}); err != nil {
+		t.Errorf("unexpected error %v\n", err)
+	}
+}
@@ -0,0 +1,133 @@
+package riskmeasures
+
+import (
+	"math"
+	"sort"
+
+	"code.vegaprotocol.io/quant/interfaces"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Distortion is an increasing concave distortion function G of [0, 1] onto itself and its inverse. The distortion
+// risk measure of a r.v. X with quantile function Q is -integral of Q(u) dG(u) over [0, 1], i.e. the spectral risk
+// measure with the non-increasing weight function (spectrum) G', which weights the worst outcomes the most.
+// Concavity makes the risk measure coherent.
+type Distortion struct {
+	G       func(u float64) float64
+	Inverse func(v float64) float64
+}
+
+// ExpectedShortfallDistortion returns G(u) = min(u / lambda, 1) whose risk measure is the expected shortfall at level lambda
+func ExpectedShortfallDistortion(lambda float64) Distortion {
+	return Distortion{
+		G:       func(u float64) float64 { return math.Min(u/lambda, 1) },
+		Inverse: func(v float64) float64 { return lambda * v },
+	}
+}
+
+// ExponentialDistortion returns the exponential spectrum k exp(-k u) / (1 - exp(-k)) with the absolute risk
+// aversion k > 0, i.e. G(u) = (1 - exp(-k u)) / (1 - exp(-k)), the larger k the more weight on the worst outcomes
+func ExponentialDistortion(k float64) Distortion {
+	norm := -math.Expm1(-k)
+	return Distortion{
+		G:       func(u float64) float64 { return -math.Expm1(-k*u) / norm },
+		Inverse: func(v float64) float64 { return -math.Log1p(-v*norm) / k },
+	}
+}
+
+// WangDistortion returns Wang's transform G(u) = N(N^-1(u) + kappa) for kappa >= 0 where N is the standard normal
+// CDF, for a normal r.v. with mean mu and standard deviation sigma the risk measure is kappa sigma - mu
+func WangDistortion(kappa float64) Distortion {
+	return Distortion{
+		G:       func(u float64) float64 { return distuv.UnitNormal.CDF(distuv.UnitNormal.Quantile(u) + kappa) },
+		Inverse: func(v float64) float64 { return distuv.UnitNormal.CDF(distuv.UnitNormal.Quantile(v) - kappa) },
+	}
+}
+
+// PowerDistortion returns G(u) = u^gamma for 0 < gamma <= 1, the smaller gamma the more weight on the worst outcomes
+func PowerDistortion(gamma float64) Distortion {
+	return Distortion{
+		G:       func(u float64) float64 { return math.Pow(u, gamma) },
+		Inverse: func(v float64) float64 { return math.Pow(v, 1/gamma) },
+	}
+}
+
+// EmpiricalDistortionRisk calculates the distortion risk measure of samples x, i.e. minus the sum of the i-th smallest
+// sample times G(i / n) - G((i - 1) / n). With ExpectedShortfallDistortion it is EmpiricalEs.
+func EmpiricalDistortionRisk(x []float64, d Distortion, isSorted bool) float64 {
+	if !isSorted {
+		sort.Float64s(x)
+	}
+	n := float64(len(x))
+	var risk, previous float64
+	for i, xi := range x {
+		g := d.G(float64(i+1) / n)
+		risk -= xi * (g - previous)
+		previous = g
+	}
+	return risk
+}
+
+// DistributionDistortionRisk returns the distortion risk measure of a r.v. with the supplied distribution,
+// minus the integral of Q(G^-1(v)) over [0, 1] for the quantile function Q, along with an estimate of the integration
+// error. With ExpectedShortfallDistortion it is DistributionEs. The risk measure may be infinite for distortions
+// which weight the worst outcomes heavily, e.g. PowerDistortion needs a finite moment of order 1 / gamma,
+// and it is +Inf when the quantile is infinite within the integration range.
+// Where G^-1 underflows, or 1 - G^-1 rounds to 1, e.g. for PowerDistortion with a small gamma, the quantile can't be
+// evaluated at the probability it's needed at, the contribution of those nodes is included in the error estimate.
+func DistributionDistortionRisk(dist interfaces.AnalyticalDistribution, d Distortion) (risk, errEstimate float64) {
+	risk, coarse, unresolved := integrateDistortion(func(v float64) (float64, bool) {
+		return representableQuantile(dist, d.Inverse(v))
+	})
+	return -risk, integrationError(risk, coarse, unresolved)
+}
+
+// NegativeDistributionDistortionRisk returns the distortion risk measure of minus a r.v. with the supplied distribution,
+// the integral of Q(1 - G^-1(v)) over [0, 1], along with an estimate of the integration error,
+// see DistributionDistortionRisk. With ExpectedShortfallDistortion it is NegativeDistributionEs.
+func NegativeDistributionDistortionRisk(dist interfaces.AnalyticalDistribution, d Distortion) (risk, errEstimate float64) {
+	risk, coarse, unresolved := integrateDistortion(func(v float64) (float64, bool) {
+		return representableQuantile(dist, 1.0-d.Inverse(v))
+	})
+	return risk, integrationError(risk, coarse, unresolved)
+}
+
+const (
+	// minProbability and maxProbability are the smallest positive normal float and the largest float below 1
+	minProbability = 2.2250738585072014e-308
+	maxProbability = 1 - 1.0/(1<<53)
+)
+
+// representableQuantile returns the quantile at u, with the probabilities which underflow or round to 1 moved to
+// the nearest ones in (0, 1) where the quantile of a distribution with finite tails is finite, and whether u was moved.
+// An infinite quantile is returned as is so that the risk measure is infinite.
+func representableQuantile(dist interfaces.AnalyticalDistribution, u float64) (float64, bool) {
+	clamped := math.Min(math.Max(u, minProbability), maxProbability)
+	return dist.Quantile(clamped), clamped != u
+}
+
+// integrationError returns the difference between the fine and coarse integrals plus the unresolved contributions,
+// or 0 if the integral is infinite
+func integrationError(fine, coarse, unresolved float64) float64 {
+	if math.IsInf(fine, 0) {
+		return 0
+	}
+	return math.Abs(fine-coarse) + unresolved
+}
+
+// integrateDistortion returns the integral of q(v) over [0, 1] on the fine and coarse Gauss-Legendre nodes,
+// after the substitutions v = s^4 / 2 on [0, 1/2] and 1 - v = s^4 / 2 on [1/2, 1] which smooth out the integrable
+// singularities of the quantile function at both ends, and the absolute contribution of the fine nodes where q
+// isn't resolved
+func integrateDistortion(q func(v float64) (float64, bool)) (fine, coarse, unresolved float64) {
+	lower, lowerCoarse, lowerUnresolved := integrateTail(func(s float64) (float64, bool) {
+		value, isUnresolved := q(0.5 * s * s * s * s)
+		return 0.5 * value, isUnresolved
+	})
+	upper, upperCoarse, upperUnresolved := integrateTail(func(s float64) (float64, bool) {
+		value, isUnresolved := q(1 - 0.5*s*s*s*s)
+		return 0.5 * value, isUnresolved
+	})
+	return lower + upper, lowerCoarse + upperCoarse, lowerUnresolved + upperUnresolved
+}
@@ -0,0 +1,136 @@
+package riskmeasures
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/interfaces"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func TestDistortionInverses(t *testing.T) {
+	const tolerance float64 = 1e-12
+	distortions := map[string]Distortion{
+		"ES":          ExpectedShortfallDistortion(0.025),
+		"exponential": ExponentialDistortion(20),
+		"Wang":        WangDistortion(0.7),
+		"power":       PowerDistortion(0.4),
+	}
+	for name, d := range distortions {
+		if error := math.Abs(d.G(1)-1) + math.Abs(d.G(0)); error > tolerance {
+			t.Errorf("%s: G(0)=%g, G(1)=%g\n", name, d.G(0), d.G(1))
+		}
+		for _, v := range []float64{1e-6, 0.01, 0.3, 0.9} {
+			if error := math.Abs(d.G(d.Inverse(v)) - v); math.IsNaN(error) || error > tolerance {
+				t.Errorf("%s: v=%g, error=%g\n", name, v, error)
+			}
+		}
+	}
+}
+
+// TestExpectedShortfallIsSpecialCase checks the ES distortion gives the empirical and distribution ES
+func TestExpectedShortfallIsSpecialCase(t *testing.T) {
+	const tolerance float64 = 1e-12
+	const toleranceForIntegration float64 = 1e-6 // relative
+	rng := rand.New(rand.NewSource(1))
+	x := make([]float64, 1001)
+	for i := range x {
+		x[i] = rng.NormFloat64()
+	}
+	lognormal := &distuv.LogNormal{Mu: 0.1, Sigma: 0.5}
+
+	for _, lambda := range []float64{0.001, 0.01, 0.025, 0.1, 0.5} {
+		d := ExpectedShortfallDistortion(lambda)
+		error := math.Abs(EmpiricalDistortionRisk(x, d, false) - EmpiricalEs(x, lambda, true))
+		if math.IsNaN(error) || error > tolerance {
+			t.Errorf("lambda=%g: empirical error=%g\n", lambda, error)
+		}
+
+		risk, _ := DistributionDistortionRisk(lognormal, d)
+		negativeRisk, _ := NegativeDistributionDistortionRisk(lognormal, d)
+		es, _ := DistributionEs(lognormal, lambda)
+		negativeEs, _ := NegativeDistributionEs(lognormal, lambda)
+		if risk != es || negativeRisk != negativeEs {
+			t.Errorf("lambda=%g: distortion risk=%g and %g, ES=%g and %g\n", lambda, risk, negativeRisk, es, negativeEs)
+		}
+		error = math.Abs(risk/LogNormalEs(0.1, 0.5, lambda)-1) + math.Abs(negativeRisk/NegativeLogNormalEs(0.1, 0.5, lambda)-1)
+		if math.IsNaN(error) || error > toleranceForIntegration {
+			t.Errorf("lambda=%g: closed form error=%g\n", lambda, error)
+		}
+	}
+}
+
+// TestDistributionDistortionRiskAgainstClosedForms checks Wang's transform of normal r.v.s and the distortion
+// risk measures of the uniform r.v. on [0, 1], which are -1 + integral of G over [0, 1] and for minus the r.v.
+// the integral of G
+func TestDistributionDistortionRiskAgainstClosedForms(t *testing.T) {
+	const tolerance float64 = 1e-8
+	mu, sigma := -0.3, 2.0
+	k, gamma := 10.0, 0.3
+	uniform := distuv.Uniform{Min: 0, Max: 1}
+	integralOfExponential := 1/(-math.Expm1(-k)) - 1/k
+
+	tables := []struct {
+		name     string
+		dist     interfaces.AnalyticalDistribution
+		d        Distortion
+		risk     float64
+		negative float64
+		// the error estimates include the contributions of the probabilities which round to 1,
+		// e.g. 1 - v^(1/gamma) for v below 1.6e-5
+		errTolerance float64
+	}{
+		{"Wang", distuv.Normal{Mu: mu, Sigma: sigma}, WangDistortion(1.5), 1.5*sigma - mu, 1.5*sigma + mu, tolerance},
+		{"Wang without distortion", distuv.Normal{Mu: mu, Sigma: sigma}, WangDistortion(0), -mu, mu, tolerance},
+		{"exponential", uniform, ExponentialDistortion(k), -1 + integralOfExponential, integralOfExponential, tolerance},
+		{"power", uniform, PowerDistortion(gamma), -1 + 1/(1+gamma), 1 / (1 + gamma), 1e-4},
+	}
+	for _, table := range tables {
+		risk, errEstimate := DistributionDistortionRisk(table.dist, table.d)
+		negativeRisk, negativeErrEstimate := NegativeDistributionDistortionRisk(table.dist, table.d)
+		error := math.Abs(risk-table.risk) + math.Abs(negativeRisk-table.negative)
+		if math.IsNaN(error) || error > tolerance || errEstimate > table.errTolerance || negativeErrEstimate > table.errTolerance {
+			t.Errorf("%s: risk=%g and %g, expected %g and %g, error estimates %g and %g\n", table.name,
+				risk, negativeRisk, table.risk, table.negative, errEstimate, negativeErrEstimate)
+		}
+	}
+}
+
+// TestEmpiricalDistortionRiskUsingMC compares the empirical and distribution risk measures of Student-t samples
+func TestEmpiricalDistortionRiskUsingMC(t *testing.T) {
+	const testToleranceForMC float64 = 2e-2 // relative
+	const numMCSamples int = 1000000
+	dist := distuv.StudentsT{Mu: 0.1, Sigma: 0.5, Nu: 5, Src: rand.New(rand.NewSource(1))}
+	x := make([]float64, numMCSamples)
+	for i := range x {
+		x[i] = dist.Rand()
+	}
+
+	for name, d := range map[string]Distortion{
+		"ES":          ExpectedShortfallDistortion(0.01),
+		"exponential": ExponentialDistortion(50),
+		"Wang":        WangDistortion(2),
+		"power":       PowerDistortion(0.7),
+	} {
+		risk, _ := DistributionDistortionRisk(dist, d)
+		error := math.Abs(EmpiricalDistortionRisk(x, d, false)/risk - 1)
+		if math.IsNaN(error) || error > testToleranceForMC {
+			t.Errorf("%s: empirical=%g, distribution=%g\n", name, EmpiricalDistortionRisk(x, d, true), risk)
+		}
+	}
+}
+
+// TestDistortionRiskReportsUnderflow checks that where the inverse distortion underflows the error estimate accounts
+// for the nodes at which the quantile couldn't be evaluated, the risk of a standard normal r.v. is computed by
+// integrating -x dG(N(x)) with the asymptotic expansion of log N(x) in the far tail
+func TestDistortionRiskReportsUnderflow(t *testing.T) {
+	const expected float64 = 12.19217
+	risk, errEstimate := DistributionDistortionRisk(distuv.UnitNormal, PowerDistortion(0.01))
+	negativeRisk, negativeErrEstimate := NegativeDistributionDistortionRisk(distuv.UnitNormal, PowerDistortion(0.01))
+	if errEstimate < math.Abs(risk-expected) || negativeErrEstimate < math.Abs(negativeRisk-expected) {
+		t.Errorf("risk=%g and %g, expected %g, error estimates %g and %g\n",
+			risk, negativeRisk, expected, errEstimate, negativeErrEstimate)
+	}
+}
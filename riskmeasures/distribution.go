@@ -0,0 +1,66 @@
+package riskmeasures
+
+import (
+	"math"
+
+	"code.vegaprotocol.io/quant/interfaces"
+
+	"gonum.org/v1/gonum/integrate/quad"
+)
+
+// distributionEsNodes is the number of Gauss-Legendre nodes used for the expected shortfall and the distortion risk
+// measures of a distribution, the error estimate compares it with the result on half as many nodes
+const distributionEsNodes = 128
+
+var (
+	esNodes, esWeights         = legendreOnUnitInterval(distributionEsNodes)
+	esCoarseNodes, esCoarseWts = legendreOnUnitInterval(distributionEsNodes / 2)
+)
+
+func legendreOnUnitInterval(n int) ([]float64, []float64) {
+	x := make([]float64, n)
+	weight := make([]float64, n)
+	quad.Legendre{}.FixedLocations(x, weight, 0, 1)
+	return x, weight
+}
+
+// DistributionVaR computes value at risk of a r.v. with the supplied distribution
+func DistributionVaR(d interfaces.AnalyticalDistribution, alpha float64) float64 {
+	return -d.Quantile(alpha)
+}
+
+// NegativeDistributionVaR computes value at risk of minus a r.v. with the supplied distribution
+func NegativeDistributionVaR(d interfaces.AnalyticalDistribution, alpha float64) float64 {
+	return d.Quantile(1.0 - alpha)
+}
+
+// DistributionEs returns the expected shortfall of a r.v. with the supplied distribution at given lambda level,
+// i.e. minus the average of its quantiles below lambda, along with an estimate of the integration error.
+// It is the distortion risk measure of ExpectedShortfallDistortion, see DistributionDistortionRisk.
+func DistributionEs(d interfaces.AnalyticalDistribution, lambd float64) (es, errEstimate float64) {
+	return DistributionDistortionRisk(d, ExpectedShortfallDistortion(lambd))
+}
+
+// NegativeDistributionEs returns the expected shortfall of minus a r.v. with the supplied distribution at given lambda level,
+// i.e. the average of its quantiles above 1 - lambda, along with an estimate of the integration error, see DistributionEs.
+func NegativeDistributionEs(d interfaces.AnalyticalDistribution, lambd float64) (es, errEstimate float64) {
+	return NegativeDistributionDistortionRisk(d, ExpectedShortfallDistortion(lambd))
+}
+
+// integrateTail returns the integral of 4 s^3 q(s) over [0, 1] on the fine and coarse Gauss-Legendre nodes,
+// and the absolute contribution of the fine nodes where q flags its value as unresolved
+func integrateTail(q func(s float64) (float64, bool)) (fine, coarse, unresolved float64) {
+	for i, s := range esNodes {
+		value, isUnresolved := q(s)
+		term := esWeights[i] * 4 * s * s * s * value
+		fine += term
+		if isUnresolved {
+			unresolved += math.Abs(term)
+		}
+	}
+	for i, s := range esCoarseNodes {
+		value, _ := q(s)
+		coarse += esCoarseWts[i] * 4 * s * s * s * value
+	}
+	return
+}
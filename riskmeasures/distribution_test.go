@@ -0,0 +1,111 @@
+package riskmeasures
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/interfaces"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func TestDistributionVaRAndEsAgainstClosedForms(t *testing.T) {
+	const tolerance float64 = 1e-6 // relative
+
+	tables := []struct {
+		name     string
+		d        interfaces.AnalyticalDistribution
+		varFn    func(alpha float64) float64
+		negVarFn func(alpha float64) float64
+		esFn     func(lambda float64) float64
+		negEsFn  func(lambda float64) float64
+	}{
+		{
+			name:     "lognormal",
+			d:        &distuv.LogNormal{Mu: 0.1, Sigma: 0.5},
+			varFn:    func(alpha float64) float64 { return LogNormalVaR(0.1, 0.5, alpha) },
+			negVarFn: func(alpha float64) float64 { return NegativeLogNormalVaR(0.1, 0.5, alpha) },
+			esFn:     func(lambda float64) float64 { return LogNormalEs(0.1, 0.5, lambda) },
+			negEsFn:  func(lambda float64) float64 { return NegativeLogNormalEs(0.1, 0.5, lambda) },
+		},
+		{
+			name:     "lognormal short horizon",
+			d:        &distuv.LogNormal{Mu: 0, Sigma: 0.001},
+			varFn:    func(alpha float64) float64 { return LogNormalVaR(0, 0.001, alpha) },
+			negVarFn: func(alpha float64) float64 { return NegativeLogNormalVaR(0, 0.001, alpha) },
+			esFn:     func(lambda float64) float64 { return LogNormalEs(0, 0.001, lambda) },
+			negEsFn:  func(lambda float64) float64 { return NegativeLogNormalEs(0, 0.001, lambda) },
+		},
+		{
+			name:     "normal",
+			d:        &distuv.Normal{Mu: -1, Sigma: 2},
+			varFn:    func(alpha float64) float64 { return NormalVaR(-1, 2, alpha) },
+			negVarFn: func(alpha float64) float64 { return NegativeNormalVaR(-1, 2, alpha) },
+			esFn:     func(lambda float64) float64 { return NormalEs(-1, 2, lambda) },
+			negEsFn:  func(lambda float64) float64 { return NegativeNormalEs(-1, 2, lambda) },
+		},
+		{
+			name:     "student-t",
+			d:        &distuv.StudentsT{Mu: 0.5, Sigma: 0.3, Nu: 3},
+			varFn:    func(alpha float64) float64 { return StudentTVaR(0.5, 0.3, 3, alpha) },
+			negVarFn: func(alpha float64) float64 { return NegativeStudentTVaR(0.5, 0.3, 3, alpha) },
+			esFn:     func(lambda float64) float64 { return StudentTEs(0.5, 0.3, 3, lambda) },
+			negEsFn:  func(lambda float64) float64 { return NegativeStudentTEs(0.5, 0.3, 3, lambda) },
+		},
+	}
+
+	for _, table := range tables {
+		for _, lambda := range []float64{1e-4, 1e-3, 0.01, 0.1, 0.5} {
+			errorVaR := math.Abs(DistributionVaR(table.d, lambda)-table.varFn(lambda)) +
+				math.Abs(NegativeDistributionVaR(table.d, lambda)-table.negVarFn(lambda))
+			if math.IsNaN(errorVaR) || errorVaR > testTolerance*math.Abs(table.varFn(lambda)) {
+				t.Errorf("%s, lambda=%g: VaR error=%g\n", table.name, lambda, errorVaR)
+			}
+
+			es, errEstimate := DistributionEs(table.d, lambda)
+			negEs, negErrEstimate := NegativeDistributionEs(table.d, lambda)
+			scale := math.Abs(table.esFn(lambda))
+			for _, check := range []struct {
+				es, errEstimate, expected float64
+			}{
+				{es, errEstimate, table.esFn(lambda)},
+				{negEs, negErrEstimate, table.negEsFn(lambda)},
+			} {
+				error := math.Abs(check.es - check.expected)
+				if math.IsNaN(error) || error > tolerance*scale {
+					t.Errorf("%s, lambda=%g: ES=%g, expected=%g, error estimate=%g\n", table.name, lambda, check.es, check.expected, check.errEstimate)
+				}
+				if check.errEstimate > tolerance*scale {
+					t.Errorf("%s, lambda=%g: error estimate=%g\n", table.name, lambda, check.errEstimate)
+				}
+			}
+		}
+	}
+}
+
+// infiniteTail is a uniform distribution on [0, 1] with an atom at +Inf of mass 1e-10
+type infiniteTail struct{}
+
+func (infiniteTail) Mean() float64 { return math.Inf(1) }
+
+func (infiniteTail) Variance() float64 { return math.Inf(1) }
+
+func (infiniteTail) CDF(x float64) float64 { return math.Min(math.Max(x, 0), 1) * (1 - 1e-10) }
+
+func (infiniteTail) Quantile(p float64) float64 {
+	if p > 1-1e-10 {
+		return math.Inf(1)
+	}
+	return p / (1 - 1e-10)
+}
+
+func TestDistributionEsWithInfiniteQuantile(t *testing.T) {
+	if negEs, _ := NegativeDistributionEs(infiniteTail{}, 0.01); !math.IsInf(negEs, 1) {
+		t.Errorf("negative ES=%g, expected +Inf\n", negEs)
+	}
+	es, errEstimate := DistributionEs(infiniteTail{}, 0.01)
+	error := math.Abs(es + 0.005)
+	if math.IsNaN(error) || error > 1e-9 || errEstimate > 1e-9 {
+		t.Errorf("ES=%g, expected=%g, error estimate=%g\n", es, -0.005, errEstimate)
+	}
+}
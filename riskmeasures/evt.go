@@ -0,0 +1,316 @@
+package riskmeasures
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"code.vegaprotocol.io/quant/misc"
+
+	"gonum.org/v1/gonum/optimize"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Extreme value theory: above a high threshold u the losses L (-x for the lower tail of x, i.e. long positions,
+// and x for the upper tail) are approximately u plus a Generalised Pareto r.v., which extrapolates the empirical
+// distribution to levels with few or no samples, see McNeil, Frey and Embrechts (Quantitative Risk Management, 2005).
+
+var (
+	// ErrTooFewExceedances is returned when there are fewer than minExceedances losses above the threshold
+	ErrTooFewExceedances = errors.New("too few exceedances to fit the generalised Pareto distribution")
+	// ErrInvalidTailSize is returned when the number of tail samples isn't below the number of samples
+	ErrInvalidTailSize = errors.New("number of tail samples must be below the number of samples")
+	// ErrLambdaAboveThreshold is returned when the tail probability is beyond the threshold, i.e. above k / n
+	ErrLambdaAboveThreshold = errors.New("lambda must be below the fraction of samples above the threshold")
+	// ErrGPDFitFailed is returned when the optimiser doesn't find the maximum likelihood parameters
+	ErrGPDFitFailed = errors.New("generalised Pareto distribution fit failed")
+	// ErrNonPositiveThreshold is returned by Hill when the threshold loss isn't positive
+	ErrNonPositiveThreshold = errors.New("the Hill estimator needs a positive threshold")
+)
+
+const minExceedances = 10
+
+// GPD is the Generalised Pareto distribution with shape Xi and scale Beta > 0,
+// its CDF is 1 - (1 + Xi y / Beta)^(-1/Xi) for y >= 0 (and 1 - exp(-y / Beta) when Xi = 0)
+type GPD struct {
+	Xi   float64
+	Beta float64
+}
+
+// gpdXiZero is the shape below which the GPD is treated as exponential
+const gpdXiZero = 1e-9
+
+// CDF returns the probability that the r.v. is at most y
+func (g GPD) CDF(y float64) float64 {
+	if y <= 0 {
+		return 0
+	}
+	if math.Abs(g.Xi) < gpdXiZero {
+		return -math.Expm1(-y / g.Beta)
+	}
+	z := 1 + g.Xi*y/g.Beta
+	if z <= 0 {
+		return 1
+	}
+	return 1 - math.Pow(z, -1/g.Xi)
+}
+
+// Quantile returns y with CDF(y) = p
+func (g GPD) Quantile(p float64) float64 {
+	if math.Abs(g.Xi) < gpdXiZero {
+		return -g.Beta * math.Log1p(-p)
+	}
+	return g.Beta / g.Xi * math.Expm1(-g.Xi*math.Log1p(-p))
+}
+
+// Mean returns the mean Beta / (1 - Xi), which is only finite for Xi < 1
+func (g GPD) Mean() float64 {
+	if g.Xi >= 1 {
+		return math.Inf(1)
+	}
+	return g.Beta / (1 - g.Xi)
+}
+
+// logLikelihood returns the log-likelihood of the samples y, -Inf if any of them is outside the support
+func (g GPD) logLikelihood(y []float64) float64 {
+	if !(g.Beta > 0) {
+		return math.Inf(-1)
+	}
+	n := float64(len(y))
+	if math.Abs(g.Xi) < gpdXiZero {
+		var sum float64
+		for _, yi := range y {
+			sum += yi
+		}
+		return -n*math.Log(g.Beta) - sum/g.Beta
+	}
+	var sum float64
+	for _, yi := range y {
+		z := 1 + g.Xi*yi/g.Beta
+		if z <= 0 {
+			return math.Inf(-1)
+		}
+		sum += math.Log(z)
+	}
+	return -n*math.Log(g.Beta) - (1+1/g.Xi)*sum
+}
+
+// GPDFitMethod selects how the GPD parameters are estimated
+type GPDFitMethod int
+
+const (
+	// MaximumLikelihood maximises the likelihood of the exceedances, its standard errors are valid for Xi > -1/2
+	MaximumLikelihood GPDFitMethod = iota
+	// ProbabilityWeightedMoments matches the first two probability weighted moments as in Hosking and Wallis
+	// (Parameter and quantile estimation for the generalized Pareto distribution, Technometrics, 1987),
+	// its standard errors are valid for Xi < 1/2
+	ProbabilityWeightedMoments
+)
+
+// FitGPD estimates the GPD of the exceedances y (the losses above the threshold minus the threshold)
+func FitGPD(y []float64, method GPDFitMethod) (GPD, error) {
+	if len(y) < minExceedances {
+		return GPD{}, ErrTooFewExceedances
+	}
+	pwm := fitGPDPWM(y)
+	if method == ProbabilityWeightedMoments {
+		return pwm, nil
+	}
+	return fitGPDMLE(y, pwm)
+}
+
+// fitGPDPWM returns Xi = 2 - a0 / (a0 - 2 a1) and Beta = 2 a0 a1 / (a0 - 2 a1) where a0 is the mean of y and
+// a1 the mean of (1 - p_i) y_(i) with the plotting positions p_i = (i - 0.35) / n of the ordered samples
+func fitGPDPWM(y []float64) GPD {
+	sorted := make([]float64, len(y))
+	copy(sorted, y)
+	sort.Float64s(sorted)
+	n := float64(len(sorted))
+	var a0, a1 float64
+	for i, yi := range sorted {
+		a0 += yi / n
+		a1 += (1 - (float64(i+1)-0.35)/n) * yi / n
+	}
+	return GPD{Xi: 2 - a0/(a0-2*a1), Beta: 2 * a0 * a1 / (a0 - 2*a1)}
+}
+
+// fitGPDMLE maximises the likelihood over (Xi, log Beta) starting from the initial estimate
+func fitGPDMLE(y []float64, initial GPD) (GPD, error) {
+	maxY := 0.0
+	for _, yi := range y {
+		maxY = math.Max(maxY, yi)
+	}
+	// start within the support if the moment estimate isn't
+	if !(initial.Beta > 0) || 1+initial.Xi*maxY/initial.Beta <= 0 {
+		initial = GPD{Xi: 0, Beta: math.Max(initial.Beta, maxY)}
+	}
+	problem := optimize.Problem{
+		Func: func(x []float64) float64 {
+			ll := GPD{Xi: x[0], Beta: math.Exp(x[1])}.logLikelihood(y)
+			if math.IsInf(ll, -1) || math.IsNaN(ll) {
+				return math.MaxFloat64
+			}
+			return -ll
+		},
+	}
+	settings := &optimize.Settings{
+		Converger:       &optimize.FunctionConverge{Absolute: 1e-12, Relative: 1e-12, Iterations: 100},
+		MajorIterations: 10000,
+	}
+	result, err := optimize.Minimize(problem, []float64{initial.Xi, math.Log(initial.Beta)}, settings, &optimize.NelderMead{})
+	if result == nil || result.F == math.MaxFloat64 {
+		if err == nil {
+			err = ErrGPDFitFailed
+		}
+		return GPD{}, err
+	}
+	return GPD{Xi: result.X[0], Beta: math.Exp(result.X[1])}, nil
+}
+
+// covariance returns the asymptotic covariance matrix of the estimates of (Xi, Beta) from n exceedances
+func (g GPD) covariance(n int, method GPDFitMethod) (varXi, varBeta, cov float64) {
+	xi, beta, nf := g.Xi, g.Beta, float64(n)
+	if method == ProbabilityWeightedMoments {
+		// Hosking and Wallis give the covariance for k = -Xi
+		k := -xi
+		d := nf * (1 + 2*k) * (3 + 2*k)
+		varXi = (1 + k) * (2 + k) * (2 + k) * (1 + k + 2*k*k) / d
+		varBeta = beta * beta * (7 + 18*k + 11*k*k + 2*k*k*k) / d
+		cov = -beta * (2 + k) * (2 + 6*k + 7*k*k + 2*k*k*k) / d
+		return
+	}
+	varXi = (1 + xi) * (1 + xi) / nf
+	varBeta = 2 * beta * beta * (1 + xi) / nf
+	cov = -beta * (1 + xi) / nf
+	return
+}
+
+// TailEstimate is the value at risk and expected shortfall of a tail extrapolated with the GPD
+// fitted to the Exceedances losses above the Threshold
+type TailEstimate struct {
+	VaR         float64
+	Es          float64
+	VaRInterval misc.ConfidenceInterval
+	EsInterval  misc.ConfidenceInterval
+	Threshold   float64
+	Exceedances int
+	GPD         GPD
+}
+
+// PeaksOverThreshold estimates the value at risk and expected shortfall of the lower tail of the samples x at level
+// lambda, i.e. as EmpiricalVaR and EmpiricalEs, by fitting the GPD to the k largest losses -x over the threshold
+// given by the (k+1)-th largest loss u: VaR = u + Beta / Xi ((n lambda / k)^(-Xi) - 1) and ES = (VaR + Beta - Xi u) / (1 - Xi),
+// which is infinite for Xi >= 1. The confidence intervals at the given confidence level come from the asymptotic
+// normality of the parameter estimates by the delta method, treating the threshold as fixed.
+func PeaksOverThreshold(x []float64, k int, lambda, confidence float64, method GPDFitMethod) (TailEstimate, error) {
+	losses := make([]float64, len(x))
+	for i, xi := range x {
+		losses[i] = -xi
+	}
+	return peaksOverThreshold(losses, k, lambda, confidence, method)
+}
+
+// NegativePeaksOverThreshold estimates the value at risk and expected shortfall of the upper tail of the samples x,
+// i.e. of minus x, see PeaksOverThreshold
+func NegativePeaksOverThreshold(x []float64, k int, lambda, confidence float64, method GPDFitMethod) (TailEstimate, error) {
+	losses := make([]float64, len(x))
+	copy(losses, x)
+	return peaksOverThreshold(losses, k, lambda, confidence, method)
+}
+
+// peaksOverThreshold sorts the losses in place and extrapolates their upper tail
+func peaksOverThreshold(losses []float64, k int, lambda, confidence float64, method GPDFitMethod) (TailEstimate, error) {
+	n := len(losses)
+	if err := misc.FirstError(misc.ValidateProbability(lambda), misc.ValidateProbability(confidence)); err != nil {
+		return TailEstimate{}, err
+	}
+	if k >= n {
+		return TailEstimate{}, ErrInvalidTailSize
+	}
+	if k < minExceedances {
+		return TailEstimate{}, ErrTooFewExceedances
+	}
+	if lambda > float64(k)/float64(n) {
+		return TailEstimate{}, ErrLambdaAboveThreshold
+	}
+	sort.Float64s(losses)
+	threshold := losses[n-k-1]
+	exceedances := make([]float64, k)
+	for i := range exceedances {
+		exceedances[i] = losses[n-k+i] - threshold
+	}
+	gpd, err := FitGPD(exceedances, method)
+	if err != nil {
+		return TailEstimate{}, err
+	}
+
+	// the tail probability lambda is the probability k / n of exceeding the threshold times that of the GPD
+	tailProbability := lambda * float64(n) / float64(k)
+	varFn := func(g GPD) float64 {
+		return threshold + g.Quantile(1-tailProbability)
+	}
+	esFn := func(g GPD) float64 {
+		if g.Xi >= 1 {
+			return math.Inf(1)
+		}
+		return (varFn(g) + g.Beta - g.Xi*threshold) / (1 - g.Xi)
+	}
+
+	estimate := TailEstimate{VaR: varFn(gpd), Es: esFn(gpd), Threshold: threshold, Exceedances: k, GPD: gpd}
+	z := distuv.UnitNormal.Quantile(0.5 + 0.5*confidence)
+	varXi, varBeta, cov := gpd.covariance(k, method)
+	interval := func(fn func(GPD) float64, value float64) misc.ConfidenceInterval {
+		// the delta method with central differences for the gradient w.r.t. (Xi, Beta)
+		hXi, hBeta := 1e-6, 1e-6*gpd.Beta
+		dXi := (fn(GPD{Xi: gpd.Xi + hXi, Beta: gpd.Beta}) - fn(GPD{Xi: gpd.Xi - hXi, Beta: gpd.Beta})) / (2 * hXi)
+		dBeta := (fn(GPD{Xi: gpd.Xi, Beta: gpd.Beta + hBeta}) - fn(GPD{Xi: gpd.Xi, Beta: gpd.Beta - hBeta})) / (2 * hBeta)
+		stdErr := math.Sqrt(dXi*dXi*varXi + dBeta*dBeta*varBeta + 2*dXi*dBeta*cov)
+		return misc.ConfidenceInterval{Lower: value - z*stdErr, Upper: value + z*stdErr, Confidence: confidence}
+	}
+	estimate.VaRInterval = interval(varFn, estimate.VaR)
+	estimate.EsInterval = interval(esFn, estimate.Es)
+	return estimate, nil
+}
+
+// MeanExcess returns the mean excess e(u) = E[L - u | L > u] of the losses over each of the thresholds (NaN when no
+// loss exceeds it), for a GPD tail the mean excess plot is linear in u above the threshold with slope Xi / (1 - Xi)
+func MeanExcess(losses []float64, thresholds []float64) []float64 {
+	meanExcess := make([]float64, len(thresholds))
+	for j, u := range thresholds {
+		var sum float64
+		var count int
+		for _, l := range losses {
+			if l > u {
+				sum += l - u
+				count++
+			}
+		}
+		meanExcess[j] = sum / float64(count)
+		if count == 0 {
+			meanExcess[j] = math.NaN()
+		}
+	}
+	return meanExcess
+}
+
+// Hill returns the Hill estimate of the tail index Xi from the k largest losses,
+// 1/k sum_{i=1..k} ln(L_(i) / L_(k+1)) where L_(i) is the i-th largest loss, which needs L_(k+1) > 0.
+// Plotting it against k helps to choose the number of tail samples where the estimate is stable.
+func Hill(losses []float64, k int) (float64, error) {
+	n := len(losses)
+	if k >= n || k < 1 {
+		return 0, ErrInvalidTailSize
+	}
+	sorted := make([]float64, n)
+	copy(sorted, losses)
+	sort.Float64s(sorted)
+	threshold := sorted[n-k-1]
+	if !(threshold > 0) {
+		return 0, ErrNonPositiveThreshold
+	}
+	var sum float64
+	for _, l := range sorted[n-k:] {
+		sum += math.Log(l / threshold)
+	}
+	return sum / float64(k), nil
+}
@@ -0,0 +1,201 @@
+package riskmeasures
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/misc"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// sampleGPD draws n samples of the GPD by inverting its CDF
+func sampleGPD(g GPD, n int, rng *rand.Rand) []float64 {
+	samples := make([]float64, n)
+	for i := range samples {
+		samples[i] = g.Quantile(rng.Float64())
+	}
+	return samples
+}
+
+func TestGPDQuantileInvertsCDF(t *testing.T) {
+	const tolerance float64 = 1e-12
+	for _, g := range []GPD{{0.3, 1}, {0, 2}, {-0.2, 0.5}, {1e-12, 1}} {
+		for _, p := range []float64{1e-6, 0.1, 0.5, 0.99, 0.999999} {
+			error := math.Abs(g.CDF(g.Quantile(p)) - p)
+			if math.IsNaN(error) || error > tolerance {
+				t.Errorf("xi=%g, beta=%g, p=%g: error=%g\n", g.Xi, g.Beta, p, error)
+			}
+		}
+	}
+}
+
+func TestFitGPDRecoversParameters(t *testing.T) {
+	const tolerance float64 = 0.05
+	tables := []struct {
+		gpd    GPD
+		method GPDFitMethod
+	}{
+		{GPD{0.3, 1}, MaximumLikelihood},
+		{GPD{0.3, 1}, ProbabilityWeightedMoments},
+		{GPD{0, 2}, MaximumLikelihood},
+		{GPD{-0.2, 0.5}, ProbabilityWeightedMoments},
+	}
+	for _, table := range tables {
+		rng := rand.New(rand.NewSource(1))
+		fitted, err := FitGPD(sampleGPD(table.gpd, 50000, rng), table.method)
+		if err != nil {
+			t.Fatalf("xi=%g, beta=%g: %v\n", table.gpd.Xi, table.gpd.Beta, err)
+		}
+		error := math.Abs(fitted.Xi-table.gpd.Xi) + math.Abs(fitted.Beta/table.gpd.Beta-1)
+		if math.IsNaN(error) || error > tolerance {
+			t.Errorf("xi=%g, beta=%g, method=%d: fitted %v, error=%g\n", table.gpd.Xi, table.gpd.Beta, table.method, fitted, error)
+		}
+	}
+}
+
+// TestPeaksOverThresholdStudentT compares the extrapolated tails of Student-t samples with the exact risk measures
+// at a level with only a few samples beyond the VaR
+func TestPeaksOverThresholdStudentT(t *testing.T) {
+	const tolerance float64 = 0.1 // relative, the samples beyond the threshold make for about 5% standard error
+	const numSamples, k int = 100000, 1000
+	mu, sigma, nu, lambda := 0.1, 2.0, 4.0, 1e-3
+
+	for _, method := range []GPDFitMethod{MaximumLikelihood, ProbabilityWeightedMoments} {
+		dist := distuv.StudentsT{Mu: mu, Sigma: sigma, Nu: nu, Src: rand.New(rand.NewSource(1))}
+		X := make([]float64, numSamples)
+		for i := range X {
+			X[i] = dist.Rand()
+		}
+		lower, err := PeaksOverThreshold(X, k, lambda, 0.99, method)
+		if err != nil {
+			t.Fatalf("method=%d: %v\n", method, err)
+		}
+		upper, err := NegativePeaksOverThreshold(X, k, lambda, 0.99, method)
+		if err != nil {
+			t.Fatalf("method=%d: %v\n", method, err)
+		}
+
+		checks := []struct {
+			name     string
+			estimate float64
+			interval misc.ConfidenceInterval
+			exact    float64
+		}{
+			{"VaR", lower.VaR, lower.VaRInterval, StudentTVaR(mu, sigma, nu, lambda)},
+			{"ES", lower.Es, lower.EsInterval, StudentTEs(mu, sigma, nu, lambda)},
+			{"negative VaR", upper.VaR, upper.VaRInterval, NegativeStudentTVaR(mu, sigma, nu, lambda)},
+			{"negative ES", upper.Es, upper.EsInterval, NegativeStudentTEs(mu, sigma, nu, lambda)},
+		}
+		for _, c := range checks {
+			error := math.Abs(c.estimate/c.exact - 1)
+			if math.IsNaN(error) || error > tolerance {
+				t.Errorf("method=%d, %s: estimate=%g, exact=%g, error=%g\n", method, c.name, c.estimate, c.exact, error)
+			}
+			if !c.interval.Contains(c.exact) {
+				t.Errorf("method=%d, %s: exact=%g outside the interval %v\n", method, c.name, c.exact, c.interval)
+			}
+		}
+	}
+}
+
+// TestPeaksOverThresholdCoverage checks the confidence intervals cover the exact risk measures of a GPD about as
+// often as their confidence level
+func TestPeaksOverThresholdCoverage(t *testing.T) {
+	const numRuns, numSamples, k int = 400, 2000, 200
+	const lambda, confidence, tolerance float64 = 0.005, 0.9, 0.05
+	gpd := GPD{Xi: 0.2, Beta: 1}
+	exactVaR := gpd.Quantile(1 - lambda)
+	exactEs := (exactVaR + gpd.Beta) / (1 - gpd.Xi)
+
+	for _, method := range []GPDFitMethod{MaximumLikelihood, ProbabilityWeightedMoments} {
+		rng := rand.New(rand.NewSource(1))
+		var coveredVaR, coveredEs int
+		for run := 0; run < numRuns; run++ {
+			estimate, err := NegativePeaksOverThreshold(sampleGPD(gpd, numSamples, rng), k, lambda, confidence, method)
+			if err != nil {
+				t.Fatalf("method=%d: %v\n", method, err)
+			}
+			if estimate.VaRInterval.Contains(exactVaR) {
+				coveredVaR++
+			}
+			if estimate.EsInterval.Contains(exactEs) {
+				coveredEs++
+			}
+		}
+		for _, covered := range []int{coveredVaR, coveredEs} {
+			error := math.Abs(float64(covered)/float64(numRuns) - confidence)
+			if error > tolerance {
+				t.Errorf("method=%d: coverage %d out of %d runs\n", method, covered, numRuns)
+			}
+		}
+	}
+}
+
+func TestHillEstimatesParetoTailIndex(t *testing.T) {
+	const tolerance float64 = 0.02
+	for _, xi := range []float64{0.25, 0.5, 1} {
+		rng := rand.New(rand.NewSource(1))
+		// Pareto samples with P(L > l) = l^(-1/xi) for l >= 1
+		losses := make([]float64, 100000)
+		for i := range losses {
+			losses[i] = math.Pow(1-rng.Float64(), -xi)
+		}
+		hill, err := Hill(losses, 5000)
+		if err != nil {
+			t.Fatalf("xi=%g: %v\n", xi, err)
+		}
+		error := math.Abs(hill/xi - 1)
+		if math.IsNaN(error) || error > tolerance {
+			t.Errorf("xi=%g: Hill estimate=%g\n", xi, hill)
+		}
+	}
+}
+
+func TestMeanExcessOfGPDIsLinear(t *testing.T) {
+	const tolerance float64 = 0.05
+	gpd := GPD{Xi: 0.2, Beta: 1}
+	losses := sampleGPD(gpd, 200000, rand.New(rand.NewSource(1)))
+	thresholds := []float64{0, 0.5, 1, 2, 3}
+	for i, meanExcess := range MeanExcess(losses, thresholds) {
+		// e(u) = (beta + xi u) / (1 - xi)
+		exact := (gpd.Beta + gpd.Xi*thresholds[i]) / (1 - gpd.Xi)
+		error := math.Abs(meanExcess/exact - 1)
+		if math.IsNaN(error) || error > tolerance {
+			t.Errorf("u=%g: mean excess=%g, exact=%g\n", thresholds[i], meanExcess, exact)
+		}
+	}
+	if meanExcess := MeanExcess(losses, []float64{math.Inf(1)}); !math.IsNaN(meanExcess[0]) {
+		t.Errorf("expected NaN above all the losses, got %g\n", meanExcess[0])
+	}
+}
+
+func TestPeaksOverThresholdErrors(t *testing.T) {
+	x := sampleGPD(GPD{Xi: 0.2, Beta: 1}, 100, rand.New(rand.NewSource(1)))
+	tables := []struct {
+		x          []float64
+		k          int
+		lambda     float64
+		confidence float64
+		err        error
+	}{
+		{x, 20, 0, 0.9, misc.ErrProbabilityOutOfRange},
+		{x, 20, 0.01, 1, misc.ErrProbabilityOutOfRange},
+		{x, 100, 0.01, 0.9, ErrInvalidTailSize},
+		{x, 5, 0.01, 0.9, ErrTooFewExceedances},
+		{x, 20, 0.5, 0.9, ErrLambdaAboveThreshold},
+		{x[:5], 20, 0.01, 0.9, ErrInvalidTailSize},
+	}
+	for i, table := range tables {
+		if _, err := PeaksOverThreshold(table.x, table.k, table.lambda, table.confidence, MaximumLikelihood); err != table.err {
+			t.Errorf("case %d: expected %v, got %v\n", i, table.err, err)
+		}
+	}
+	if _, err := FitGPD(x[:5], ProbabilityWeightedMoments); err != ErrTooFewExceedances {
+		t.Errorf("expected %v, got %v\n", ErrTooFewExceedances, err)
+	}
+	if _, err := Hill([]float64{-3, -2, -1}, 1); err != ErrNonPositiveThreshold {
+		t.Errorf("expected %v, got %v\n", ErrNonPositiveThreshold, err)
+	}
+}
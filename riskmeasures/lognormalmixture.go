@@ -0,0 +1,77 @@
+package riskmeasures
+
+import (
+	"errors"
+	"math"
+
+	"code.vegaprotocol.io/quant/misc"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ErrInvalidMixture is returned when the mixture weights aren't non-negative summing to 1,
+// with a log-mean and a log-standard deviation per component
+var ErrInvalidMixture = errors.New("mixture weights must be non-negative and sum to 1, one per component")
+
+const (
+	mixtureQuantileMaxIter = 200
+	mixtureQuantileTol     = 1e-14
+)
+
+// LogNormalMixtureVaR computes value at risk of a mixture of LogNormal r.v.s where the i-th component has
+// probability weights[i] and log-mean and log-standard deviation mus[i] and sigmas[i]
+func LogNormalMixtureVaR(weights, mus, sigmas []float64, alpha float64) float64 {
+	return -logNormalMixtureQuantile(weights, mus, sigmas, alpha)
+}
+
+// NegativeLogNormalMixtureVaR computes value at risk of minus a mixture of LogNormal r.v.s, see LogNormalMixtureVaR
+func NegativeLogNormalMixtureVaR(weights, mus, sigmas []float64, alpha float64) float64 {
+	return logNormalMixtureQuantile(weights, mus, sigmas, 1.0-alpha)
+}
+
+// LogNormalMixtureEs returns the expected shortfall of a mixture of LogNormal r.v.s at given lambda level, see LogNormalMixtureVaR
+func LogNormalMixtureEs(weights, mus, sigmas []float64, lambd float64) float64 {
+	logQuantile := math.Log(logNormalMixtureQuantile(weights, mus, sigmas, lambd))
+	var tailMean float64
+	for i, w := range weights {
+		tailMean += w * math.Exp(mus[i]+sigmas[i]*sigmas[i]*0.5) * distuv.UnitNormal.CDF((logQuantile-mus[i])/sigmas[i]-sigmas[i])
+	}
+	return -tailMean / lambd
+}
+
+// NegativeLogNormalMixtureEs returns the expected shortfall of minus a mixture of LogNormal r.v.s at given lambda level, see LogNormalMixtureVaR
+func NegativeLogNormalMixtureEs(weights, mus, sigmas []float64, lambd float64) float64 {
+	logQuantile := math.Log(logNormalMixtureQuantile(weights, mus, sigmas, 1.0-lambd))
+	var tailMean float64
+	for i, w := range weights {
+		tailMean += w * math.Exp(mus[i]+sigmas[i]*sigmas[i]*0.5) * distuv.UnitNormal.Survival((logQuantile-mus[i])/sigmas[i]-sigmas[i])
+	}
+	return tailMean / lambd
+}
+
+// logNormalMixtureQuantile returns x such that the mixture CDF at x equals p, found by Brent's method in log space
+func logNormalMixtureQuantile(weights, mus, sigmas []float64, p float64) float64 {
+	f := func(logX float64) float64 {
+		var cdf float64
+		for i, w := range weights {
+			cdf += w * distuv.UnitNormal.CDF((logX-mus[i])/sigmas[i])
+		}
+		return cdf - p
+	}
+
+	// the quantile lies within the range of the components' quantiles
+	z := distuv.UnitNormal.Quantile(p)
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for i := range weights {
+		lo = math.Min(lo, mus[i]+sigmas[i]*z)
+		hi = math.Max(hi, mus[i]+sigmas[i]*z)
+	}
+	if lo == hi {
+		return math.Exp(lo)
+	}
+	logX, err := misc.FindRootBrent(f, lo, hi, mixtureQuantileMaxIter, mixtureQuantileTol*math.Max(1, math.Abs(hi)))
+	if err != nil {
+		return math.NaN()
+	}
+	return math.Exp(logX)
+}
@@ -0,0 +1,67 @@
+package riskmeasures
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+func TestLogNormalMixtureWithOneComponent(t *testing.T) {
+	for _, vals := range testValsForESLognormal {
+		weights, mus, sigmas := []float64{1}, []float64{vals.mu}, []float64{vals.sigma}
+		error := math.Abs(LogNormalMixtureVaR(weights, mus, sigmas, vals.lambda)-LogNormalVaR(vals.mu, vals.sigma, vals.lambda)) +
+			math.Abs(NegativeLogNormalMixtureVaR(weights, mus, sigmas, vals.lambda)-NegativeLogNormalVaR(vals.mu, vals.sigma, vals.lambda)) +
+			math.Abs(LogNormalMixtureEs(weights, mus, sigmas, vals.lambda)-LogNormalEs(vals.mu, vals.sigma, vals.lambda)) +
+			math.Abs(NegativeLogNormalMixtureEs(weights, mus, sigmas, vals.lambda)-NegativeLogNormalEs(vals.mu, vals.sigma, vals.lambda))
+		if math.IsNaN(error) || math.IsInf(error, 0) || error > testTolerance {
+			t.Errorf("mu=%g, sigma=%g, lambda=%g: error=%g is greater than tolerance\n", vals.mu, vals.sigma, vals.lambda, error)
+		}
+	}
+}
+
+func TestLogNormalMixtureUsingMC(t *testing.T) {
+	const testToleranceForMC float64 = 1e-2 // relative
+	const numMCSamples int = 1000000
+
+	weights := []float64{0.7, 0.25, 0.05}
+	mus := []float64{0.0, -0.2, 0.3}
+	sigmas := []float64{0.1, 0.3, 0.5}
+
+	// use our own source so that the samples drawn by other tests don't change
+	rng := rand.New(rand.NewSource(1))
+	X := make([]float64, numMCSamples)
+	negX := make([]float64, numMCSamples)
+	for i := range X {
+		u := rng.Float64()
+		component := 0
+		for u > weights[component] && component < len(weights)-1 {
+			u -= weights[component]
+			component++
+		}
+		X[i] = math.Exp(mus[component] + sigmas[component]*rng.NormFloat64())
+		negX[i] = -X[i]
+	}
+	sort.Float64s(X)
+	sort.Float64s(negX)
+
+	for _, lambda := range []float64{0.01, 0.05, 0.2} {
+		tables := []struct {
+			name      string
+			empirical float64
+			analytic  float64
+		}{
+			{"VaR", EmpiricalVaR(X, lambda, true), LogNormalMixtureVaR(weights, mus, sigmas, lambda)},
+			{"negative VaR", EmpiricalVaR(negX, lambda, true), NegativeLogNormalMixtureVaR(weights, mus, sigmas, lambda)},
+			{"ES", EmpiricalEs(X, lambda, true), LogNormalMixtureEs(weights, mus, sigmas, lambda)},
+			{"negative ES", EmpiricalEs(negX, lambda, true), NegativeLogNormalMixtureEs(weights, mus, sigmas, lambda)},
+		}
+		for _, table := range tables {
+			error := math.Abs(table.empirical-table.analytic) / math.Abs(table.analytic)
+			if math.IsNaN(error) || math.IsInf(error, 0) || error > testToleranceForMC {
+				t.Errorf("%s: lambda=%g, empirical=%g, analytic=%g\n", table.name, lambda, table.empirical, table.analytic)
+			}
+		}
+	}
+}
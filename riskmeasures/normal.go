@@ -0,0 +1,27 @@
+package riskmeasures
+
+import (
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// NormalVaR computes value at risk of Normal r.v.
+func NormalVaR(mu, sigma, alpha float64) float64 {
+	return -(mu + sigma*distuv.UnitNormal.Quantile(alpha))
+}
+
+// NegativeNormalVaR computes value at risk of minus a Normal r.v.
+func NegativeNormalVaR(mu, sigma, alpha float64) float64 {
+	return mu + sigma*distuv.UnitNormal.Quantile(1.0-alpha)
+}
+
+// NormalEs returns the expected shortfall of a normal r.v. at given lambda level
+func NormalEs(mu, sigma, lambd float64) float64 {
+	var quantileForLambda = distuv.UnitNormal.Quantile(lambd)
+	return -mu + sigma*distuv.UnitNormal.Prob(quantileForLambda)/lambd
+}
+
+// NegativeNormalEs returns the expected shortfall of minus a normal r.v. at given lambda level
+func NegativeNormalEs(mu, sigma, lambd float64) float64 {
+	var quantileForLambda = distuv.UnitNormal.Quantile(lambd)
+	return mu + sigma*distuv.UnitNormal.Prob(quantileForLambda)/lambd
+}
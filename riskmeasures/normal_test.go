@@ -0,0 +1,60 @@
+package riskmeasures
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"golang.org/x/exp/rand"
+)
+
+func TestVaRAndESNormalUsingMC(t *testing.T) {
+	const testToleranceForMC float64 = 1e-2 // relative to sigma
+	const numMCSamples int = 1000000
+
+	// use our own source so that the samples drawn by other tests don't change
+	rng := rand.New(rand.NewSource(1))
+	Z := make([]float64, 2*numMCSamples)
+	for i := 0; i < numMCSamples; i++ {
+		z := rng.NormFloat64()
+		Z[i] = z
+		Z[numMCSamples+i] = -z //antithetic
+	}
+	sort.Float64s(Z)
+
+	tables := []struct {
+		mu     float64
+		sigma  float64
+		lambda float64
+	}{
+		{0.0, 0.1, 0.01},
+		{0.0, 1.0, 0.05},
+		{1.0, 2.0, 0.01},
+		{-1.0, 0.5, 0.2},
+	}
+
+	for _, table := range tables {
+		mu, sigma, lambda := table.mu, table.sigma, table.lambda
+
+		X := make([]float64, len(Z))
+		negX := make([]float64, len(Z))
+		for i := range Z {
+			X[i] = mu + sigma*Z[i]
+			negX[len(Z)-1-i] = -X[i]
+		}
+
+		errorVaR := math.Abs(EmpiricalVaR(X, lambda, true)-NormalVaR(mu, sigma, lambda)) +
+			math.Abs(EmpiricalVaR(negX, lambda, true)-NegativeNormalVaR(mu, sigma, lambda))
+		errorVaR /= sigma
+		if math.IsNaN(errorVaR) || math.IsInf(errorVaR, 0) || errorVaR > testToleranceForMC {
+			t.Errorf("VaR: mu=%g, sigma=%g, lambda=%g, error=%g greater than MC tolerance\n", mu, sigma, lambda, errorVaR)
+		}
+
+		errorES := math.Abs(EmpiricalEs(X, lambda, true)-NormalEs(mu, sigma, lambda)) +
+			math.Abs(EmpiricalEs(negX, lambda, true)-NegativeNormalEs(mu, sigma, lambda))
+		errorES /= sigma
+		if math.IsNaN(errorES) || math.IsInf(errorES, 0) || errorES > testToleranceForMC {
+			t.Errorf("ES: mu=%g, sigma=%g, lambda=%g, error=%g greater than MC tolerance\n", mu, sigma, lambda, errorES)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package riskmeasures
+
+import (
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// StudentTVaR computes value at risk of a Student-t r.v. with location mu, scale sigma and nu degrees of freedom
+func StudentTVaR(mu, sigma, nu, alpha float64) float64 {
+	return -(mu + sigma*distuv.StudentsT{Mu: 0, Sigma: 1, Nu: nu}.Quantile(alpha))
+}
+
+// NegativeStudentTVaR computes value at risk of minus a Student-t r.v., see StudentTVaR
+func NegativeStudentTVaR(mu, sigma, nu, alpha float64) float64 {
+	return mu + sigma*distuv.StudentsT{Mu: 0, Sigma: 1, Nu: nu}.Quantile(1.0-alpha)
+}
+
+// StudentTEs returns the expected shortfall of a Student-t r.v. at given lambda level, see StudentTVaR.
+// It is only finite for nu > 1.
+func StudentTEs(mu, sigma, nu, lambd float64) float64 {
+	return -mu + sigma*studentTTailMean(nu, lambd)
+}
+
+// NegativeStudentTEs returns the expected shortfall of minus a Student-t r.v. at given lambda level, see StudentTVaR.
+// It is only finite for nu > 1.
+func NegativeStudentTEs(mu, sigma, nu, lambd float64) float64 {
+	return mu + sigma*studentTTailMean(nu, lambd)
+}
+
+// studentTTailMean returns -E[T | T <= t] = (nu + t^2) / (nu - 1) f(t) / lambda for the standard Student-t r.v. T
+// with density f and lambda quantile t
+func studentTTailMean(nu, lambd float64) float64 {
+	standard := distuv.StudentsT{Mu: 0, Sigma: 1, Nu: nu}
+	var quantileForLambda = standard.Quantile(lambd)
+	return (nu + quantileForLambda*quantileForLambda) / (nu - 1) * standard.Prob(quantileForLambda) / lambd
+}
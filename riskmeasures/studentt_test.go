@@ -0,0 +1,70 @@
+package riskmeasures
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+func TestVaRAndESStudentTUsingMC(t *testing.T) {
+	const testToleranceForMC float64 = 3e-2 // relative, for both tails together
+	const numMCSamples int = 1000000
+
+	tables := []struct {
+		mu     float64
+		sigma  float64
+		nu     float64
+		lambda float64
+	}{
+		{0.0, 0.1, 3, 0.01},
+		{0.0, 1.0, 5, 0.05},
+		{1.0, 2.0, 10, 0.01},
+		{-1.0, 0.5, 2.5, 0.2},
+		{0.0, 0.01, 4, 0.005},
+	}
+
+	for _, table := range tables {
+		mu, sigma, nu, lambda := table.mu, table.sigma, table.nu, table.lambda
+
+		// use our own source so that the samples drawn by other tests don't change
+		dist := distuv.StudentsT{Mu: mu, Sigma: sigma, Nu: nu, Src: rand.New(rand.NewSource(1))}
+		X := make([]float64, numMCSamples)
+		negX := make([]float64, numMCSamples)
+		for i := range X {
+			X[i] = dist.Rand()
+			negX[i] = -X[i]
+		}
+		sort.Float64s(X)
+		sort.Float64s(negX)
+
+		errorVaR := math.Abs(EmpiricalVaR(X, lambda, true)-StudentTVaR(mu, sigma, nu, lambda)) +
+			math.Abs(EmpiricalVaR(negX, lambda, true)-NegativeStudentTVaR(mu, sigma, nu, lambda))
+		errorVaR /= math.Abs(StudentTVaR(0, sigma, nu, lambda))
+		if math.IsNaN(errorVaR) || math.IsInf(errorVaR, 0) || errorVaR > testToleranceForMC {
+			t.Errorf("VaR: mu=%g, sigma=%g, nu=%g, lambda=%g, error=%g greater than MC tolerance\n", mu, sigma, nu, lambda, errorVaR)
+		}
+
+		errorES := math.Abs(EmpiricalEs(X, lambda, true)-StudentTEs(mu, sigma, nu, lambda)) +
+			math.Abs(EmpiricalEs(negX, lambda, true)-NegativeStudentTEs(mu, sigma, nu, lambda))
+		errorES /= StudentTEs(0, sigma, nu, lambda)
+		if math.IsNaN(errorES) || math.IsInf(errorES, 0) || errorES > testToleranceForMC {
+			t.Errorf("ES: mu=%g, sigma=%g, nu=%g, lambda=%g, error=%g greater than MC tolerance\n", mu, sigma, nu, lambda, errorES)
+		}
+	}
+}
+
+// TestStudentTApproachesNormal checks that with many degrees of freedom the risk measures are the normal ones
+func TestStudentTApproachesNormal(t *testing.T) {
+	const tolerance float64 = 1e-4
+	for _, lambda := range []float64{0.001, 0.01, 0.1} {
+		error := math.Abs(StudentTVaR(0.1, 2, 1e7, lambda)-NormalVaR(0.1, 2, lambda)) +
+			math.Abs(StudentTEs(0.1, 2, 1e7, lambda)-NormalEs(0.1, 2, lambda)) +
+			math.Abs(NegativeStudentTEs(0.1, 2, 1e7, lambda)-NegativeNormalEs(0.1, 2, lambda))
+		if math.IsNaN(error) || error > tolerance {
+			t.Errorf("lambda=%g: error=%g\n", lambda, error)
+		}
+	}
+}
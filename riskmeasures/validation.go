@@ -0,0 +1,300 @@
+package riskmeasures
+
+import (
+	"math"
+
+	"code.vegaprotocol.io/quant/misc"
+)
+
+// The checked variants below return an error from the misc package instead of NaN or Inf when the
+// parameters are invalid: sigma must be positive and finite, mu finite and alpha (or lambda) strictly between 0 and 1.
+
+func validateParams(mu, sigma, p float64) error {
+	return misc.FirstError(
+		misc.ValidateFinite(mu, misc.ErrInvalidRate),
+		misc.ValidatePositive(sigma, misc.ErrInvalidSigma),
+		misc.ValidateProbability(p),
+	)
+}
+
+func validateStudentTParams(mu, sigma, nu, p float64) error {
+	if err := validateParams(mu, sigma, p); err != nil {
+		return err
+	}
+	if !(nu > 1) {
+		return misc.ErrInvalidDegreesOfFreedom
+	}
+	return nil
+}
+
+// checkedResult returns misc.ErrNotFinite when valid parameters still lead to an overflow
+func checkedResult(x float64) (float64, error) {
+	if err := misc.ValidateFinite(x, misc.ErrNotFinite); err != nil {
+		return 0, err
+	}
+	return x, nil
+}
+
+// NormalVaRChecked is NormalVaR with validated parameters
+func NormalVaRChecked(mu, sigma, alpha float64) (float64, error) {
+	if err := validateParams(mu, sigma, alpha); err != nil {
+		return 0, err
+	}
+	return checkedResult(NormalVaR(mu, sigma, alpha))
+}
+
+// NegativeNormalVaRChecked is NegativeNormalVaR with validated parameters
+func NegativeNormalVaRChecked(mu, sigma, alpha float64) (float64, error) {
+	if err := validateParams(mu, sigma, alpha); err != nil {
+		return 0, err
+	}
+	return checkedResult(NegativeNormalVaR(mu, sigma, alpha))
+}
+
+// NormalEsChecked is NormalEs with validated parameters
+func NormalEsChecked(mu, sigma, lambd float64) (float64, error) {
+	if err := validateParams(mu, sigma, lambd); err != nil {
+		return 0, err
+	}
+	return checkedResult(NormalEs(mu, sigma, lambd))
+}
+
+// NegativeNormalEsChecked is NegativeNormalEs with validated parameters
+func NegativeNormalEsChecked(mu, sigma, lambd float64) (float64, error) {
+	if err := validateParams(mu, sigma, lambd); err != nil {
+		return 0, err
+	}
+	return checkedResult(NegativeNormalEs(mu, sigma, lambd))
+}
+
+// LogNormalVaRChecked is LogNormalVaR with validated parameters
+func LogNormalVaRChecked(mu, sigma, alpha float64) (float64, error) {
+	if err := validateParams(mu, sigma, alpha); err != nil {
+		return 0, err
+	}
+	return checkedResult(LogNormalVaR(mu, sigma, alpha))
+}
+
+// NegativeLogNormalVaRChecked is NegativeLogNormalVaR with validated parameters
+func NegativeLogNormalVaRChecked(mu, sigma, alpha float64) (float64, error) {
+	if err := validateParams(mu, sigma, alpha); err != nil {
+		return 0, err
+	}
+	return checkedResult(NegativeLogNormalVaR(mu, sigma, alpha))
+}
+
+// LogNormalEsChecked is LogNormalEs with validated parameters
+func LogNormalEsChecked(mu, sigma, lambd float64) (float64, error) {
+	if err := validateParams(mu, sigma, lambd); err != nil {
+		return 0, err
+	}
+	return checkedResult(LogNormalEs(mu, sigma, lambd))
+}
+
+// NegativeLogNormalEsChecked is NegativeLogNormalEs with validated parameters
+func NegativeLogNormalEsChecked(mu, sigma, lambd float64) (float64, error) {
+	if err := validateParams(mu, sigma, lambd); err != nil {
+		return 0, err
+	}
+	return checkedResult(NegativeLogNormalEs(mu, sigma, lambd))
+}
+
+// StudentTVaRChecked is StudentTVaR with validated parameters, nu must also exceed 1
+func StudentTVaRChecked(mu, sigma, nu, alpha float64) (float64, error) {
+	if err := validateStudentTParams(mu, sigma, nu, alpha); err != nil {
+		return 0, err
+	}
+	return checkedResult(StudentTVaR(mu, sigma, nu, alpha))
+}
+
+// NegativeStudentTVaRChecked is NegativeStudentTVaR with validated parameters, nu must also exceed 1
+func NegativeStudentTVaRChecked(mu, sigma, nu, alpha float64) (float64, error) {
+	if err := validateStudentTParams(mu, sigma, nu, alpha); err != nil {
+		return 0, err
+	}
+	return checkedResult(NegativeStudentTVaR(mu, sigma, nu, alpha))
+}
+
+// StudentTEsChecked is StudentTEs with validated parameters, nu must also exceed 1
+func StudentTEsChecked(mu, sigma, nu, lambd float64) (float64, error) {
+	if err := validateStudentTParams(mu, sigma, nu, lambd); err != nil {
+		return 0, err
+	}
+	return checkedResult(StudentTEs(mu, sigma, nu, lambd))
+}
+
+// NegativeStudentTEsChecked is NegativeStudentTEs with validated parameters, nu must also exceed 1
+func NegativeStudentTEsChecked(mu, sigma, nu, lambd float64) (float64, error) {
+	if err := validateStudentTParams(mu, sigma, nu, lambd); err != nil {
+		return 0, err
+	}
+	return checkedResult(NegativeStudentTEs(mu, sigma, nu, lambd))
+}
+
+// mixtureWeightsTolerance is how far from 1 the sum of the mixture weights may be
+const mixtureWeightsTolerance = 1e-9
+
+// validateMixture checks the mixture weights and each component's parameters, and that 0 < p < 1
+func validateMixture(weights, mus, sigmas []float64, p float64) error {
+	if len(weights) == 0 || len(mus) != len(weights) || len(sigmas) != len(weights) {
+		return ErrInvalidMixture
+	}
+	var sum float64
+	for i, w := range weights {
+		if err := misc.FirstError(
+			misc.ValidateNonNegative(w, ErrInvalidMixture),
+			validateParams(mus[i], sigmas[i], p),
+		); err != nil {
+			return err
+		}
+		sum += w
+	}
+	if !(math.Abs(sum-1) <= mixtureWeightsTolerance) {
+		return ErrInvalidMixture
+	}
+	return nil
+}
+
+// LogNormalMixtureVaRChecked is LogNormalMixtureVaR with validated parameters
+func LogNormalMixtureVaRChecked(weights, mus, sigmas []float64, alpha float64) (float64, error) {
+	if err := validateMixture(weights, mus, sigmas, alpha); err != nil {
+		return 0, err
+	}
+	return checkedResult(LogNormalMixtureVaR(weights, mus, sigmas, alpha))
+}
+
+// NegativeLogNormalMixtureVaRChecked is NegativeLogNormalMixtureVaR with validated parameters
+func NegativeLogNormalMixtureVaRChecked(weights, mus, sigmas []float64, alpha float64) (float64, error) {
+	if err := validateMixture(weights, mus, sigmas, alpha); err != nil {
+		return 0, err
+	}
+	return checkedResult(NegativeLogNormalMixtureVaR(weights, mus, sigmas, alpha))
+}
+
+// LogNormalMixtureEsChecked is LogNormalMixtureEs with validated parameters
+func LogNormalMixtureEsChecked(weights, mus, sigmas []float64, lambd float64) (float64, error) {
+	if err := validateMixture(weights, mus, sigmas, lambd); err != nil {
+		return 0, err
+	}
+	return checkedResult(LogNormalMixtureEs(weights, mus, sigmas, lambd))
+}
+
+// NegativeLogNormalMixtureEsChecked is NegativeLogNormalMixtureEs with validated parameters
+func NegativeLogNormalMixtureEsChecked(weights, mus, sigmas []float64, lambd float64) (float64, error) {
+	if err := validateMixture(weights, mus, sigmas, lambd); err != nil {
+		return 0, err
+	}
+	return checkedResult(NegativeLogNormalMixtureEs(weights, mus, sigmas, lambd))
+}
+
+// validateSample checks that x is a non-empty sample without NaN and that 0 < p < 1
+func validateSample(x []float64, p float64) error {
+	if len(x) == 0 {
+		return misc.ErrEmptySample
+	}
+	for _, xi := range x {
+		if math.IsNaN(xi) {
+			return misc.ErrInvalidSample
+		}
+	}
+	return misc.ValidateProbability(p)
+}
+
+// EmpiricalVaRChecked is EmpiricalVaR for a validated sample and probability level
+func EmpiricalVaRChecked(x []float64, alpha float64, isSorted bool) (float64, error) {
+	if err := validateSample(x, alpha); err != nil {
+		return 0, err
+	}
+	return EmpiricalVaR(x, alpha, isSorted), nil
+}
+
+// EmpiricalEsChecked is EmpiricalEs for a validated sample and probability level
+func EmpiricalEsChecked(x []float64, lambda float64, isSorted bool) (float64, error) {
+	if err := validateSample(x, lambda); err != nil {
+		return 0, err
+	}
+	return EmpiricalEs(x, lambda, isSorted), nil
+}
+
+// validateWeights checks there is a non-negative finite weight per sample with a positive sum
+func validateWeights(x, weights []float64) error {
+	if len(weights) != len(x) {
+		return ErrInvalidWeights
+	}
+	var sum float64
+	for _, w := range weights {
+		if err := misc.ValidateNonNegative(w, ErrInvalidWeights); err != nil {
+			return err
+		}
+		sum += w
+	}
+	return misc.ValidatePositive(sum, ErrInvalidWeights)
+}
+
+// WeightedEmpiricalVaRChecked is WeightedEmpiricalVaR for a validated sample, weights and probability level
+func WeightedEmpiricalVaRChecked(x, weights []float64, alpha float64, isSorted bool) (float64, error) {
+	if err := misc.FirstError(validateSample(x, alpha), validateWeights(x, weights)); err != nil {
+		return 0, err
+	}
+	return WeightedEmpiricalVaR(x, weights, alpha, isSorted), nil
+}
+
+// WeightedEmpiricalEsChecked is WeightedEmpiricalEs for a validated sample, weights and probability level
+func WeightedEmpiricalEsChecked(x, weights []float64, lambda float64, isSorted bool) (float64, error) {
+	if err := misc.FirstError(validateSample(x, lambda), validateWeights(x, weights)); err != nil {
+		return 0, err
+	}
+	return WeightedEmpiricalEs(x, weights, lambda, isSorted), nil
+}
+
+func validateDecay(decay float64) error {
+	if !(decay > 0) || !(decay <= 1) {
+		return ErrInvalidDecay
+	}
+	return nil
+}
+
+// AgeWeightedEmpiricalVaRChecked is AgeWeightedEmpiricalVaR for a validated sample, decay and probability level
+func AgeWeightedEmpiricalVaRChecked(x []float64, decay, alpha float64) (float64, error) {
+	if err := misc.FirstError(validateSample(x, alpha), validateDecay(decay)); err != nil {
+		return 0, err
+	}
+	return AgeWeightedEmpiricalVaR(x, decay, alpha), nil
+}
+
+// AgeWeightedEmpiricalEsChecked is AgeWeightedEmpiricalEs for a validated sample, decay and probability level
+func AgeWeightedEmpiricalEsChecked(x []float64, decay, lambda float64) (float64, error) {
+	if err := misc.FirstError(validateSample(x, lambda), validateDecay(decay)); err != nil {
+		return 0, err
+	}
+	return AgeWeightedEmpiricalEs(x, decay, lambda), nil
+}
+
+// validateVolatilities checks there is a positive finite volatility per return and that the current one is too
+func validateVolatilities(returns, vols []float64, currentVol float64) error {
+	if len(vols) != len(returns) {
+		return misc.ErrInvalidSigma
+	}
+	for _, v := range vols {
+		if err := misc.ValidatePositive(v, misc.ErrInvalidSigma); err != nil {
+			return err
+		}
+	}
+	return misc.ValidatePositive(currentVol, misc.ErrInvalidSigma)
+}
+
+// VolatilityScaledEmpiricalVaRChecked is VolatilityScaledEmpiricalVaR for validated returns, volatilities and probability level
+func VolatilityScaledEmpiricalVaRChecked(returns, vols []float64, currentVol, alpha float64) (float64, error) {
+	if err := misc.FirstError(validateSample(returns, alpha), validateVolatilities(returns, vols, currentVol)); err != nil {
+		return 0, err
+	}
+	return VolatilityScaledEmpiricalVaR(returns, vols, currentVol, alpha), nil
+}
+
+// VolatilityScaledEmpiricalEsChecked is VolatilityScaledEmpiricalEs for validated returns, volatilities and probability level
+func VolatilityScaledEmpiricalEsChecked(returns, vols []float64, currentVol, lambda float64) (float64, error) {
+	if err := misc.FirstError(validateSample(returns, lambda), validateVolatilities(returns, vols, currentVol)); err != nil {
+		return 0, err
+	}
+	return VolatilityScaledEmpiricalEs(returns, vols, currentVol, lambda), nil
+}
@@ -0,0 +1,123 @@
+package riskmeasures
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/misc"
+)
+
+func TestCheckedParametricRiskMeasures(t *testing.T) {
+	const mu, sigma, nu, p = 0.01, 0.2, 4.0, 0.01
+
+	tables := []struct {
+		mu, sigma, nu, p float64
+		err              error
+	}{
+		{mu, sigma, nu, p, nil},
+		{math.NaN(), sigma, nu, p, misc.ErrInvalidRate},
+		{mu, 0, nu, p, misc.ErrInvalidSigma},
+		{mu, -sigma, nu, p, misc.ErrInvalidSigma},
+		{mu, math.Inf(1), nu, p, misc.ErrInvalidSigma},
+		{mu, sigma, nu, 0, misc.ErrProbabilityOutOfRange},
+		{mu, sigma, nu, 1, misc.ErrProbabilityOutOfRange},
+		{mu, sigma, nu, math.NaN(), misc.ErrProbabilityOutOfRange},
+	}
+
+	for i, table := range tables {
+		checks := []func(mu, sigma, p float64) (float64, error){
+			NormalVaRChecked, NegativeNormalVaRChecked, NormalEsChecked, NegativeNormalEsChecked,
+			LogNormalVaRChecked, NegativeLogNormalVaRChecked, LogNormalEsChecked, NegativeLogNormalEsChecked,
+			func(mu, sigma, p float64) (float64, error) { return StudentTVaRChecked(mu, sigma, table.nu, p) },
+			func(mu, sigma, p float64) (float64, error) { return NegativeStudentTVaRChecked(mu, sigma, table.nu, p) },
+			func(mu, sigma, p float64) (float64, error) { return StudentTEsChecked(mu, sigma, table.nu, p) },
+			func(mu, sigma, p float64) (float64, error) { return NegativeStudentTEsChecked(mu, sigma, table.nu, p) },
+		}
+		for j, check := range checks {
+			value, err := check(table.mu, table.sigma, table.p)
+			if err != table.err {
+				t.Errorf("case %d, function %d: expected error %v, got %v\n", i, j, table.err, err)
+			}
+			if err == nil && (math.IsNaN(value) || math.IsInf(value, 0)) {
+				t.Errorf("case %d, function %d: value=%g\n", i, j, value)
+			}
+		}
+	}
+
+	if _, err := StudentTEsChecked(mu, sigma, 1, p); err != misc.ErrInvalidDegreesOfFreedom {
+		t.Errorf("expected error %v, got %v\n", misc.ErrInvalidDegreesOfFreedom, err)
+	}
+	if _, err := LogNormalEsChecked(1000, sigma, p); err != misc.ErrNotFinite {
+		t.Errorf("expected error %v, got %v\n", misc.ErrNotFinite, err)
+	}
+	if es, err := LogNormalEsChecked(mu, sigma, p); err != nil || es != LogNormalEs(mu, sigma, p) {
+		t.Errorf("expected %g, got %g and error %v\n", LogNormalEs(mu, sigma, p), es, err)
+	}
+}
+
+func TestCheckedLogNormalMixtureRiskMeasures(t *testing.T) {
+	weights, mus, sigmas := []float64{0.3, 0.7}, []float64{-0.1, 0.05}, []float64{0.4, 0.2}
+
+	tables := []struct {
+		weights, mus, sigmas []float64
+		p                    float64
+		err                  error
+	}{
+		{weights, mus, sigmas, 0.01, nil},
+		{nil, nil, nil, 0.01, ErrInvalidMixture},
+		{weights, mus[:1], sigmas, 0.01, ErrInvalidMixture},
+		{[]float64{0.3, 0.6}, mus, sigmas, 0.01, ErrInvalidMixture},
+		{[]float64{-0.3, 1.3}, mus, sigmas, 0.01, ErrInvalidMixture},
+		{weights, []float64{-0.1, math.Inf(1)}, sigmas, 0.01, misc.ErrInvalidRate},
+		{weights, mus, []float64{0.4, 0}, 0.01, misc.ErrInvalidSigma},
+		{weights, mus, sigmas, 1, misc.ErrProbabilityOutOfRange},
+	}
+
+	for i, table := range tables {
+		checks := []func(weights, mus, sigmas []float64, p float64) (float64, error){
+			LogNormalMixtureVaRChecked, NegativeLogNormalMixtureVaRChecked,
+			LogNormalMixtureEsChecked, NegativeLogNormalMixtureEsChecked,
+		}
+		for j, check := range checks {
+			value, err := check(table.weights, table.mus, table.sigmas, table.p)
+			if err != table.err {
+				t.Errorf("case %d, function %d: expected error %v, got %v\n", i, j, table.err, err)
+			}
+			if err == nil && (math.IsNaN(value) || math.IsInf(value, 0)) {
+				t.Errorf("case %d, function %d: value=%g\n", i, j, value)
+			}
+		}
+	}
+
+	if es, err := LogNormalMixtureEsChecked(weights, mus, sigmas, 0.01); err != nil || es != LogNormalMixtureEs(weights, mus, sigmas, 0.01) {
+		t.Errorf("expected %g, got %g and error %v\n", LogNormalMixtureEs(weights, mus, sigmas, 0.01), es, err)
+	}
+}
+
+func TestCheckedEmpiricalRiskMeasures(t *testing.T) {
+	x := []float64{-2, -1, 0, 1, 2, 3, 4, 5, 6, 7}
+
+	tables := []struct {
+		x   []float64
+		p   float64
+		err error
+	}{
+		{x, 0.1, nil},
+		{nil, 0.1, misc.ErrEmptySample},
+		{[]float64{1, math.NaN()}, 0.1, misc.ErrInvalidSample},
+		{x, 1.5, misc.ErrProbabilityOutOfRange},
+	}
+
+	for i, table := range tables {
+		if _, err := EmpiricalVaRChecked(table.x, table.p, false); err != table.err {
+			t.Errorf("case %d: expected VaR error %v, got %v\n", i, table.err, err)
+		}
+		if _, err := EmpiricalEsChecked(table.x, table.p, false); err != table.err {
+			t.Errorf("case %d: expected ES error %v, got %v\n", i, table.err, err)
+		}
+	}
+
+	if es, err := EmpiricalEsChecked(x, 0.2, true); err != nil || es != EmpiricalEs(x, 0.2, true) {
+		t.Errorf("expected %g, got %g and error %v\n", EmpiricalEs(x, 0.2, true), es, err)
+	}
+}
@@ -0,0 +1,135 @@
+package riskmeasures
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+var (
+	// ErrInvalidWeights is returned when the weights aren't one non-negative finite number per sample with a positive sum
+	ErrInvalidWeights = errors.New("weights must be non-negative and finite, one per sample and with a positive sum")
+	// ErrInvalidDecay is returned when the age weighting decay isn't in (0, 1]
+	ErrInvalidDecay = errors.New("decay must be in (0, 1]")
+)
+
+// WeightedEmpiricalVaR calculates the empirical value at risk of samples x where the i-th sample has probability
+// proportional to weights[i], the weights needn't sum to 1. Unless isSorted it sorts x and weights together in place.
+// With equal weights it is EmpiricalVaR.
+func WeightedEmpiricalVaR(x, weights []float64, alpha float64, isSorted bool) float64 {
+	if !isSorted {
+		sort.Sort(weightedSamples{x, weights})
+	}
+	return -stat.Quantile(alpha, stat.Empirical, x, relativeWeights(weights))
+}
+
+// WeightedEmpiricalEs calculates the empirical expected shortfall of samples x where the i-th sample has probability
+// proportional to weights[i], see WeightedEmpiricalVaR. With equal weights it is EmpiricalEs.
+func WeightedEmpiricalEs(x, weights []float64, lambda float64, isSorted bool) float64 {
+	empVar := WeightedEmpiricalVaR(x, weights, lambda, isSorted)
+	weights = relativeWeights(weights)
+
+	var totalWeight, weightLessThanMinusVar, sumSamplesLessThanMinusVar float64
+	for i, xi := range x {
+		totalWeight += weights[i]
+		if xi <= -empVar {
+			weightLessThanMinusVar += weights[i]
+			sumSamplesLessThanMinusVar += weights[i] * xi
+		}
+	}
+	// as in EmpiricalEs only the fraction lambda - P(X < -VaR) of the atom at -VaR is in the tail
+	pXleqMinusVar := weightLessThanMinusVar / totalWeight
+	return (-1.0 / lambda) * (sumSamplesLessThanMinusVar/totalWeight + empVar*(pXleqMinusVar-lambda))
+}
+
+// AgeWeights returns the Boudoukh, Richardson and Whitelaw (The best of both worlds, Risk, 1998) weights of n samples
+// ordered from the oldest to the most recent, the weight of each sample is decay times that of the next one and
+// they sum to 1, so that a decay of 1 gives equal weights
+func AgeWeights(n int, decay float64) []float64 {
+	weights := make([]float64, n)
+	var sum float64
+	w := 1.0
+	for i := n - 1; i >= 0; i-- {
+		weights[i] = w
+		sum += w
+		w *= decay
+	}
+	for i := range weights {
+		weights[i] /= sum
+	}
+	return weights
+}
+
+// AgeWeightedEmpiricalVaR calculates the empirical value at risk of samples x ordered from the oldest to the most
+// recent with AgeWeights, x isn't modified
+func AgeWeightedEmpiricalVaR(x []float64, decay, alpha float64) float64 {
+	samples, weights := ageWeightedSamples(x, decay)
+	return WeightedEmpiricalVaR(samples, weights, alpha, false)
+}
+
+// AgeWeightedEmpiricalEs calculates the empirical expected shortfall of samples x ordered from the oldest to the most
+// recent with AgeWeights, x isn't modified
+func AgeWeightedEmpiricalEs(x []float64, decay, lambda float64) float64 {
+	samples, weights := ageWeightedSamples(x, decay)
+	return WeightedEmpiricalEs(samples, weights, lambda, false)
+}
+
+// VolatilityScaled returns the returns rescaled to the current volatility as in Hull and White (Incorporating
+// volatility updating into the historical simulation method for value-at-risk, Journal of Risk, 1998), i.e. the
+// i-th return times currentVol / vols[i] where vols[i] is the volatility estimate at the time of the i-th return.
+// With a GARCH or EWMA volatility series this is filtered historical simulation.
+func VolatilityScaled(returns, vols []float64, currentVol float64) []float64 {
+	scaled := make([]float64, len(returns))
+	for i, r := range returns {
+		scaled[i] = r * (currentVol / vols[i])
+	}
+	return scaled
+}
+
+// VolatilityScaledEmpiricalVaR calculates the empirical value at risk of the VolatilityScaled returns,
+// the returns aren't modified
+func VolatilityScaledEmpiricalVaR(returns, vols []float64, currentVol, alpha float64) float64 {
+	return EmpiricalVaR(VolatilityScaled(returns, vols, currentVol), alpha, false)
+}
+
+// VolatilityScaledEmpiricalEs calculates the empirical expected shortfall of the VolatilityScaled returns,
+// the returns aren't modified
+func VolatilityScaledEmpiricalEs(returns, vols []float64, currentVol, lambda float64) float64 {
+	return EmpiricalEs(VolatilityScaled(returns, vols, currentVol), lambda, false)
+}
+
+// ageWeightedSamples returns a copy of x and its AgeWeights
+func ageWeightedSamples(x []float64, decay float64) ([]float64, []float64) {
+	samples := make([]float64, len(x))
+	copy(samples, x)
+	return samples, AgeWeights(len(x), decay)
+}
+
+// relativeWeights returns the weights divided by the largest one, so that equal weights are exactly 1 and the sums
+// of the weights are the sample counts of EmpiricalVaR and EmpiricalEs without rounding, e.g. in the quantile index
+func relativeWeights(weights []float64) []float64 {
+	var largest float64
+	for _, w := range weights {
+		largest = math.Max(largest, w)
+	}
+	relative := make([]float64, len(weights))
+	for i, w := range weights {
+		relative[i] = w / largest
+	}
+	return relative
+}
+
+// weightedSamples sorts samples together with their weights
+type weightedSamples struct {
+	x       []float64
+	weights []float64
+}
+
+func (s weightedSamples) Len() int           { return len(s.x) }
+func (s weightedSamples) Less(i, j int) bool { return s.x[i] < s.x[j] }
+func (s weightedSamples) Swap(i, j int) {
+	s.x[i], s.x[j] = s.x[j], s.x[i]
+	s.weights[i], s.weights[j] = s.weights[j], s.weights[i]
+}
@@ -0,0 +1,164 @@
+package riskmeasures
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/misc"
+
+	"golang.org/x/exp/rand"
+)
+
+// TestWeightedEmpiricalUniformIsEmpirical checks the weighted risk measures with equal weights are exactly the
+// unweighted ones, including when lambda n is an integer so that rounding the cumulative weights would pick
+// the neighbouring sample
+func TestWeightedEmpiricalUniformIsEmpirical(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	copyOf := func(y []float64) []float64 {
+		z := make([]float64, len(y))
+		copy(z, y)
+		return z
+	}
+
+	for _, n := range []int{1000, 1001, 2000, 10000} {
+		x := make([]float64, n)
+		for i := range x {
+			x[i] = rng.NormFloat64()
+		}
+		vols := make([]float64, len(x))
+		for i := range vols {
+			vols[i] = 0.3
+		}
+
+		for _, p := range []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.5} {
+			expectedVaR, expectedEs := EmpiricalVaR(copyOf(x), p, false), EmpiricalEs(copyOf(x), p, false)
+			for _, weights := range [][]float64{AgeWeights(len(x), 1), vols} {
+				v := WeightedEmpiricalVaR(copyOf(x), copyOf(weights), p, false)
+				es := WeightedEmpiricalEs(copyOf(x), copyOf(weights), p, false)
+				if v != expectedVaR || es != expectedEs {
+					t.Errorf("n=%d, p=%g: weighted VaR=%g and ES=%g, expected %g and %g\n", n, p, v, es, expectedVaR, expectedEs)
+				}
+			}
+			if v, es := AgeWeightedEmpiricalVaR(x, 1, p), AgeWeightedEmpiricalEs(x, 1, p); v != expectedVaR || es != expectedEs {
+				t.Errorf("n=%d, p=%g: age weighted VaR=%g and ES=%g, expected %g and %g\n", n, p, v, es, expectedVaR, expectedEs)
+			}
+			if v, es := VolatilityScaledEmpiricalVaR(x, vols, 0.3, p), VolatilityScaledEmpiricalEs(x, vols, 0.3, p); v != expectedVaR || es != expectedEs {
+				t.Errorf("n=%d, p=%g: volatility scaled VaR=%g and ES=%g, expected %g and %g\n", n, p, v, es, expectedVaR, expectedEs)
+			}
+		}
+	}
+}
+
+// TestWeightedEmpiricalIntegerWeights checks integer weights are the same as repeating the samples
+func TestWeightedEmpiricalIntegerWeights(t *testing.T) {
+	const tolerance float64 = 1e-12
+	rng := rand.New(rand.NewSource(1))
+	x := make([]float64, 200)
+	weights := make([]float64, len(x))
+	var repeated []float64
+	for i := range x {
+		x[i] = rng.NormFloat64()
+		weights[i] = float64(1 + rng.Intn(4))
+		for j := 0; j < int(weights[i]); j++ {
+			repeated = append(repeated, x[i])
+		}
+	}
+
+	for _, p := range []float64{0.01, 0.05, 0.1, 0.3} {
+		error := math.Abs(WeightedEmpiricalVaR(x, weights, p, false)-EmpiricalVaR(repeated, p, false)) +
+			math.Abs(WeightedEmpiricalEs(x, weights, p, true)-EmpiricalEs(repeated, p, true))
+		if math.IsNaN(error) || error > tolerance {
+			t.Errorf("p=%g: error=%g\n", p, error)
+		}
+	}
+}
+
+func TestAgeWeights(t *testing.T) {
+	const tolerance float64 = 1e-14
+	weights := AgeWeights(250, 0.98)
+	var sum float64
+	for i, w := range weights {
+		sum += w
+		if i > 0 && math.Abs(weights[i-1]/w-0.98) > tolerance {
+			t.Errorf("weight %d=%g is not the decay times weight %d=%g\n", i-1, weights[i-1], i, w)
+		}
+	}
+	if error := math.Abs(sum - 1); error > tolerance {
+		t.Errorf("weights sum to %g\n", sum)
+	}
+}
+
+// TestRiskMeasuresAfterVolatilityRegimeShift checks that age weighting and volatility scaling react to a recent
+// increase in volatility, the latter recovering the risk measures of the current volatility
+func TestRiskMeasuresAfterVolatilityRegimeShift(t *testing.T) {
+	const testToleranceForMC float64 = 5e-2 // relative
+	const numSamples, numRecent int = 100000, 1000
+	const lowVol, highVol, lambda float64 = 0.01, 0.03, 0.01
+
+	rng := rand.New(rand.NewSource(1))
+	returns := make([]float64, numSamples)
+	vols := make([]float64, numSamples)
+	for i := range returns {
+		vols[i] = lowVol
+		if i >= numSamples-numRecent {
+			vols[i] = highVol
+		}
+		returns[i] = vols[i] * rng.NormFloat64()
+	}
+
+	equalEs := EmpiricalEs(append([]float64{}, returns...), lambda, false)
+	if ageWeightedEs := AgeWeightedEmpiricalEs(returns, 0.999, lambda); !(ageWeightedEs > 1.5*equalEs) {
+		t.Errorf("age weighted ES=%g doesn't react to the regime shift, equally weighted ES=%g\n", ageWeightedEs, equalEs)
+	}
+
+	scaledVaR := VolatilityScaledEmpiricalVaR(returns, vols, highVol, lambda)
+	scaledEs := VolatilityScaledEmpiricalEs(returns, vols, highVol, lambda)
+	errorVaR := math.Abs(scaledVaR/NormalVaR(0, highVol, lambda) - 1)
+	errorEs := math.Abs(scaledEs/NormalEs(0, highVol, lambda) - 1)
+	if math.IsNaN(errorVaR+errorEs) || errorVaR > testToleranceForMC || errorEs > testToleranceForMC {
+		t.Errorf("volatility scaled VaR=%g and ES=%g, expected %g and %g\n", scaledVaR, scaledEs,
+			NormalVaR(0, highVol, lambda), NormalEs(0, highVol, lambda))
+	}
+}
+
+func TestCheckedWeightedEmpiricalRiskMeasures(t *testing.T) {
+	x := []float64{-1, 0.5, 2, -0.3}
+	tables := []struct {
+		weights []float64
+		decay   float64
+		vols    []float64
+		p       float64
+		err     error
+	}{
+		{[]float64{1, 1, 1}, 0.9, []float64{1, 1, 1, 1}, 0.1, ErrInvalidWeights},
+		{[]float64{1, -1, 1, 1}, 0.9, []float64{1, 1, 1, 1}, 0.1, ErrInvalidWeights},
+		{[]float64{0, 0, 0, 0}, 0.9, []float64{1, 1, 1, 1}, 0.1, ErrInvalidWeights},
+		{[]float64{1, math.NaN(), 1, 1}, 0.9, []float64{1, 1, 1, 1}, 0.1, ErrInvalidWeights},
+		{[]float64{1, 2, 3, 4}, 0.9, []float64{1, 1, 1, 1}, 1, misc.ErrProbabilityOutOfRange},
+	}
+	for i, table := range tables {
+		if _, err := WeightedEmpiricalVaRChecked(x, table.weights, table.p, false); err != table.err {
+			t.Errorf("case %d: expected %v, got %v\n", i, table.err, err)
+		}
+		if _, err := WeightedEmpiricalEsChecked(x, table.weights, table.p, false); err != table.err {
+			t.Errorf("case %d: expected %v, got %v\n", i, table.err, err)
+		}
+	}
+
+	for _, decay := range []float64{0, 1.01, math.NaN()} {
+		if _, err := AgeWeightedEmpiricalEsChecked(x, decay, 0.1); err != ErrInvalidDecay {
+			t.Errorf("decay=%g: expected %v, got %v\n", decay, ErrInvalidDecay, err)
+		}
+	}
+	for _, vols := range [][]float64{{1, 1, 1}, {1, 0, 1, 1}, {1, 1, math.Inf(1), 1}} {
+		if _, err := VolatilityScaledEmpiricalVaRChecked(x, vols, 1, 0.1); err != misc.ErrInvalidSigma {
+			t.Errorf("vols=%v: expected %v, got %v\n", vols, misc.ErrInvalidSigma, err)
+		}
+	}
+	if _, err := VolatilityScaledEmpiricalEsChecked(x, []float64{1, 1, 1, 1}, -1, 0.1); err != misc.ErrInvalidSigma {
+		t.Errorf("expected %v, got %v\n", misc.ErrInvalidSigma, err)
+	}
+	if es, err := AgeWeightedEmpiricalEsChecked(x, 0.5, 0.3); err != nil || es != AgeWeightedEmpiricalEs(x, 0.5, 0.3) {
+		t.Errorf("expected %g, got %g and error %v\n", AgeWeightedEmpiricalEs(x, 0.5, 0.3), es, err)
+	}
+}
@@ -0,0 +1,83 @@
+package riskmodel
+
+import (
+	"errors"
+
+	"code.vegaprotocol.io/quant/interfaces"
+	"code.vegaprotocol.io/quant/misc"
+	"code.vegaprotocol.io/quant/riskmeasures"
+	"code.vegaprotocol.io/quant/riskmodelbs"
+)
+
+var (
+	// ErrLambdaOutOfRange is returned when the expected shortfall level lambda is outside of the range
+	// [alphaModel, 1 - alphaModel] the model supports, see interfaces.AnalyticalModel.GetProbabilityTolerance
+	ErrLambdaOutOfRange = errors.New("lambda is outside of the probability range supported by the model")
+	// ErrInvalidPrice is returned when the current price of the risky asset isn't positive and finite,
+	// it is misc.ErrInvalidPrice
+	ErrInvalidPrice = misc.ErrInvalidPrice
+	// ErrInvalidHorizon is returned when the risk horizon tau isn't positive and finite, it is misc.ErrInvalidHorizon
+	ErrInvalidHorizon = misc.ErrInvalidHorizon
+	// ErrInaccurateRiskFactors is returned when the error estimate of a numerically integrated expected shortfall
+	// exceeds the tolerance, e.g. for a distribution whose quantiles can't be evaluated far enough in the tails
+	ErrInaccurateRiskFactors = errors.New("risk factors can't be integrated to the required accuracy")
+)
+
+// riskFactorTolerance is the largest integration error estimate of the risk factors accepted by RiskFactors
+const riskFactorTolerance = 1e-6
+
+// RiskFactors calculates the risk factors for any model of the evolution of the risky asset from the distribution
+// of its price over the horizon tau given the current price S, so that the margin of a long position covers the expected
+// price decrease within the lambda lower tail and the margin of a short position the expected increase within the upper tail:
+// Long = (S - E[S_tau | S_tau <= q_lambda]) / S and Short = (E[S_tau | S_tau >= q_{1-lambda}] - S) / S.
+// The expected shortfalls are integrated numerically with riskmeasures.DistributionEs and riskmeasures.NegativeDistributionEs,
+// for models with closed form expressions (e.g. riskmodelbs.RiskFactorsForward) this gives the same risk factors.
+// ErrInaccurateRiskFactors is returned when the integration error estimate of either risk factor exceeds 1e-6.
+func RiskFactors(model interfaces.AnalyticalModel, lambd, tau, S float64) (riskmodelbs.RiskFactors, error) {
+	alphaModel := model.GetProbabilityTolerance()
+	if !(lambd >= alphaModel) || !(lambd <= 1-alphaModel) {
+		return riskmodelbs.RiskFactors{}, ErrLambdaOutOfRange
+	}
+	if err := misc.FirstError(
+		misc.ValidatePositive(S, ErrInvalidPrice),
+		misc.ValidatePositive(tau, ErrInvalidHorizon),
+	); err != nil {
+		return riskmodelbs.RiskFactors{}, err
+	}
+	distribution := model.GetProbabilityDistribution(S, tau)
+
+	es, errEstimate := riskmeasures.DistributionEs(distribution, lambd)
+	riskFactorLong := es/S + 1.0
+
+	negEs, negErrEstimate := riskmeasures.NegativeDistributionEs(distribution, lambd)
+	riskFactorShort := negEs/S - 1.0
+
+	if !(errEstimate/S <= riskFactorTolerance) || !(negErrEstimate/S <= riskFactorTolerance) {
+		return riskmodelbs.RiskFactors{}, ErrInaccurateRiskFactors
+	}
+
+	factors := riskmodelbs.RiskFactors{Long: riskFactorLong, Short: riskFactorShort}
+	return factors, nil
+}
+
+// RiskFactorsCall calculates the risk factors for CALL option with the given delta (from any option pricing model)
+// by scaling the risk factors of the risky asset from RiskFactors
+func RiskFactorsCall(model interfaces.AnalyticalModel, lambd, tau, S, callDelta float64) (riskmodelbs.RiskFactors, error) {
+	factors, err := RiskFactors(model, lambd, tau, S)
+	if err != nil {
+		return riskmodelbs.RiskFactors{}, err
+	}
+	return riskmodelbs.RiskFactors{Long: callDelta * factors.Long, Short: callDelta * factors.Short}, nil
+}
+
+// RiskFactorsPut calculates the risk factors for PUT option with the given delta (from any option pricing model)
+// by scaling the risk factors of the risky asset from RiskFactors, a long put loses when the risky asset goes up
+// so its risk factor is scaled from the short one and vice versa
+func RiskFactorsPut(model interfaces.AnalyticalModel, lambd, tau, S, putDelta float64) (riskmodelbs.RiskFactors, error) {
+	factors, err := RiskFactors(model, lambd, tau, S)
+	if err != nil {
+		return riskmodelbs.RiskFactors{}, err
+	}
+	minusPutDelta := -putDelta
+	return riskmodelbs.RiskFactors{Long: minusPutDelta * factors.Short, Short: minusPutDelta * factors.Long}, nil
+}
@@ -0,0 +1,116 @@
+package riskmodel
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/bsformula"
+	"code.vegaprotocol.io/quant/interfaces"
+	"code.vegaprotocol.io/quant/riskmodelbs"
+	"code.vegaprotocol.io/quant/riskmodelmerton"
+	"code.vegaprotocol.io/quant/riskmodelnormal"
+)
+
+const testTolerance float64 = 1e-8
+
+func TestRiskFactorsMatchClosedForms(t *testing.T) {
+	const S, tau = 100.0, 1.0 / 365.25
+
+	bs := riskmodelbs.ModelParamsBS{Mu: 0.05, R: 0.01, Sigma: 0.8}
+	merton := riskmodelmerton.ModelParamsMerton{Mu: 0.05, R: 0.01, Sigma: 0.5, Lambda: 10, JumpMean: -0.05, JumpStd: 0.1}
+	normal := riskmodelnormal.ModelParamsNormal{Mu: 1, R: 0.01, Sigma: 30}
+
+	for _, lambda := range []float64{0.001, 0.01, 0.1, 0.5} {
+		tables := []struct {
+			name     string
+			model    interfaces.AnalyticalModel
+			expected riskmodelbs.RiskFactors
+		}{
+			{"black-scholes", bs, riskmodelbs.RiskFactorsForward(lambda, tau, bs)},
+			{"merton", merton, riskmodelmerton.RiskFactorsForward(lambda, tau, merton)},
+			{"normal", normal, riskmodelnormal.RiskFactorsForward(lambda, tau, S, normal)},
+		}
+
+		for _, table := range tables {
+			riskFactors, err := RiskFactors(table.model, lambda, tau, S)
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+			error := math.Abs(riskFactors.Long-table.expected.Long) + math.Abs(riskFactors.Short-table.expected.Short)
+			if math.IsNaN(error) || math.IsInf(error, 0) || error > testTolerance {
+				t.Errorf("%s, lambda=%g: risk factors %+v, expected %+v\n", table.name, lambda, riskFactors, table.expected)
+			}
+		}
+	}
+}
+
+func TestOptionRiskFactorsMatchBlackScholes(t *testing.T) {
+	const S, lambda, tau = 100.0, 0.01, 1.0 / 365.25
+
+	p := riskmodelbs.ModelParamsBS{Mu: 0.05, R: 0.01, Sigma: 0.8}
+	for _, K := range []float64{50, 100, 150} {
+		for _, T := range []float64{1.0 / 52, 0.25, 1} {
+			callDelta := bsformula.Fast.CallDelta(S, K, p.R, p.Q, p.Sigma, T)
+			putDelta := bsformula.Fast.PutDelta(S, K, p.R, p.Q, p.Sigma, T)
+
+			call, err := RiskFactorsCall(p, lambda, tau, S, callDelta)
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+			put, err := RiskFactorsPut(p, lambda, tau, S, putDelta)
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+			expectedCall := riskmodelbs.RiskFactorsCall(lambda, tau, S, K, T, p)
+			expectedPut := riskmodelbs.RiskFactorsPut(lambda, tau, S, K, T, p)
+
+			error := math.Abs(call.Long-expectedCall.Long) + math.Abs(call.Short-expectedCall.Short) +
+				math.Abs(put.Long-expectedPut.Long) + math.Abs(put.Short-expectedPut.Short)
+			if math.IsNaN(error) || math.IsInf(error, 0) || error > testTolerance {
+				t.Errorf("K=%g, T=%g: call %+v, expected %+v, put %+v, expected %+v\n", K, T, call, expectedCall, put, expectedPut)
+			}
+		}
+	}
+}
+
+func TestRiskFactorsErrors(t *testing.T) {
+	p := riskmodelbs.ModelParamsBS{Mu: 0.05, R: 0.01, Sigma: 0.8}
+	alphaModel := p.GetProbabilityTolerance()
+
+	const tau = 1.0 / 365.25
+
+	tables := []struct {
+		lambda float64
+		tau    float64
+		S      float64
+		err    error
+	}{
+		{alphaModel, tau, 100, nil},
+		{1 - alphaModel, tau, 100, nil},
+		{0.5 * alphaModel, tau, 100, ErrLambdaOutOfRange},
+		{1 - 0.5*alphaModel, tau, 100, ErrLambdaOutOfRange},
+		{math.NaN(), tau, 100, ErrLambdaOutOfRange},
+		{0.01, tau, 0, ErrInvalidPrice},
+		{0.01, -tau, 100, ErrInvalidHorizon},
+	}
+
+	for _, table := range tables {
+		if _, err := RiskFactors(p, table.lambda, table.tau, table.S); err != table.err {
+			t.Errorf("lambda=%g, tau=%g, S=%g: expected error %v, got %v\n", table.lambda, table.tau, table.S, table.err, err)
+		}
+		if _, err := RiskFactorsCall(p, table.lambda, table.tau, table.S, 0.5); err != table.err {
+			t.Errorf("lambda=%g, tau=%g, S=%g: expected call error %v, got %v\n", table.lambda, table.tau, table.S, table.err, err)
+		}
+		if _, err := RiskFactorsPut(p, table.lambda, table.tau, table.S, -0.5); err != table.err {
+			t.Errorf("lambda=%g, tau=%g, S=%g: expected put error %v, got %v\n", table.lambda, table.tau, table.S, table.err, err)
+		}
+	}
+}
+
+func TestRiskFactorsInaccurate(t *testing.T) {
+	// the price distribution is so skewed that the upper tail expected shortfall can't be integrated accurately
+	p := riskmodelbs.ModelParamsBS{Mu: 0.05, R: 0.01, Sigma: 2}
+	if _, err := RiskFactors(p, 0.01, 10, 100); err != ErrInaccurateRiskFactors {
+		t.Errorf("expected error %v, got %v\n", ErrInaccurateRiskFactors, err)
+	}
+}
@@ -4,22 +4,71 @@ import (
 	"math"
 
 	"code.vegaprotocol.io/quant/bsformula"
+	"code.vegaprotocol.io/quant/interfaces"
 	"code.vegaprotocol.io/quant/riskmeasures"
 )
 
+// formula returns the option pricing formulas matching the accuracy requested in the model parameters
+func (p ModelParamsBS) formula() bsformula.Formula {
+	if p.PreciseCdf {
+		return bsformula.Precise
+	}
+	return bsformula.Fast
+}
+
+// callDelta returns the call delta from the Black-76 model if the underlying is a futures price
+// and from the Black-Scholes-Merton model otherwise
+func (p ModelParamsBS) callDelta(S, K, T float64) float64 {
+	if p.FuturesUnderlying {
+		return p.formula().Black76CallDelta(S, K, math.Exp(-p.R*T), p.Sigma, T)
+	}
+	return p.formula().CallDelta(S, K, p.R, p.Q, p.Sigma, T)
+}
+
+// putDelta returns the put delta from the Black-76 model if the underlying is a futures price
+// and from the Black-Scholes-Merton model otherwise
+func (p ModelParamsBS) putDelta(S, K, T float64) float64 {
+	if p.FuturesUnderlying {
+		return p.formula().Black76PutDelta(S, K, math.Exp(-p.R*T), p.Sigma, T)
+	}
+	return p.formula().PutDelta(S, K, p.R, p.Q, p.Sigma, T)
+}
+
 // RiskFactorsCall calculates the risk factors based on Black Scholes model for the evolution
 // of the risky asset (i.e. geometric brownian motion i.e. risky asset dist. is lognormal)
-// The risk factors returned are for CALL option
+// The risk factors returned are for CALL option, any yield in p.Q is accounted for in the option delta
+// and the option is valued with Black-76 when p.FuturesUnderlying is set.
+// An option at or past expiry (T <= 0) has settled so its risk factors are zero.
 func RiskFactorsCall(lambd, tau, S, K, T float64, p ModelParamsBS) RiskFactors {
+	if T <= 0 {
+		return RiskFactors{}
+	}
+	return callRiskFactors(lambd, tau, p.callDelta(S, K, T), p)
+}
+
+// RiskFactorsCallWithSurface calculates the risk factors for CALL option as RiskFactorsCall but with a volatility smile:
+// the option delta uses the implied volatility surface.Vol(K, T) of the option and the evolution of the risky asset
+// over the horizon tau uses the at-the-money volatility surface.Vol(S, tau), p.Sigma is ignored
+func RiskFactorsCallWithSurface(lambd, tau, S, K, T float64, p ModelParamsBS, surface interfaces.VolatilitySurface) RiskFactors {
+	if T <= 0 {
+		return RiskFactors{}
+	}
+	p.Sigma = surface.Vol(K, T)
+	callDelta := p.callDelta(S, K, T)
+	p.Sigma = surface.Vol(S, tau)
+	return callRiskFactors(lambd, tau, callDelta, p)
+}
+
+// callRiskFactors scales the lognormal expected shortfalls of the risky asset over the horizon tau by the call delta
+func callRiskFactors(lambd, tau, callDelta float64, p ModelParamsBS) RiskFactors {
 	muBar := (p.Mu - 0.5*p.Sigma*p.Sigma) * tau
 	sigmaBar := math.Sqrt(tau) * p.Sigma
 
-	bsProb1 := bsformula.BSCallProb1(S, K, p.R, p.Sigma, T)
 	negLogNormEs := riskmeasures.NegativeLogNormalEs(muBar, sigmaBar, lambd)
-	riskFactorShort := bsProb1 * (negLogNormEs - 1.0)
+	riskFactorShort := callDelta * (negLogNormEs - 1.0)
 
 	logNormEs := riskmeasures.LogNormalEs(muBar, sigmaBar, lambd)
-	riskFactorLong := bsProb1 * (logNormEs + 1.0)
+	riskFactorLong := callDelta * (logNormEs + 1.0)
 
 	factors := RiskFactors{riskFactorLong, riskFactorShort}
 	return factors
@@ -27,18 +76,41 @@ func RiskFactorsCall(lambd, tau, S, K, T float64, p ModelParamsBS) RiskFactors {
 
 // RiskFactorsPut calculates the risk factors based on Black Scholes model for the evolution
 // of the risky asset (i.e. geometric brownian motion i.e. risky asset dist. is lognormal)
-// The risk factors returned are for PUT option
+// The risk factors returned are for PUT option, any yield in p.Q is accounted for in the option delta
+// and the option is valued with Black-76 when p.FuturesUnderlying is set.
+// An option at or past expiry (T <= 0) has settled so its risk factors are zero.
 func RiskFactorsPut(lambd, tau, S, K, T float64, p ModelParamsBS) RiskFactors {
+	if T <= 0 {
+		return RiskFactors{}
+	}
+	return putRiskFactors(lambd, tau, p.putDelta(S, K, T), p)
+}
+
+// RiskFactorsPutWithSurface calculates the risk factors for PUT option as RiskFactorsPut but with a volatility smile:
+// the option delta uses the implied volatility surface.Vol(K, T) of the option and the evolution of the risky asset
+// over the horizon tau uses the at-the-money volatility surface.Vol(S, tau), p.Sigma is ignored
+func RiskFactorsPutWithSurface(lambd, tau, S, K, T float64, p ModelParamsBS, surface interfaces.VolatilitySurface) RiskFactors {
+	if T <= 0 {
+		return RiskFactors{}
+	}
+	p.Sigma = surface.Vol(K, T)
+	putDelta := p.putDelta(S, K, T)
+	p.Sigma = surface.Vol(S, tau)
+	return putRiskFactors(lambd, tau, putDelta, p)
+}
+
+// putRiskFactors scales the lognormal expected shortfalls of the risky asset over the horizon tau by minus the put delta
+func putRiskFactors(lambd, tau, putDelta float64, p ModelParamsBS) RiskFactors {
 	muBar := (p.Mu - 0.5*p.Sigma*p.Sigma) * tau
 	sigmaBar := math.Sqrt(tau) * p.Sigma
 
-	bsProb1 := bsformula.BSCallProb1(S, K, p.R, p.Sigma, T)
+	minusPutDelta := -putDelta
 
 	logNormEs := riskmeasures.LogNormalEs(muBar, sigmaBar, lambd)
-	riskFactorShort := (1.0 - bsProb1) * (logNormEs + 1.0)
+	riskFactorShort := minusPutDelta * (logNormEs + 1.0)
 
 	negLogNormEs := riskmeasures.NegativeLogNormalEs(muBar, sigmaBar, lambd)
-	riskFactorLong := (1.0 - bsProb1) * (negLogNormEs - 1.0)
+	riskFactorLong := minusPutDelta * (negLogNormEs - 1.0)
 
 	factors := RiskFactors{riskFactorLong, riskFactorShort}
 	return factors
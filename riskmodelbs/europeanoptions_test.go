@@ -8,6 +8,7 @@ import (
 
 	"code.vegaprotocol.io/quant/bsformula"
 	"code.vegaprotocol.io/quant/riskmeasures"
+	"code.vegaprotocol.io/quant/volsurface"
 
 	"gonum.org/v1/gonum/stat/distuv"
 )
@@ -31,7 +32,7 @@ var testValues = []struct {
 	{75., 80., 0.03, 0.1, 2.00, 0.01, 1.0 / 365.25 / 24 / 60}, // test non-unit strike / mat
 }
 
-func TestCallPutRiskFactorsUsingMonteCarlo(t *testing.T) {
+func runTestCallPutRiskFactorsUsingMonteCarlo(t *testing.T, q float64) {
 	const testToleranceForMC float64 = 1.0e-3
 	const numIndepMCSamples int = 20000
 
@@ -58,13 +59,13 @@ func TestCallPutRiskFactorsUsingMonteCarlo(t *testing.T) {
 		simShortCalls := make([]float64, numMCSamples)
 		simLongPuts := make([]float64, numMCSamples)
 		simShortPuts := make([]float64, numMCSamples)
-		currentCall := bsformula.BSCallPrice(S, K, r, sigma, T)
-		currentPut := bsformula.BSPutPrice(S, K, r, sigma, T)
+		currentCall := bsformula.BSMCallPrice(S, K, r, q, sigma, T)
+		currentPut := bsformula.BSMPutPrice(S, K, r, q, sigma, T)
 		for i := 0; i < numMCSamples; i++ {
 			SatTau := S * math.Exp((mu-0.5*sigma*sigma)*tau+sigma*math.Sqrt(tau)*Z[i])
-			simLongCalls[i] = bsformula.BSCallPrice(SatTau, K, r, sigma, T-tau) - currentCall
+			simLongCalls[i] = bsformula.BSMCallPrice(SatTau, K, r, q, sigma, T-tau) - currentCall
 			simShortCalls[i] = -simLongCalls[i]
-			simLongPuts[i] = bsformula.BSPutPrice(SatTau, K, r, sigma, T-tau) - currentPut
+			simLongPuts[i] = bsformula.BSMPutPrice(SatTau, K, r, q, sigma, T-tau) - currentPut
 			simShortPuts[i] = -simLongPuts[i]
 		}
 		empMarginLongCall := riskmeasures.EmpiricalEs(simLongCalls, lambda, false)
@@ -72,7 +73,7 @@ func TestCallPutRiskFactorsUsingMonteCarlo(t *testing.T) {
 		empMarginLongPut := riskmeasures.EmpiricalEs(simLongPuts, lambda, false)
 		empMarginShortPut := riskmeasures.EmpiricalEs(simShortPuts, lambda, false)
 
-		riskFactorsCall := RiskFactorsCall(lambda, tau, S, K, T, ModelParamsBS{mu, r, sigma})
+		riskFactorsCall := RiskFactorsCall(lambda, tau, S, K, T, ModelParamsBS{Mu: mu, R: r, Sigma: sigma, Q: q})
 
 		marginShortCall := S * riskFactorsCall.Short
 		marginLongCall := S * riskFactorsCall.Long
@@ -80,13 +81,13 @@ func TestCallPutRiskFactorsUsingMonteCarlo(t *testing.T) {
 		errorCall := math.Abs(marginShortCall-empMarginShortCall) + math.Abs(marginLongCall-empMarginLongCall)
 		errorCall /= currentCall
 		if math.IsNaN(errorCall) || math.IsInf(errorCall, 0) || errorCall > testToleranceForMC {
-			t.Logf("mu=%g, sigma=%g, tau=%g\n", mu, sigma, tau)
+			t.Logf("mu=%g, sigma=%g, q=%g, tau=%g\n", mu, sigma, q, tau)
 			t.Logf("margin short call=%g, emp margin short call=%g\n", marginShortCall, empMarginShortCall)
 			t.Logf("margin long call=%g, emp margin long call=%g\n", marginLongCall, empMarginLongCall)
 			t.Errorf("Error=%g is more than tolerance", errorCall)
 		}
 
-		riskFactorsPut := RiskFactorsPut(lambda, tau, S, K, T, ModelParamsBS{mu, r, sigma})
+		riskFactorsPut := RiskFactorsPut(lambda, tau, S, K, T, ModelParamsBS{Mu: mu, R: r, Sigma: sigma, Q: q})
 
 		marginShortPut := S * riskFactorsPut.Short
 		marginLongPut := S * riskFactorsPut.Long
@@ -94,7 +95,7 @@ func TestCallPutRiskFactorsUsingMonteCarlo(t *testing.T) {
 		errorPut := math.Abs(marginShortPut-empMarginShortPut) + math.Abs(marginLongPut-empMarginLongPut)
 		errorPut /= currentPut
 		if math.IsNaN(errorPut) || math.IsInf(errorPut, 0) || errorPut > testToleranceForMC {
-			t.Logf("mu=%g, sigma=%g, tau=%g\n", mu, sigma, tau)
+			t.Logf("mu=%g, sigma=%g, q=%g, tau=%g\n", mu, sigma, q, tau)
 			t.Logf("margin short put=%g, emp margin long put=%g\n", marginShortPut, empMarginShortPut)
 			t.Logf("margin long put=%g, emp margin long put=%g\n", marginLongPut, empMarginLongPut)
 			t.Errorf("Error=%g is more than tolerance", errorPut)
@@ -104,6 +105,15 @@ func TestCallPutRiskFactorsUsingMonteCarlo(t *testing.T) {
 
 }
 
+func TestCallPutRiskFactorsUsingMonteCarlo(t *testing.T) {
+	runTestCallPutRiskFactorsUsingMonteCarlo(t, 0.0)
+}
+
+// TestCallPutRiskFactorsWithYieldUsingMonteCarlo covers options on an underlying paying a continuous yield
+func TestCallPutRiskFactorsWithYieldUsingMonteCarlo(t *testing.T) {
+	runTestCallPutRiskFactorsUsingMonteCarlo(t, 0.05)
+}
+
 // TestTimeTakenForOptionsRiskFactor
 // At the moment you cannot fail this test.
 // Eventually we may impose minimum performance here to check
@@ -128,3 +138,132 @@ func TestTimeTakenForOptionsRiskFactor(t *testing.T) {
 	fmt.Printf("Num of times we can calculate call and put risk factors in BS model: %.1f per second.\n",
 		float64(numRuns)/elapsed.Seconds())
 }
+
+// TestCallPutRiskFactorsFuturesUnderlying checks that valuing options on a futures price with Black-76
+// agrees with Black-Scholes-Merton where the yield equals the interest rate (zero cost of carry)
+func TestCallPutRiskFactorsFuturesUnderlying(t *testing.T) {
+	const r float64 = 0.03
+	for _, vals := range testValues {
+		futures := ModelParamsBS{Mu: vals.mu, R: r, Sigma: vals.sigma, FuturesUnderlying: true}
+		fullCarry := ModelParamsBS{Mu: vals.mu, R: r, Sigma: vals.sigma, Q: r}
+
+		callFutures := RiskFactorsCall(vals.lambda, vals.tau, vals.S, vals.K, vals.T, futures)
+		callFullCarry := RiskFactorsCall(vals.lambda, vals.tau, vals.S, vals.K, vals.T, fullCarry)
+		putFutures := RiskFactorsPut(vals.lambda, vals.tau, vals.S, vals.K, vals.T, futures)
+		putFullCarry := RiskFactorsPut(vals.lambda, vals.tau, vals.S, vals.K, vals.T, fullCarry)
+
+		error := math.Abs(callFutures.Long-callFullCarry.Long) + math.Abs(callFutures.Short-callFullCarry.Short) +
+			math.Abs(putFutures.Long-putFullCarry.Long) + math.Abs(putFutures.Short-putFullCarry.Short)
+		if math.IsNaN(error) || math.IsInf(error, 0) || error > testTolerance {
+			t.Errorf("S=%g, K=%g, T=%g: error=%g is more than tolerance", vals.S, vals.K, vals.T, error)
+		}
+	}
+}
+
+// TestCallPutRiskFactorsPreciseCdf checks that the precise CDF only moves the risk factors
+// by the accuracy of the fast approximation
+func TestCallPutRiskFactorsPreciseCdf(t *testing.T) {
+	const tolerance float64 = 1.0e-4
+	for _, vals := range testValues {
+		fast := ModelParamsBS{Mu: vals.mu, R: 0.01, Sigma: vals.sigma}
+		precise := ModelParamsBS{Mu: vals.mu, R: 0.01, Sigma: vals.sigma, PreciseCdf: true}
+
+		callFast := RiskFactorsCall(vals.lambda, vals.tau, vals.S, vals.K, vals.T, fast)
+		callPrecise := RiskFactorsCall(vals.lambda, vals.tau, vals.S, vals.K, vals.T, precise)
+		putFast := RiskFactorsPut(vals.lambda, vals.tau, vals.S, vals.K, vals.T, fast)
+		putPrecise := RiskFactorsPut(vals.lambda, vals.tau, vals.S, vals.K, vals.T, precise)
+
+		error := math.Abs(callFast.Long-callPrecise.Long) + math.Abs(callFast.Short-callPrecise.Short) +
+			math.Abs(putFast.Long-putPrecise.Long) + math.Abs(putFast.Short-putPrecise.Short)
+		if math.IsNaN(error) || math.IsInf(error, 0) || error > tolerance {
+			t.Errorf("S=%g, K=%g, T=%g: error=%g is more than tolerance", vals.S, vals.K, vals.T, error)
+		}
+	}
+}
+
+// TestCallPutRiskFactorsWithSurface checks that the option delta is taken at the strike's implied volatility
+// and the horizon move at the at-the-money volatility of the surface
+func TestCallPutRiskFactorsWithSurface(t *testing.T) {
+	const S, r float64 = 100.0, 0.01
+	quotes := []volsurface.Quote{
+		{Expiry: 0.1, Strike: 80, Vol: 0.6},
+		{Expiry: 0.1, Strike: 100, Vol: 0.4},
+		{Expiry: 0.1, Strike: 120, Vol: 0.5},
+		{Expiry: 1.0, Strike: 80, Vol: 0.45},
+		{Expiry: 1.0, Strike: 100, Vol: 0.35},
+		{Expiry: 1.0, Strike: 120, Vol: 0.4},
+	}
+	surface, err := volsurface.New(S, quotes, volsurface.LogMoneyness, volsurface.Flat)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for _, K := range []float64{70, 90, 100, 115} {
+		for _, T := range []float64{0.1, 0.5, 2.0} {
+			const lambda, tau = 0.01, 1.0 / 365.25
+			p := ModelParamsBS{Mu: 0.05, R: r, Sigma: 123.0}
+			call := RiskFactorsCallWithSurface(lambda, tau, S, K, T, p, surface)
+			put := RiskFactorsPutWithSurface(lambda, tau, S, K, T, p, surface)
+
+			optionVol := surface.Vol(K, T)
+			callDelta := bsformula.BSCallDelta(S, K, r, optionVol, T)
+			putDelta := bsformula.BSPutDelta(S, K, r, optionVol, T)
+			forward := RiskFactorsForward(lambda, tau, ModelParamsBS{Mu: p.Mu, R: r, Sigma: surface.Vol(S, tau)})
+
+			error := math.Abs(call.Long-callDelta*forward.Long) + math.Abs(call.Short-callDelta*forward.Short) +
+				math.Abs(put.Long+putDelta*forward.Short) + math.Abs(put.Short+putDelta*forward.Long)
+			if math.IsNaN(error) || math.IsInf(error, 0) || error > testTolerance {
+				t.Errorf("K=%g, T=%g: error=%g is more than tolerance", K, T, error)
+			}
+		}
+	}
+
+	// a flat surface gives the same risk factors as a single volatility
+	flat, _ := volsurface.New(S, []volsurface.Quote{{Expiry: 1, Strike: S, Vol: 0.3}}, volsurface.Strike, volsurface.Flat)
+	p := ModelParamsBS{Mu: 0.05, R: r, Sigma: 0.3}
+	call := RiskFactorsCallWithSurface(0.01, 1.0/365.25, S, 110, 0.5, p, flat)
+	expected := RiskFactorsCall(0.01, 1.0/365.25, S, 110, 0.5, p)
+	if call != expected {
+		t.Errorf("flat surface: got %v, expected %v", call, expected)
+	}
+}
+
+func TestCallPutRiskFactorsDegenerateInputs(t *testing.T) {
+	const S, lambda, tau = 100.0, 0.01, 1.0 / 365.25
+	p := ModelParamsBS{Mu: 0.05, R: 0.01, Sigma: 0.5}
+	surface, _ := volsurface.New(S, []volsurface.Quote{{Expiry: 1, Strike: S, Vol: 0.3}}, volsurface.Strike, volsurface.Flat)
+
+	// options at or past expiry have settled
+	for _, T := range []float64{0, -0.1} {
+		for _, K := range []float64{80, 100, 120} {
+			factors := []RiskFactors{
+				RiskFactorsCall(lambda, tau, S, K, T, p), RiskFactorsPut(lambda, tau, S, K, T, p),
+				RiskFactorsCallWithSurface(lambda, tau, S, K, T, p, surface), RiskFactorsPutWithSurface(lambda, tau, S, K, T, p, surface),
+			}
+			for i, f := range factors {
+				if f != (RiskFactors{}) {
+					t.Errorf("K=%g, T=%g: risk factors %d=%+v, expected zero\n", K, T, i, f)
+				}
+			}
+		}
+	}
+
+	// with zero volatility the deltas are step functions and the forward risk factors stay finite
+	zeroVol := ModelParamsBS{Mu: 0.05, R: 0.01, Sigma: 0}
+	forward := RiskFactorsForward(lambda, tau, zeroVol)
+	for _, K := range []float64{80, 120} {
+		call := RiskFactorsCall(lambda, tau, S, K, 0.5, zeroVol)
+		put := RiskFactorsPut(lambda, tau, S, K, 0.5, zeroVol)
+		callDelta := bsformula.BSCallDelta(S, K, zeroVol.R, 0, 0.5)
+		putDelta := bsformula.BSPutDelta(S, K, zeroVol.R, 0, 0.5)
+
+		error := math.Abs(call.Long-callDelta*forward.Long) + math.Abs(call.Short-callDelta*forward.Short) +
+			math.Abs(put.Long+putDelta*forward.Short) + math.Abs(put.Short+putDelta*forward.Long)
+		if math.IsNaN(error) || math.IsInf(error, 0) || error > testTolerance {
+			t.Errorf("K=%g: call %+v, put %+v, forward %+v\n", K, call, put, forward)
+		}
+		if callDelta != 0 && callDelta != 1 || putDelta != 0 && putDelta != -1 {
+			t.Errorf("K=%g: call delta=%g, put delta=%g aren't steps\n", K, callDelta, putDelta)
+		}
+	}
+}
@@ -17,10 +17,18 @@ type RiskFactors struct {
 
 // ModelParamsBS collect the parameters of Black-Scholes model.
 // Here mu is the real-world measure growth rate, r is the risk-free interest rate, sigma is volatiliy
+// and q is the continuous dividend (or carry) yield used when pricing options on the risky asset
+// (e.g. the foreign interest rate for FX), it doesn't affect the real-world dynamics set by mu.
+// Set FuturesUnderlying when the risky asset is a futures price, options are then valued with
+// the Black-76 model and q is ignored.
+// Set PreciseCdf to value options with bsformula.Precise instead of the faster bsformula.Fast.
 type ModelParamsBS struct {
-	Mu    float64
-	R     float64
-	Sigma float64
+	Mu                float64
+	R                 float64
+	Sigma             float64
+	Q                 float64
+	FuturesUnderlying bool
+	PreciseCdf        bool
 }
 
 // RiskFactorsForward calculates the risk factors based on Black Scholes model for the evolution
@@ -0,0 +1,75 @@
+package riskmodelbs
+
+import (
+	"code.vegaprotocol.io/quant/misc"
+)
+
+// Validate checks that the growth rate Mu, interest rate R and yield Q are finite and the volatility Sigma
+// is non-negative and finite, it returns misc.ErrInvalidRate or misc.ErrInvalidSigma otherwise.
+// A zero Sigma is valid, the risk factors are then those of the deterministic price path.
+func (p ModelParamsBS) Validate() error {
+	return misc.FirstError(
+		misc.ValidateFinite(p.Mu, misc.ErrInvalidRate),
+		misc.ValidateFinite(p.R, misc.ErrInvalidRate),
+		misc.ValidateFinite(p.Q, misc.ErrInvalidRate),
+		misc.ValidateNonNegative(p.Sigma, misc.ErrInvalidSigma),
+	)
+}
+
+// validateRiskInputs checks the model parameters, the expected shortfall level and the risk horizon
+func validateRiskInputs(lambd, tau float64, p ModelParamsBS) error {
+	return misc.FirstError(
+		p.Validate(),
+		misc.ValidateProbability(lambd),
+		misc.ValidatePositive(tau, misc.ErrInvalidHorizon),
+	)
+}
+
+// validateOptionInputs additionally checks the price of the risky asset S, the strike K and the maturity T of the option,
+// which may be zero
+func validateOptionInputs(lambd, tau, S, K, T float64, p ModelParamsBS) error {
+	return misc.FirstError(
+		validateRiskInputs(lambd, tau, p),
+		misc.ValidatePositive(S, misc.ErrInvalidPrice),
+		misc.ValidatePositive(K, misc.ErrInvalidStrike),
+		misc.ValidateNonNegative(T, misc.ErrInvalidMaturity),
+	)
+}
+
+// checkedRiskFactors returns misc.ErrNotFinite when valid inputs still lead to an overflow
+func checkedRiskFactors(factors RiskFactors) (RiskFactors, error) {
+	if err := misc.FirstError(
+		misc.ValidateFinite(factors.Long, misc.ErrNotFinite),
+		misc.ValidateFinite(factors.Short, misc.ErrNotFinite),
+	); err != nil {
+		return RiskFactors{}, err
+	}
+	return factors, nil
+}
+
+// RiskFactorsForwardChecked is RiskFactorsForward returning an error instead of NaN or Inf risk factors
+// when the model parameters are invalid, lambda isn't strictly between 0 and 1 or the horizon tau isn't positive
+func RiskFactorsForwardChecked(lambd, tau float64, p ModelParamsBS) (RiskFactors, error) {
+	if err := validateRiskInputs(lambd, tau, p); err != nil {
+		return RiskFactors{}, err
+	}
+	return checkedRiskFactors(RiskFactorsForward(lambd, tau, p))
+}
+
+// RiskFactorsCallChecked is RiskFactorsCall returning an error instead of NaN or Inf risk factors
+// when the inputs are invalid, see RiskFactorsForwardChecked, S or K aren't positive or T is negative
+func RiskFactorsCallChecked(lambd, tau, S, K, T float64, p ModelParamsBS) (RiskFactors, error) {
+	if err := validateOptionInputs(lambd, tau, S, K, T, p); err != nil {
+		return RiskFactors{}, err
+	}
+	return checkedRiskFactors(RiskFactorsCall(lambd, tau, S, K, T, p))
+}
+
+// RiskFactorsPutChecked is RiskFactorsPut returning an error instead of NaN or Inf risk factors
+// when the inputs are invalid, see RiskFactorsForwardChecked, S or K aren't positive or T is negative
+func RiskFactorsPutChecked(lambd, tau, S, K, T float64, p ModelParamsBS) (RiskFactors, error) {
+	if err := validateOptionInputs(lambd, tau, S, K, T, p); err != nil {
+		return RiskFactors{}, err
+	}
+	return checkedRiskFactors(RiskFactorsPut(lambd, tau, S, K, T, p))
+}
@@ -0,0 +1,86 @@
+package riskmodelbs
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/misc"
+)
+
+func TestModelParamsValidate(t *testing.T) {
+	tables := []struct {
+		p   ModelParamsBS
+		err error
+	}{
+		{ModelParamsBS{Mu: 0.1, R: 0.01, Sigma: 0.5, Q: 0.02}, nil},
+		{ModelParamsBS{Mu: math.NaN(), R: 0.01, Sigma: 0.5}, misc.ErrInvalidRate},
+		{ModelParamsBS{Mu: 0.1, R: math.Inf(1), Sigma: 0.5}, misc.ErrInvalidRate},
+		{ModelParamsBS{Mu: 0.1, R: 0.01, Sigma: 0.5, Q: math.NaN()}, misc.ErrInvalidRate},
+		{ModelParamsBS{Mu: 0.1, R: 0.01, Sigma: 0}, nil},
+		{ModelParamsBS{Mu: 0.1, R: 0.01, Sigma: -0.5}, misc.ErrInvalidSigma},
+	}
+
+	for i, table := range tables {
+		if err := table.p.Validate(); err != table.err {
+			t.Errorf("case %d: expected error %v, got %v\n", i, table.err, err)
+		}
+	}
+}
+
+func TestCheckedRiskFactors(t *testing.T) {
+	const lambda, tau, S, K, T = 0.01, 1.0 / 365.25, 100.0, 110.0, 0.5
+	p := ModelParamsBS{Mu: 0.1, R: 0.01, Sigma: 0.5}
+
+	tables := []struct {
+		lambda, tau, S, K, T float64
+		p                    ModelParamsBS
+		err                  error
+	}{
+		{lambda, tau, S, K, T, p, nil},
+		{lambda, tau, S, K, T, ModelParamsBS{Mu: 0.1, Sigma: 0}, nil},
+		{lambda, tau, S, K, T, ModelParamsBS{Mu: 0.1, Sigma: -0.5}, misc.ErrInvalidSigma},
+		{lambda, tau, S, K, T, ModelParamsBS{Mu: 0.1, Sigma: math.Inf(1)}, misc.ErrInvalidSigma},
+		{1, tau, S, K, T, p, misc.ErrProbabilityOutOfRange},
+		{0, tau, S, K, T, p, misc.ErrProbabilityOutOfRange},
+		{lambda, -tau, S, K, T, p, misc.ErrInvalidHorizon},
+		{lambda, 0, S, K, T, p, misc.ErrInvalidHorizon},
+	}
+
+	for i, table := range tables {
+		forward, err := RiskFactorsForwardChecked(table.lambda, table.tau, table.p)
+		if err != table.err {
+			t.Errorf("case %d: expected forward error %v, got %v\n", i, table.err, err)
+		}
+		if err == nil && forward != RiskFactorsForward(table.lambda, table.tau, table.p) {
+			t.Errorf("case %d: checked forward risk factors differ from the unchecked ones\n", i)
+		}
+		if _, err := RiskFactorsCallChecked(table.lambda, table.tau, table.S, table.K, table.T, table.p); err != table.err {
+			t.Errorf("case %d: expected call error %v, got %v\n", i, table.err, err)
+		}
+		if _, err := RiskFactorsPutChecked(table.lambda, table.tau, table.S, table.K, table.T, table.p); err != table.err {
+			t.Errorf("case %d: expected put error %v, got %v\n", i, table.err, err)
+		}
+	}
+
+	optionTables := []struct {
+		S, K, T float64
+		err     error
+	}{
+		{0, K, T, misc.ErrInvalidPrice},
+		{S, math.NaN(), T, misc.ErrInvalidStrike},
+		{S, K, -T, misc.ErrInvalidMaturity},
+	}
+	for i, table := range optionTables {
+		if _, err := RiskFactorsCallChecked(lambda, tau, table.S, table.K, table.T, p); err != table.err {
+			t.Errorf("case %d: expected call error %v, got %v\n", i, table.err, err)
+		}
+		if _, err := RiskFactorsPutChecked(lambda, tau, table.S, table.K, table.T, p); err != table.err {
+			t.Errorf("case %d: expected put error %v, got %v\n", i, table.err, err)
+		}
+	}
+
+	// valid inputs may still overflow
+	if _, err := RiskFactorsForwardChecked(lambda, 1e6, ModelParamsBS{Mu: 0.1, Sigma: 10}); err != misc.ErrNotFinite {
+		t.Errorf("expected error %v, got %v\n", misc.ErrNotFinite, err)
+	}
+}
@@ -0,0 +1,72 @@
+package riskmodelmerton
+
+import (
+	"math"
+
+	"code.vegaprotocol.io/quant/interfaces"
+	"code.vegaprotocol.io/quant/riskmeasures"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+const probabilityTolerance = 1e-3
+
+// Distribution is a mixture of lognormal distributions, the i-th component having the probability weights[i]
+// and log-mean and log-standard deviation mus[i] and sigmas[i]. It implements interfaces.AnalyticalDistribution.
+type Distribution struct {
+	weights []float64
+	mus     []float64
+	sigmas  []float64
+}
+
+// GetProbabilityDistribution returns the Poisson mixture of lognormals corresponding to the supplied model parameters, the current price S and time horizon tau.
+func (modelParams ModelParamsMerton) GetProbabilityDistribution(S, tau float64) interfaces.AnalyticalDistribution {
+	weights, mus, sigmas := modelParams.mixture(tau)
+	logS := math.Log(S)
+	for i := range mus {
+		mus[i] += logS
+	}
+	return &Distribution{weights: weights, mus: mus, sigmas: sigmas}
+}
+
+// GetProbabilityTolerance specifies the probability tolerance alphaModel that the model supports. It shouldn't be used for any calculations involving alpha < alphaModel or alpha > 1-alphaModel
+func (modelParams ModelParamsMerton) GetProbabilityTolerance() (alphaModel float64) {
+	alphaModel = probabilityTolerance
+	return alphaModel
+}
+
+// Mean returns the mean of the mixture
+func (d *Distribution) Mean() float64 {
+	var mean float64
+	for i, w := range d.weights {
+		mean += w * math.Exp(d.mus[i]+0.5*d.sigmas[i]*d.sigmas[i])
+	}
+	return mean
+}
+
+// Variance returns the variance of the mixture
+func (d *Distribution) Variance() float64 {
+	var secondMoment float64
+	for i, w := range d.weights {
+		secondMoment += w * math.Exp(2*d.mus[i]+2*d.sigmas[i]*d.sigmas[i])
+	}
+	mean := d.Mean()
+	return secondMoment - mean*mean
+}
+
+// CDF returns the probability that the price doesn't exceed x
+func (d *Distribution) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	var cdf float64
+	for i, w := range d.weights {
+		cdf += w * distuv.UnitNormal.CDF((math.Log(x)-d.mus[i])/d.sigmas[i])
+	}
+	return cdf
+}
+
+// Quantile returns the price x such that CDF(x) = p
+func (d *Distribution) Quantile(p float64) float64 {
+	return -riskmeasures.LogNormalMixtureVaR(d.weights, d.mus, d.sigmas, p)
+}
@@ -0,0 +1,61 @@
+package riskmodelmerton
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/interfaces"
+	"code.vegaprotocol.io/quant/pricedistribution"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+func TestMertonIsAnalyticalModel(t *testing.T) {
+	var analyticMerton interfaces.AnalyticalModel = testParams[0]
+
+	if analyticMerton == nil {
+		t.Error("Expected ModelParamsMerton to implement AnalyticalModel interface")
+	}
+}
+
+func TestDistributionAgainstMC(t *testing.T) {
+	const S float64 = 50.0
+	const numSamples int = 1000000
+
+	for _, p := range testParams {
+		for _, tau := range []float64{1.0 / 365.25, 0.25} {
+			samples := simulate(p, tau, numSamples)
+			for i := range samples {
+				samples[i] *= S
+			}
+			d := p.GetProbabilityDistribution(S, tau)
+
+			if error := math.Abs(d.Mean()-S*math.Exp(p.Mu*tau)) / S; error > testTolerance {
+				t.Errorf("%+v, tau=%g: mean=%g, expected=%g\n", p, tau, d.Mean(), S*math.Exp(p.Mu*tau))
+			}
+			mean, variance := stat.MeanVariance(samples, nil)
+			if error := math.Abs(d.Mean()-mean) / math.Sqrt(variance); error > 0.01 {
+				t.Errorf("%+v, tau=%g: mean=%g, MC mean=%g\n", p, tau, d.Mean(), mean)
+			}
+			if error := math.Abs(d.Variance()-variance) / variance; error > 0.02 {
+				t.Errorf("%+v, tau=%g: variance=%g, MC variance=%g\n", p, tau, d.Variance(), variance)
+			}
+
+			for _, prob := range []float64{p.GetProbabilityTolerance(), 0.01, 0.1, 0.5, 0.9, 0.99, 1 - p.GetProbabilityTolerance()} {
+				x := stat.Quantile(prob, stat.Empirical, samples, nil)
+				error := math.Abs(d.CDF(x) - prob)
+				if math.IsNaN(error) || error > 4*math.Sqrt(prob*(1-prob)/float64(numSamples)) {
+					t.Errorf("%+v, tau=%g, p=%g: CDF=%g at the MC quantile\n", p, tau, prob, d.CDF(x))
+				}
+				if error := math.Abs(d.CDF(d.Quantile(prob)) - prob); error > testTolerance {
+					t.Errorf("%+v, tau=%g, p=%g: CDF(quantile)=%g\n", p, tau, prob, d.CDF(d.Quantile(prob)))
+				}
+			}
+
+			minPrice, maxPrice := pricedistribution.PriceRange(d, 0.99)
+			if !(minPrice < S && S < maxPrice) {
+				t.Errorf("%+v, tau=%g: price range [%g, %g] doesn't contain the current price\n", p, tau, minPrice, maxPrice)
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package riskmodelmerton
+
+import (
+	"math"
+
+	"code.vegaprotocol.io/quant/bsformula"
+	"code.vegaprotocol.io/quant/riskmeasures"
+	"code.vegaprotocol.io/quant/riskmodelbs"
+)
+
+// pricingSeries calls term with the probability of n jumps under the risk-neutral measure and the interest rate and
+// volatility of the Black-Scholes model conditional on n jumps, see Merton (Option pricing when underlying stock returns
+// are discontinuous, Journal of Financial Economics, 1976) and the sum of the terms is the option price or delta.
+// At or past expiry no more jumps can happen and the single term gives the intrinsic value.
+func (p ModelParamsMerton) pricingSeries(T float64, term func(weight, r, sigma float64) float64) float64 {
+	if T <= 0 {
+		return term(1, p.R, p.Sigma)
+	}
+	k := p.jumpCompensator()
+	weights := poissonWeights(p.Lambda * (1 + k) * T)
+	var sum float64
+	for n, weight := range weights {
+		r := p.R - p.Lambda*k + float64(n)*math.Log(1+k)/T
+		sigma := math.Sqrt(p.Sigma*p.Sigma + float64(n)*p.JumpStd*p.JumpStd/T)
+		sum += term(weight, r, sigma)
+	}
+	return sum
+}
+
+// CallPrice calculates the call option price in the Merton jump-diffusion model
+func (p ModelParamsMerton) CallPrice(S, K, T float64) float64 {
+	return p.pricingSeries(T, func(weight, r, sigma float64) float64 {
+		return weight * bsformula.BSCallPrice(S, K, r, sigma, T)
+	})
+}
+
+// PutPrice calculates the put option price in the Merton jump-diffusion model
+func (p ModelParamsMerton) PutPrice(S, K, T float64) float64 {
+	return p.pricingSeries(T, func(weight, r, sigma float64) float64 {
+		return weight * bsformula.BSPutPrice(S, K, r, sigma, T)
+	})
+}
+
+// CallDelta calculates the call option delta (partial derivative w.r.t. S) in the Merton jump-diffusion model
+func (p ModelParamsMerton) CallDelta(S, K, T float64) float64 {
+	return p.pricingSeries(T, func(weight, r, sigma float64) float64 {
+		return weight * bsformula.BSCallDelta(S, K, r, sigma, T)
+	})
+}
+
+// PutDelta calculates the put option delta (partial derivative w.r.t. S) in the Merton jump-diffusion model
+func (p ModelParamsMerton) PutDelta(S, K, T float64) float64 {
+	return p.pricingSeries(T, func(weight, r, sigma float64) float64 {
+		return weight * bsformula.BSPutDelta(S, K, r, sigma, T)
+	})
+}
+
+// RiskFactorsCall calculates the risk factors based on the Merton jump-diffusion model for the evolution
+// of the risky asset (i.e. risky asset dist. is a Poisson mixture of lognormals)
+// The risk factors returned are for CALL option, they are zero for an option at or past expiry (T <= 0)
+func RiskFactorsCall(lambd, tau, S, K, T float64, p ModelParamsMerton) riskmodelbs.RiskFactors {
+	if T <= 0 {
+		return riskmodelbs.RiskFactors{}
+	}
+	weights, mus, sigmas := p.mixture(tau)
+
+	callDelta := p.CallDelta(S, K, T)
+	negMixtureEs := riskmeasures.NegativeLogNormalMixtureEs(weights, mus, sigmas, lambd)
+	riskFactorShort := callDelta * (negMixtureEs - 1.0)
+
+	mixtureEs := riskmeasures.LogNormalMixtureEs(weights, mus, sigmas, lambd)
+	riskFactorLong := callDelta * (mixtureEs + 1.0)
+
+	factors := riskmodelbs.RiskFactors{Long: riskFactorLong, Short: riskFactorShort}
+	return factors
+}
+
+// RiskFactorsPut calculates the risk factors based on the Merton jump-diffusion model for the evolution
+// of the risky asset (i.e. risky asset dist. is a Poisson mixture of lognormals)
+// The risk factors returned are for PUT option, they are zero for an option at or past expiry (T <= 0)
+func RiskFactorsPut(lambd, tau, S, K, T float64, p ModelParamsMerton) riskmodelbs.RiskFactors {
+	if T <= 0 {
+		return riskmodelbs.RiskFactors{}
+	}
+	weights, mus, sigmas := p.mixture(tau)
+
+	minusPutDelta := -p.PutDelta(S, K, T)
+
+	mixtureEs := riskmeasures.LogNormalMixtureEs(weights, mus, sigmas, lambd)
+	riskFactorShort := minusPutDelta * (mixtureEs + 1.0)
+
+	negMixtureEs := riskmeasures.NegativeLogNormalMixtureEs(weights, mus, sigmas, lambd)
+	riskFactorLong := minusPutDelta * (negMixtureEs - 1.0)
+
+	factors := riskmodelbs.RiskFactors{Long: riskFactorLong, Short: riskFactorShort}
+	return factors
+}
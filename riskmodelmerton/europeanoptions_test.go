@@ -0,0 +1,122 @@
+package riskmodelmerton
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/bsformula"
+	"code.vegaprotocol.io/quant/riskmodelbs"
+
+	"golang.org/x/exp/rand"
+)
+
+// TestPricesWithoutJumps checks that without jumps the prices, deltas and risk factors are those of the Black-Scholes model
+func TestPricesWithoutJumps(t *testing.T) {
+	const S, lambda, tau = 100.0, 0.01, 1.0 / 365.25
+	for _, p := range testParams {
+		p.Lambda = 0
+		bs := riskmodelbs.ModelParamsBS{Mu: p.Mu, R: p.R, Sigma: p.Sigma}
+		for _, K := range []float64{50, 90, 100, 110, 200} {
+			for _, T := range []float64{0.1, 1} {
+				error := math.Abs(p.CallPrice(S, K, T)-bsformula.BSCallPrice(S, K, p.R, p.Sigma, T)) +
+					math.Abs(p.PutPrice(S, K, T)-bsformula.BSPutPrice(S, K, p.R, p.Sigma, T)) +
+					math.Abs(p.CallDelta(S, K, T)-bsformula.BSCallDelta(S, K, p.R, p.Sigma, T)) +
+					math.Abs(p.PutDelta(S, K, T)-bsformula.BSPutDelta(S, K, p.R, p.Sigma, T))
+				call, expectedCall := RiskFactorsCall(lambda, tau, S, K, T, p), riskmodelbs.RiskFactorsCall(lambda, tau, S, K, T, bs)
+				put, expectedPut := RiskFactorsPut(lambda, tau, S, K, T, p), riskmodelbs.RiskFactorsPut(lambda, tau, S, K, T, bs)
+				error += math.Abs(call.Long-expectedCall.Long) + math.Abs(call.Short-expectedCall.Short) +
+					math.Abs(put.Long-expectedPut.Long) + math.Abs(put.Short-expectedPut.Short)
+				if math.IsNaN(error) || math.IsInf(error, 0) || error > testTolerance {
+					t.Errorf("%+v, K=%g, T=%g: error=%g\n", p, K, T, error)
+				}
+			}
+		}
+	}
+}
+
+func TestPricesUsingMC(t *testing.T) {
+	const testToleranceForMC float64 = 1e-2 // relative to S
+	const S, T = 100.0, 0.5
+	const numSamples int = 400000
+
+	for _, p := range testParams {
+		// simulate the risk-neutral distribution
+		riskNeutral := p
+		riskNeutral.Mu = p.R
+		X := simulate(riskNeutral, T, numSamples)
+		for _, K := range []float64{70, 100, 130} {
+			var call, put float64
+			for _, x := range X {
+				call += math.Max(S*x-K, 0)
+				put += math.Max(K-S*x, 0)
+			}
+			call *= math.Exp(-p.R*T) / float64(numSamples)
+			put *= math.Exp(-p.R*T) / float64(numSamples)
+
+			error := (math.Abs(p.CallPrice(S, K, T)-call) + math.Abs(p.PutPrice(S, K, T)-put)) / S
+			if math.IsNaN(error) || error > testToleranceForMC {
+				t.Errorf("%+v, K=%g: call=%g, MC call=%g, put=%g, MC put=%g\n", p, K, p.CallPrice(S, K, T), call, p.PutPrice(S, K, T), put)
+			}
+		}
+	}
+}
+
+// TestDeltaVsFiniteDifference checks the deltas, the tolerance allows for the accuracy of the fast normal CDF
+func TestDeltaVsFiniteDifference(t *testing.T) {
+	const testTolerance float64 = 1.0e-3
+	const S, h = 100.0, 1e-2
+	for _, p := range testParams {
+		for _, K := range []float64{70, 100, 130} {
+			for _, T := range []float64{0.1, 1} {
+				callDelta := (p.CallPrice(S+h, K, T) - p.CallPrice(S-h, K, T)) / (2 * h)
+				putDelta := (p.PutPrice(S+h, K, T) - p.PutPrice(S-h, K, T)) / (2 * h)
+				error := math.Abs(p.CallDelta(S, K, T)-callDelta) + math.Abs(p.PutDelta(S, K, T)-putDelta)
+				if math.IsNaN(error) || error > testTolerance {
+					t.Errorf("%+v, K=%g, T=%g: delta error=%g\n", p, K, T, error)
+				}
+			}
+		}
+	}
+}
+
+// TestCallPutRiskFactorsOrder checks that the option risk factors are the forward ones scaled by the delta
+// and are heavier than in the Black-Scholes model
+func TestCallPutRiskFactorsOrder(t *testing.T) {
+	const lambda, tau = 0.01, 1.0 / 365.25
+	rng := rand.New(rand.NewSource(1))
+	for _, p := range testParams {
+		for i := 0; i < 100; i++ {
+			S, K, T := 100.0, 50+100*rng.Float64(), 0.05+rng.Float64()
+			forward := RiskFactorsForward(lambda, tau, p)
+			call := RiskFactorsCall(lambda, tau, S, K, T, p)
+			put := RiskFactorsPut(lambda, tau, S, K, T, p)
+			callDelta, putDelta := p.CallDelta(S, K, T), p.PutDelta(S, K, T)
+
+			error := math.Abs(call.Long-callDelta*forward.Long) + math.Abs(call.Short-callDelta*forward.Short) +
+				math.Abs(put.Long+putDelta*forward.Short) + math.Abs(put.Short+putDelta*forward.Long)
+			if math.IsNaN(error) || math.IsInf(error, 0) || error > testTolerance {
+				t.Errorf("%+v, K=%g, T=%g: error=%g\n", p, K, T, error)
+			}
+		}
+	}
+}
+
+func TestExpiredOptions(t *testing.T) {
+	const S = 100.0
+
+	for _, p := range testParams {
+		for _, T := range []float64{0, -0.1} {
+			for _, K := range []float64{80, 100, 120} {
+				call, put := p.CallPrice(S, K, T), p.PutPrice(S, K, T)
+				if call != math.Max(S-K, 0) || put != math.Max(K-S, 0) {
+					t.Errorf("%+v, K=%g, T=%g: call=%g, put=%g aren't the intrinsic values\n", p, K, T, call, put)
+				}
+				callRiskFactors := RiskFactorsCall(0.01, 1.0/365.25, S, K, T, p)
+				putRiskFactors := RiskFactorsPut(0.01, 1.0/365.25, S, K, T, p)
+				if callRiskFactors != (riskmodelbs.RiskFactors{}) || putRiskFactors != (riskmodelbs.RiskFactors{}) {
+					t.Errorf("%+v, K=%g, T=%g: risk factors %+v and %+v, expected zero\n", p, K, T, callRiskFactors, putRiskFactors)
+				}
+			}
+		}
+	}
+}
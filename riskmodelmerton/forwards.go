@@ -0,0 +1,77 @@
+package riskmodelmerton
+
+import (
+	"math"
+
+	"code.vegaprotocol.io/quant/riskmeasures"
+	"code.vegaprotocol.io/quant/riskmodelbs"
+)
+
+// ModelParamsMerton collect the parameters of the Merton jump-diffusion model in which the log-price follows
+// a brownian motion with volatility sigma plus jumps arriving at the rate lambda per year with normally distributed
+// sizes of mean jumpMean and standard deviation jumpStd (in log-price).
+// Here mu is the real-world measure growth rate (including the jumps) and r is the risk-free interest rate.
+// Sigma must be positive, see Validate, the RiskFactors*Checked functions return an error otherwise.
+type ModelParamsMerton struct {
+	Mu       float64
+	R        float64
+	Sigma    float64
+	Lambda   float64
+	JumpMean float64
+	JumpStd  float64
+}
+
+const (
+	// maxJumps caps the number of jumps in the Poisson mixture
+	maxJumps = 1000
+	// poissonTailTolerance is the probability of more jumps than those included in the Poisson mixture
+	poissonTailTolerance = 1e-15
+)
+
+// jumpCompensator returns E[exp(Y)] - 1 for the jump size Y
+func (p ModelParamsMerton) jumpCompensator() float64 {
+	return math.Exp(p.JumpMean+0.5*p.JumpStd*p.JumpStd) - 1
+}
+
+// poissonWeights returns the probabilities of 0, 1, 2, ... jumps in a Poisson distribution with the given mean
+// up to the number of jumps whose cumulative probability reaches 1 - poissonTailTolerance
+func poissonWeights(mean float64) []float64 {
+	weights := []float64{math.Exp(-mean)}
+	cumulative := weights[0]
+	for n := 1; n < maxJumps && 1-cumulative > poissonTailTolerance; n++ {
+		lgamma, _ := math.Lgamma(float64(n + 1))
+		weight := math.Exp(-mean + float64(n)*math.Log(mean) - lgamma)
+		if weight == 0 && float64(n) > mean {
+			break
+		}
+		weights = append(weights, weight)
+		cumulative += weight
+	}
+	return weights
+}
+
+// mixture returns the Poisson weights and the lognormal parameters of each component
+// of the distribution of the relative price change S_tau / S over the horizon tau
+func (p ModelParamsMerton) mixture(tau float64) (weights, mus, sigmas []float64) {
+	weights = poissonWeights(p.Lambda * tau)
+	mus = make([]float64, len(weights))
+	sigmas = make([]float64, len(weights))
+	drift := (p.Mu - 0.5*p.Sigma*p.Sigma - p.Lambda*p.jumpCompensator()) * tau
+	for n := range weights {
+		mus[n] = drift + float64(n)*p.JumpMean
+		sigmas[n] = math.Sqrt(p.Sigma*p.Sigma*tau + float64(n)*p.JumpStd*p.JumpStd)
+	}
+	return
+}
+
+// RiskFactorsForward calculates the risk factors based on the Merton jump-diffusion model for the evolution
+// of the risky asset (i.e. future is a Poisson mixture of lognormals)
+func RiskFactorsForward(lambd, tau float64, modelParams ModelParamsMerton) riskmodelbs.RiskFactors {
+	weights, mus, sigmas := modelParams.mixture(tau)
+
+	riskFactorShort := riskmeasures.NegativeLogNormalMixtureEs(weights, mus, sigmas, lambd) - 1.0
+	riskFactorLong := riskmeasures.LogNormalMixtureEs(weights, mus, sigmas, lambd) + 1.0
+
+	factors := riskmodelbs.RiskFactors{Long: riskFactorLong, Short: riskFactorShort}
+	return factors
+}
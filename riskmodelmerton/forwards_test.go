@@ -0,0 +1,79 @@
+package riskmodelmerton
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"code.vegaprotocol.io/quant/riskmeasures"
+	"code.vegaprotocol.io/quant/riskmodelbs"
+
+	"golang.org/x/exp/rand"
+)
+
+const testTolerance float64 = 1.0e-8
+
+var testParams = []ModelParamsMerton{
+	{Mu: 0.05, R: 0.01, Sigma: 0.5, Lambda: 10, JumpMean: -0.05, JumpStd: 0.1},
+	{Mu: 0.0, R: 0.0, Sigma: 0.2, Lambda: 100, JumpMean: 0.0, JumpStd: 0.02},
+	{Mu: -0.1, R: 0.03, Sigma: 1.0, Lambda: 1, JumpMean: -0.3, JumpStd: 0.2},
+}
+
+// simulate returns sorted samples of the relative price change S_tau / S
+func simulate(p ModelParamsMerton, tau float64, numSamples int) []float64 {
+	rng := rand.New(rand.NewSource(1))
+	drift := (p.Mu - 0.5*p.Sigma*p.Sigma - p.Lambda*p.jumpCompensator()) * tau
+	samples := make([]float64, numSamples)
+	for i := range samples {
+		logX := drift + p.Sigma*math.Sqrt(tau)*rng.NormFloat64()
+		// the number of jumps is the number of exponential inter-arrival times within tau
+		for t := rng.ExpFloat64() / p.Lambda; t < tau; t += rng.ExpFloat64() / p.Lambda {
+			logX += p.JumpMean + p.JumpStd*rng.NormFloat64()
+		}
+		samples[i] = math.Exp(logX)
+	}
+	sort.Float64s(samples)
+	return samples
+}
+
+// TestFwdRiskFactorsWithoutJumps checks that without jumps the risk factors are those of the Black-Scholes model
+func TestFwdRiskFactorsWithoutJumps(t *testing.T) {
+	for _, p := range testParams {
+		p.Lambda = 0
+		for _, tau := range []float64{1.0 / 365.25 / 24 / 60, 1.0 / 365.25, 1.0} {
+			riskFactors := RiskFactorsForward(0.01, tau, p)
+			expected := riskmodelbs.RiskFactorsForward(0.01, tau, riskmodelbs.ModelParamsBS{Mu: p.Mu, R: p.R, Sigma: p.Sigma})
+			error := math.Abs(riskFactors.Long-expected.Long) + math.Abs(riskFactors.Short-expected.Short)
+			if math.IsNaN(error) || math.IsInf(error, 0) || error > testTolerance {
+				t.Errorf("%+v, tau=%g: risk factors %+v, expected %+v\n", p, tau, riskFactors, expected)
+			}
+		}
+	}
+}
+
+func TestFwdRiskFactorsUsingMC(t *testing.T) {
+	const testToleranceForMC float64 = 2e-2 // relative
+	const lambda, tau = 0.01, 1.0 / 365.25
+
+	for _, p := range testParams {
+		X := simulate(p, tau, 1000000)
+		negX := make([]float64, len(X))
+		for i := range X {
+			negX[len(X)-1-i] = -X[i]
+		}
+		riskFactors := RiskFactorsForward(lambda, tau, p)
+		expectedLong := riskmeasures.EmpiricalEs(X, lambda, true) + 1.0
+		expectedShort := riskmeasures.EmpiricalEs(negX, lambda, true) - 1.0
+
+		error := math.Abs(riskFactors.Long-expectedLong)/expectedLong + math.Abs(riskFactors.Short-expectedShort)/expectedShort
+		if math.IsNaN(error) || math.IsInf(error, 0) || error > testToleranceForMC {
+			t.Errorf("%+v: risk factors %+v, MC long=%g, short=%g\n", p, riskFactors, expectedLong, expectedShort)
+		}
+
+		// jumps make the tails heavier than those of the diffusion alone
+		bs := riskmodelbs.RiskFactorsForward(lambda, tau, riskmodelbs.ModelParamsBS{Mu: p.Mu, R: p.R, Sigma: p.Sigma})
+		if riskFactors.Long <= bs.Long || riskFactors.Short <= bs.Short {
+			t.Errorf("%+v: risk factors %+v aren't above the Black-Scholes ones %+v\n", p, riskFactors, bs)
+		}
+	}
+}
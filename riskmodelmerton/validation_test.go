@@ -0,0 +1,75 @@
+package riskmodelmerton
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/misc"
+)
+
+func TestModelParamsValidate(t *testing.T) {
+	tables := []struct {
+		p   ModelParamsMerton
+		err error
+	}{
+		{testParams[0], nil},
+		{ModelParamsMerton{Mu: 0.1, Sigma: 0.5}, nil},
+		{ModelParamsMerton{Mu: math.NaN(), Sigma: 0.5}, misc.ErrInvalidRate},
+		{ModelParamsMerton{Mu: 0.1, Sigma: 0.5, Lambda: 1, JumpMean: math.Inf(-1)}, misc.ErrInvalidRate},
+		{ModelParamsMerton{Mu: 0.1, Sigma: 0, Lambda: 10, JumpStd: 0.1}, misc.ErrInvalidSigma},
+		{ModelParamsMerton{Mu: 0.1, Sigma: -0.5}, misc.ErrInvalidSigma},
+		{ModelParamsMerton{Mu: 0.1, Sigma: 0.5, Lambda: 1, JumpStd: -0.1}, misc.ErrInvalidSigma},
+		{ModelParamsMerton{Mu: 0.1, Sigma: 0.5, Lambda: -1}, ErrInvalidJumpIntensity},
+		{ModelParamsMerton{Mu: 0.1, Sigma: 0.5, Lambda: math.Inf(1)}, ErrInvalidJumpIntensity},
+	}
+
+	for i, table := range tables {
+		if err := table.p.Validate(); err != table.err {
+			t.Errorf("case %d: expected error %v, got %v\n", i, table.err, err)
+		}
+	}
+}
+
+func TestCheckedRiskFactors(t *testing.T) {
+	const lambda, tau, S, K, T = 0.01, 1.0 / 365.25, 100.0, 110.0, 0.5
+	p := testParams[0]
+	noDiffusion := p
+	noDiffusion.Sigma = 0
+
+	tables := []struct {
+		lambda, tau, S, K, T float64
+		p                    ModelParamsMerton
+		err                  error
+	}{
+		{lambda, tau, S, K, T, p, nil},
+		{lambda, tau, S, K, 0, p, nil},
+		{lambda, tau, S, K, T, noDiffusion, misc.ErrInvalidSigma},
+		{1, tau, S, K, T, p, misc.ErrProbabilityOutOfRange},
+		{lambda, 0, S, K, T, p, misc.ErrInvalidHorizon},
+	}
+
+	for i, table := range tables {
+		forward, err := RiskFactorsForwardChecked(table.lambda, table.tau, table.p)
+		if err != table.err {
+			t.Errorf("case %d: expected forward error %v, got %v\n", i, table.err, err)
+		}
+		if err == nil && forward != RiskFactorsForward(table.lambda, table.tau, table.p) {
+			t.Errorf("case %d: checked forward risk factors differ from the unchecked ones\n", i)
+		}
+		if _, err := RiskFactorsCallChecked(table.lambda, table.tau, table.S, table.K, table.T, table.p); err != table.err {
+			t.Errorf("case %d: expected call error %v, got %v\n", i, table.err, err)
+		}
+		if _, err := RiskFactorsPutChecked(table.lambda, table.tau, table.S, table.K, table.T, table.p); err != table.err {
+			t.Errorf("case %d: expected put error %v, got %v\n", i, table.err, err)
+		}
+	}
+
+	for _, S := range []float64{0, math.NaN()} {
+		if _, err := RiskFactorsCallChecked(lambda, tau, S, K, T, p); err != misc.ErrInvalidPrice {
+			t.Errorf("S=%g: expected error %v, got %v\n", S, misc.ErrInvalidPrice, err)
+		}
+	}
+	if _, err := RiskFactorsPutChecked(lambda, tau, S, K, -T, p); err != misc.ErrInvalidMaturity {
+		t.Errorf("expected error %v, got %v\n", misc.ErrInvalidMaturity, err)
+	}
+}
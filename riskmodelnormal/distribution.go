@@ -0,0 +1,25 @@
+package riskmodelnormal
+
+import (
+	"math"
+
+	"code.vegaprotocol.io/quant/interfaces"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+const probabilityTolerance = 1e-3
+
+// GetProbabilityDistribution returns the normal distribution corresponding to the supplied model parameters, the current price S and time horizon tau.
+func (modelParams ModelParamsNormal) GetProbabilityDistribution(S, tau float64) interfaces.AnalyticalDistribution {
+	m := S + modelParams.Mu*tau
+	stdDev := modelParams.Sigma * math.Sqrt(tau)
+
+	return &distuv.Normal{Mu: m, Sigma: stdDev}
+}
+
+// GetProbabilityTolerance specifies the probability tolerance alphaModel that the model supports. It shouldn't be used for any calculations involving alpha < alphaModel or alpha > 1-alphaModel
+func (modelParams ModelParamsNormal) GetProbabilityTolerance() (alphaModel float64) {
+	alphaModel = probabilityTolerance
+	return alphaModel
+}
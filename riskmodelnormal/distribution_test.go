@@ -0,0 +1,57 @@
+package riskmodelnormal
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/interfaces"
+	"code.vegaprotocol.io/quant/pricedistribution"
+)
+
+func TestNormalIsAnalyticalModel(t *testing.T) {
+	var analyticNormal interfaces.AnalyticalModel = ModelParamsNormal{Mu: 0, R: 0, Sigma: 1.5}
+
+	if analyticNormal == nil {
+		t.Error("Expeced ModelParamsNormal to implement AnalyticalModel interface")
+	}
+}
+
+func TestNormalMeanAndVariance(t *testing.T) {
+	tolerance := 1e-12
+	S := 10.0
+	mu := 0.1
+	tau := 0.25
+	sigma := 2.0
+	expectedMean := S + mu*tau
+	expectedVariance := sigma * sigma * tau
+
+	dist := ModelParamsNormal{Mu: mu, R: 0, Sigma: sigma}.GetProbabilityDistribution(S, tau)
+
+	if math.Abs(expectedMean-dist.Mean()) > tolerance {
+		t.Errorf("Error=%g is more than tolerance (%g)", math.Abs(expectedMean-dist.Mean()), tolerance)
+	}
+	if math.Abs(expectedVariance-dist.Variance()) > tolerance {
+		t.Errorf("Error=%g is more than tolerance (%g)", math.Abs(expectedVariance-dist.Variance()), tolerance)
+	}
+}
+
+// TestNormalPriceRangeAllowsNegativePrices checks that, unlike in the log-normal model,
+// the price range can extend below zero
+func TestNormalPriceRangeAllowsNegativePrices(t *testing.T) {
+	tolerance := 1e-9
+	S := 0.5
+	sigma := 1.0
+	tau := 1.0
+	alpha := 0.9
+
+	model := ModelParamsNormal{Mu: 0, R: 0, Sigma: sigma}
+	minPrice, maxPrice := pricedistribution.PriceRange(model.GetProbabilityDistribution(S, tau), alpha)
+
+	if minPrice >= 0 {
+		t.Errorf("Expected negative minimum price, got %g", minPrice)
+	}
+	// the range is symmetric around S
+	if math.Abs((maxPrice-S)-(S-minPrice)) > tolerance {
+		t.Errorf("Expected range symmetric around %g, got [%g, %g]", S, minPrice, maxPrice)
+	}
+}
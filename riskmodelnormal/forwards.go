@@ -0,0 +1,33 @@
+package riskmodelnormal
+
+import (
+	"math"
+
+	"code.vegaprotocol.io/quant/riskmeasures"
+	"code.vegaprotocol.io/quant/riskmodelbs"
+)
+
+// ModelParamsNormal collect the parameters of the Bachelier (normal) model
+// in which the price follows an arithmetic brownian motion and can become zero or negative.
+// Here mu is the real-world measure drift, r is the risk-free interest rate
+// and sigma is the absolute (normal) volatility, all expressed in price units per year.
+type ModelParamsNormal struct {
+	Mu    float64
+	R     float64
+	Sigma float64
+}
+
+// RiskFactorsForward calculates the risk factors based on the Bachelier model for the evolution
+// of the risky asset (i.e. arithmetic brownian motion i.e. future is normal).
+// As the price changes don't scale with the price level the current price S is needed
+// to express the risk factors relative to the mark price.
+func RiskFactorsForward(lambd, tau, S float64, modelParams ModelParamsNormal) riskmodelbs.RiskFactors {
+	muBar := modelParams.Mu * tau
+	sigmaBar := math.Sqrt(tau) * modelParams.Sigma
+
+	riskFactorShort := riskmeasures.NegativeNormalEs(muBar, sigmaBar, lambd) / S
+	riskFactorLong := riskmeasures.NormalEs(muBar, sigmaBar, lambd) / S
+
+	factors := riskmodelbs.RiskFactors{Long: riskFactorLong, Short: riskFactorShort}
+	return factors
+}
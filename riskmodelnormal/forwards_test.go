@@ -0,0 +1,83 @@
+package riskmodelnormal
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/riskmeasures"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// TestNormalFwdRiskFactorsUsingMonteCarlo compares the risk factors with the
+// empirical expected shortfall of simulated price changes.
+func TestNormalFwdRiskFactorsUsingMonteCarlo(t *testing.T) {
+	const testToleranceForMC float64 = 1.0e-2
+	const numIndepMCSamples int = 500000
+
+	numMCSamples := 2 * numIndepMCSamples
+	Z := make([]float64, numMCSamples)
+	for i := 0; i < numIndepMCSamples; i++ {
+		z := distuv.UnitNormal.Rand()
+		Z[i] = z
+		Z[numIndepMCSamples+i] = -z // antithetic sample
+	}
+
+	tables := []struct {
+		S      float64
+		mu     float64
+		sigma  float64
+		lambda float64
+		tau    float64
+	}{
+		{1.0, 0.0, 0.5, 0.01, 1.0 / 365.25},
+		{100.0, 5.0, 20.0, 0.01, 1.0 / 365.25 / 24},
+		{0.2, -0.1, 1.0, 0.05, 1.0 / 12},
+	}
+
+	for _, table := range tables {
+		S, mu, sigma, lambda, tau := table.S, table.mu, table.sigma, table.lambda, table.tau
+
+		simLong := make([]float64, numMCSamples)
+		simShort := make([]float64, numMCSamples)
+		for i := 0; i < numMCSamples; i++ {
+			simLong[i] = mu*tau + sigma*math.Sqrt(tau)*Z[i]
+			simShort[i] = -simLong[i]
+		}
+		empMarginLong := riskmeasures.EmpiricalEs(simLong, lambda, false)
+		empMarginShort := riskmeasures.EmpiricalEs(simShort, lambda, false)
+
+		riskFactors := RiskFactorsForward(lambda, tau, S, ModelParamsNormal{Mu: mu, R: 0, Sigma: sigma})
+
+		error := (math.Abs(S*riskFactors.Long-empMarginLong) + math.Abs(S*riskFactors.Short-empMarginShort)) /
+			(sigma * math.Sqrt(tau))
+		if math.IsNaN(error) || math.IsInf(error, 0) || error > testToleranceForMC {
+			t.Logf("margin long=%g, emp margin long=%g\n", S*riskFactors.Long, empMarginLong)
+			t.Logf("margin short=%g, emp margin short=%g\n", S*riskFactors.Short, empMarginShort)
+			t.Errorf("Error=%g is more than tolerance", error)
+		}
+	}
+}
+
+// TestNormalFwdRiskFactorsOrder checks that with a positive drift the risk factor
+// for long positions doesn't exceed the risk factor for short positions.
+func TestNormalFwdRiskFactorsOrder(t *testing.T) {
+	const lambda float64 = 0.01
+	const numRuns int = 1000
+
+	for runIdx := 0; runIdx < numRuns; runIdx++ {
+		params := ModelParamsNormal{Mu: distuv.UnitUniform.Rand(), R: 0, Sigma: distuv.UnitUniform.Rand()}
+		tau := distuv.UnitUniform.Rand()
+		S := distuv.UnitUniform.Rand() + 0.1
+
+		riskFactors := RiskFactorsForward(lambda, tau, S, params)
+
+		if math.IsNaN(riskFactors.Short) || math.IsNaN(riskFactors.Long) ||
+			math.IsInf(riskFactors.Short, 0) || math.IsInf(riskFactors.Long, 0) {
+			t.Errorf("risk factor is NaN or Inf")
+		}
+		if riskFactors.Short < riskFactors.Long {
+			t.Errorf("risk factor for long must be less than that for short")
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package riskmodelstudentt
+
+import (
+	"math"
+
+	"code.vegaprotocol.io/quant/interfaces"
+
+	"gonum.org/v1/gonum/integrate/quad"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+const (
+	probabilityTolerance = 1e-3
+	// momentNodes is the number of Gauss-Legendre nodes for the moments of the truncated distribution
+	momentNodes = 512
+)
+
+// Distribution is the distribution of the price whose logarithm follows a Student-t distribution,
+// possibly truncated to [lower, upper], it implements interfaces.AnalyticalDistribution.
+type Distribution struct {
+	logPrice     distuv.StudentsT
+	lower, upper float64
+	// cdfLower and mass are the Student-t CDF at the lower bound and the probability between the bounds
+	cdfLower, mass float64
+}
+
+// GetProbabilityDistribution returns the distribution of the price corresponding to the supplied model parameters, the current price S and time horizon tau.
+func (modelParams ModelParamsStudentT) GetProbabilityDistribution(S, tau float64) interfaces.AnalyticalDistribution {
+	location, scale := modelParams.logReturnParams(tau)
+	d := &Distribution{
+		logPrice: distuv.StudentsT{Mu: math.Log(S) + location, Sigma: scale, Nu: modelParams.Nu},
+		lower:    math.Inf(-1),
+		upper:    math.Inf(1),
+		mass:     1,
+	}
+	if modelParams.Truncation > 0 {
+		width := modelParams.Truncation * modelParams.Sigma * math.Sqrt(tau)
+		d.lower, d.upper = d.logPrice.Mu-width, d.logPrice.Mu+width
+		d.cdfLower = d.logPrice.CDF(d.lower)
+		d.mass = d.logPrice.CDF(d.upper) - d.cdfLower
+	}
+	return d
+}
+
+// GetProbabilityTolerance specifies the probability tolerance alphaModel that the model supports. It shouldn't be used for any calculations involving alpha < alphaModel or alpha > 1-alphaModel
+func (modelParams ModelParamsStudentT) GetProbabilityTolerance() (alphaModel float64) {
+	alphaModel = probabilityTolerance
+	return alphaModel
+}
+
+// Mean returns the mean of the price, +Inf without truncation as the exponential of a Student-t r.v. has no finite mean
+func (d *Distribution) Mean() float64 {
+	if math.IsInf(d.upper, 1) {
+		return math.Inf(1)
+	}
+	return d.moment(1)
+}
+
+// Variance returns the variance of the price, +Inf without truncation as the exponential of a Student-t r.v.
+// has no finite variance
+func (d *Distribution) Variance() float64 {
+	if math.IsInf(d.upper, 1) {
+		return math.Inf(1)
+	}
+	mean := d.moment(1)
+	return d.moment(2) - mean*mean
+}
+
+// CDF returns the probability that the price doesn't exceed x
+func (d *Distribution) CDF(x float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	logX := math.Log(x)
+	if logX <= d.lower {
+		return 0
+	}
+	if logX >= d.upper {
+		return 1
+	}
+	return (d.logPrice.CDF(logX) - d.cdfLower) / d.mass
+}
+
+// Quantile returns the price x such that CDF(x) = p
+func (d *Distribution) Quantile(p float64) float64 {
+	return math.Exp(d.logPrice.Quantile(d.cdfLower + p*d.mass))
+}
+
+// moment returns the k-th moment of the truncated price, the integral of exp(k y) over the density of the log-price y
+func (d *Distribution) moment(k float64) float64 {
+	integrand := func(y float64) float64 {
+		return math.Exp(k*y) * d.logPrice.Prob(y) / d.mass
+	}
+	return quad.Fixed(integrand, d.lower, d.upper, momentNodes, nil, 0)
+}
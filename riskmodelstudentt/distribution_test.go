@@ -0,0 +1,82 @@
+package riskmodelstudentt
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/interfaces"
+	"code.vegaprotocol.io/quant/pricedistribution"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+func TestStudentTIsAnalyticalModel(t *testing.T) {
+	var analyticStudentT interfaces.AnalyticalModel = testParams[0]
+
+	if analyticStudentT == nil {
+		t.Error("Expected ModelParamsStudentT to implement AnalyticalModel interface")
+	}
+}
+
+func TestDistributionAgainstMC(t *testing.T) {
+	const S float64 = 20.0
+	const numSamples int = 1000000
+	const testTolerance float64 = 1e-12
+
+	for _, p := range testParams {
+		for _, tau := range []float64{1.0 / 365.25, 0.25} {
+			samples := simulateLogReturns(p, tau, numSamples)
+			for i := range samples {
+				samples[i] = S * math.Exp(samples[i])
+			}
+			d := p.GetProbabilityDistribution(S, tau)
+
+			for _, prob := range []float64{p.GetProbabilityTolerance(), 0.01, 0.1, 0.5, 0.9, 0.99, 1 - p.GetProbabilityTolerance()} {
+				x := stat.Quantile(prob, stat.Empirical, samples, nil)
+				error := math.Abs(d.CDF(x) - prob)
+				if math.IsNaN(error) || error > 4*math.Sqrt(prob*(1-prob)/float64(numSamples)) {
+					t.Errorf("%+v, tau=%g, p=%g: CDF=%g at the MC quantile\n", p, tau, prob, d.CDF(x))
+				}
+				if error := math.Abs(d.CDF(d.Quantile(prob)) - prob); error > testTolerance {
+					t.Errorf("%+v, tau=%g, p=%g: CDF(quantile)=%g\n", p, tau, prob, d.CDF(d.Quantile(prob)))
+				}
+			}
+
+			minPrice, maxPrice := pricedistribution.PriceRange(d, 0.99)
+			if !(minPrice < S && S < maxPrice) {
+				t.Errorf("%+v, tau=%g: price range [%g, %g] doesn't contain the current price\n", p, tau, minPrice, maxPrice)
+			}
+		}
+	}
+}
+
+func TestTruncatedDistributionAgainstMC(t *testing.T) {
+	const S float64 = 20.0
+	const numSamples int = 1000000
+	const tau = 0.25
+	// gonum's Student-t quantile is accurate to about 1e-8 away from the median
+	const testTolerance float64 = 1e-8
+	for _, p := range testParams {
+		p.Truncation = 5
+		samples := simulateLogReturns(p, tau, numSamples)
+		for i := range samples {
+			samples[i] = S * math.Exp(samples[i])
+		}
+		d := p.GetProbabilityDistribution(S, tau)
+		mean, variance := stat.MeanVariance(samples, nil)
+		if error := math.Abs(d.Mean()-mean) / math.Sqrt(variance/float64(numSamples)); math.IsNaN(error) || error > 4 {
+			t.Errorf("%+v: mean=%g, MC mean=%g\n", p, d.Mean(), mean)
+		}
+		if error := math.Abs(d.Variance()/variance - 1); math.IsNaN(error) || error > 1e-2 {
+			t.Errorf("%+v: variance=%g, MC variance=%g\n", p, d.Variance(), variance)
+		}
+		if d.Quantile(0) < samples[0]*0.99 || d.Quantile(1) > samples[numSamples-1]*1.01 {
+			t.Errorf("%+v: support [%g, %g], samples in [%g, %g]\n", p, d.Quantile(0), d.Quantile(1), samples[0], samples[numSamples-1])
+		}
+		for _, prob := range []float64{0, 0.01, 0.5, 0.99, 1} {
+			if error := math.Abs(d.CDF(d.Quantile(prob)) - prob); error > testTolerance {
+				t.Errorf("%+v: p=%g: CDF(quantile)=%g\n", p, prob, d.CDF(d.Quantile(prob)))
+			}
+		}
+	}
+}
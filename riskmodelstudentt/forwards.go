@@ -0,0 +1,73 @@
+package riskmodelstudentt
+
+import (
+	"errors"
+	"math"
+
+	"code.vegaprotocol.io/quant/misc"
+	"code.vegaprotocol.io/quant/riskmeasures"
+	"code.vegaprotocol.io/quant/riskmodel"
+	"code.vegaprotocol.io/quant/riskmodelbs"
+)
+
+var (
+	// ErrInvalidTruncation is returned by RiskFactorsForward when the truncation isn't positive and finite,
+	// without it the price has no mean and the short risk factor is infinite
+	ErrInvalidTruncation = errors.New("truncation must be positive and finite")
+	// ErrInaccurateRiskFactors is returned when the error estimate of an integrated expected shortfall exceeds
+	// the tolerance, it is riskmodel.ErrInaccurateRiskFactors
+	ErrInaccurateRiskFactors = riskmodel.ErrInaccurateRiskFactors
+)
+
+// riskFactorTolerance is the largest integration error estimate of the risk factors accepted by RiskFactorsForward
+const riskFactorTolerance = 1e-6
+
+// ModelParamsStudentT collect the parameters of the model in which the log-returns over a horizon tau follow
+// a Student-t distribution with nu > 2 degrees of freedom, scaled to have the variance sigma^2 tau and
+// centered at (mu - sigma^2 / 2) tau as in the Black-Scholes model.
+// Here mu is the real-world measure growth rate, r is the risk-free interest rate and sigma is volatility,
+// as nu grows the model becomes the Black-Scholes one.
+// When Truncation is positive the log-return is truncated to within Truncation times sigma sqrt(tau) of its location,
+// e.g. 20, so that the price has finite moments and the short position a finite risk factor,
+// the scale stays that of the untruncated distribution. RiskFactorsForward needs it, the zero value leaves
+// the distribution untruncated.
+type ModelParamsStudentT struct {
+	Mu         float64
+	R          float64
+	Sigma      float64
+	Nu         float64
+	Truncation float64
+}
+
+// logReturnParams returns the location and scale of the log-return over the horizon tau
+func (p ModelParamsStudentT) logReturnParams(tau float64) (location, scale float64) {
+	location = (p.Mu - 0.5*p.Sigma*p.Sigma) * tau
+	scale = p.Sigma * math.Sqrt(tau*(p.Nu-2)/p.Nu)
+	return
+}
+
+// RiskFactorsForward calculates the risk factors based on the Student-t model for the log-returns R of the risky asset,
+// i.e. the expected shortfalls of the relative price changes exp(R) - 1 and 1 - exp(R) for the long and short positions.
+// The model must be truncated, e.g. with a Truncation of 20, as otherwise the short risk factor is infinite,
+// ErrInvalidTruncation is returned when it isn't. ErrInaccurateRiskFactors is returned when the integration error
+// estimate of either risk factor exceeds 1e-6 and misc.ErrNotFinite when a risk factor overflows.
+func RiskFactorsForward(lambd, tau float64, modelParams ModelParamsStudentT) (riskmodelbs.RiskFactors, error) {
+	if !(modelParams.Truncation > 0) || math.IsInf(modelParams.Truncation, 1) {
+		return riskmodelbs.RiskFactors{}, ErrInvalidTruncation
+	}
+	// the integrands are the prices below or above a quantile, which are bounded, so that the quadrature is accurate
+	d := modelParams.GetProbabilityDistribution(1, tau)
+	es, errEstimate := riskmeasures.DistributionEs(d, lambd)
+	negEs, negErrEstimate := riskmeasures.NegativeDistributionEs(d, lambd)
+	factors := riskmodelbs.RiskFactors{Long: es + 1, Short: negEs - 1}
+	if err := misc.FirstError(
+		misc.ValidateFinite(factors.Long, misc.ErrNotFinite),
+		misc.ValidateFinite(factors.Short, misc.ErrNotFinite),
+	); err != nil {
+		return riskmodelbs.RiskFactors{}, err
+	}
+	if !(errEstimate <= riskFactorTolerance) || !(negErrEstimate <= riskFactorTolerance) {
+		return riskmodelbs.RiskFactors{}, ErrInaccurateRiskFactors
+	}
+	return factors, nil
+}
@@ -0,0 +1,138 @@
+package riskmodelstudentt
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"code.vegaprotocol.io/quant/misc"
+	"code.vegaprotocol.io/quant/riskmeasures"
+	"code.vegaprotocol.io/quant/riskmodel"
+	"code.vegaprotocol.io/quant/riskmodelbs"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+var testParams = []ModelParamsStudentT{
+	{Mu: 0.05, R: 0.01, Sigma: 0.5, Nu: 3},
+	{Mu: 0.0, R: 0.0, Sigma: 1.5, Nu: 4},
+	{Mu: -0.1, R: 0.03, Sigma: 0.2, Nu: 10},
+}
+
+// simulateLogReturns returns sorted samples of the log-return over the horizon tau, dropping those beyond the truncation
+func simulateLogReturns(p ModelParamsStudentT, tau float64, numSamples int) []float64 {
+	// use our own source so that the samples drawn by other tests don't change
+	src := rand.New(rand.NewSource(1))
+	normal := distuv.Normal{Mu: 0, Sigma: 1, Src: src}
+	chiSquared := distuv.ChiSquared{K: p.Nu, Src: src}
+	samples := make([]float64, 0, numSamples)
+	for len(samples) < numSamples {
+		// a Student-t r.v. with unit variance
+		z := normal.Rand() / math.Sqrt(chiSquared.Rand()/p.Nu) * math.Sqrt((p.Nu-2)/p.Nu)
+		if p.Truncation > 0 && math.Abs(z) > p.Truncation {
+			continue
+		}
+		samples = append(samples, (p.Mu-0.5*p.Sigma*p.Sigma)*tau+p.Sigma*math.Sqrt(tau)*z)
+	}
+	sort.Float64s(samples)
+	return samples
+}
+
+// TestFwdRiskFactorsUsingMC compares the risk factors with the expected shortfalls of simulated relative price changes
+func TestFwdRiskFactorsUsingMC(t *testing.T) {
+	const testToleranceForMC float64 = 3e-2 // relative
+	const lambda, tau = 0.01, 1.0 / 365.25
+	for _, p := range testParams {
+		for _, truncation := range []float64{5, 20} {
+			p.Truncation = truncation
+			X := simulateLogReturns(p, tau, 1000000)
+			priceChanges := make([]float64, len(X))
+			negPriceChanges := make([]float64, len(X))
+			for i := range X {
+				priceChanges[i] = math.Expm1(X[i])
+				negPriceChanges[len(X)-1-i] = -priceChanges[i]
+			}
+			riskFactors, err := RiskFactorsForward(lambda, tau, p)
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+			expectedLong := riskmeasures.EmpiricalEs(priceChanges, lambda, true)
+			expectedShort := riskmeasures.EmpiricalEs(negPriceChanges, lambda, true)
+			error := math.Abs(riskFactors.Long-expectedLong)/expectedLong + math.Abs(riskFactors.Short-expectedShort)/expectedShort
+			if math.IsNaN(error) || math.IsInf(error, 0) || error > testToleranceForMC {
+				t.Errorf("%+v: risk factors %+v, MC long=%g\n", p, riskFactors, expectedLong)
+			}
+		}
+	}
+}
+
+// TestFwdRiskFactorsAgainstPriceDistribution checks the risk factors are the expected shortfalls of the model's
+// price distribution as computed for any analytical model
+func TestFwdRiskFactorsAgainstPriceDistribution(t *testing.T) {
+	const tolerance float64 = 1e-10
+	const S, lambda, tau = 20.0, 0.01, 1.0 / 365.25
+	for _, p := range testParams {
+		p.Truncation = 20
+		expected, err := riskmodel.RiskFactors(p, lambda, tau, S)
+		if err != nil {
+			t.Fatal(err)
+		}
+		riskFactors, err := RiskFactorsForward(lambda, tau, p)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		error := math.Abs(riskFactors.Long-expected.Long) + math.Abs(riskFactors.Short-expected.Short)
+		if math.IsNaN(error) || error > tolerance {
+			t.Errorf("%+v: risk factors %+v, expected %+v\n", p, riskFactors, expected)
+		}
+	}
+}
+
+// TestFwdRiskFactorsFatTails checks that far in the tails the risk factors exceed the Black-Scholes ones
+// and that with many degrees of freedom they approach them
+func TestFwdRiskFactorsFatTails(t *testing.T) {
+	const tolerance float64 = 1e-3 // relative
+	const tau = 1.0 / 365.25
+	for _, p := range testParams {
+		p.Truncation = 20
+		bsParams := riskmodelbs.ModelParamsBS{Mu: p.Mu, R: p.R, Sigma: p.Sigma}
+		riskFactors, err := RiskFactorsForward(1e-4, tau, p)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		bs := riskmodelbs.RiskFactorsForward(1e-4, tau, bsParams)
+		if riskFactors.Long <= bs.Long || riskFactors.Short <= bs.Short {
+			t.Errorf("%+v: risk factors %+v aren't above the Black-Scholes ones %+v\n", p, riskFactors, bs)
+		}
+		// gonum's Student-t quantile loses accuracy for much larger nu
+		p.Nu = 1e4
+		riskFactors, err = RiskFactorsForward(0.01, tau, p)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		bs = riskmodelbs.RiskFactorsForward(0.01, tau, bsParams)
+		if math.Abs(riskFactors.Long/bs.Long-1) > tolerance || math.Abs(riskFactors.Short/bs.Short-1) > tolerance {
+			t.Errorf("%+v: risk factors %+v, Black-Scholes %+v\n", p, riskFactors, bs)
+		}
+	}
+}
+
+func TestFwdRiskFactorsErrors(t *testing.T) {
+	const lambda, tau = 0.01, 1.0 / 365.25
+	p := testParams[0]
+	for _, truncation := range []float64{0, -1, math.Inf(1), math.NaN()} {
+		p.Truncation = truncation
+		if _, err := RiskFactorsForward(lambda, tau, p); err != ErrInvalidTruncation {
+			t.Errorf("truncation=%g: expected error %v, got %v\n", truncation, ErrInvalidTruncation, err)
+		}
+	}
+
+	// over years the price distribution is too skewed to integrate accurately and with a loose truncation it overflows
+	if _, err := RiskFactorsForward(lambda, 10, ModelParamsStudentT{Sigma: 2, Nu: 3, Truncation: 20}); err != ErrInaccurateRiskFactors {
+		t.Errorf("expected error %v, got %v\n", ErrInaccurateRiskFactors, err)
+	}
+	if _, err := RiskFactorsForward(lambda, 100, ModelParamsStudentT{Sigma: 5, Nu: 3, Truncation: 1000}); err != misc.ErrNotFinite {
+		t.Errorf("expected error %v, got %v\n", misc.ErrNotFinite, err)
+	}
+}
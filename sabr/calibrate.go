@@ -0,0 +1,149 @@
+package sabr
+
+import (
+	"errors"
+	"math"
+
+	"code.vegaprotocol.io/quant/misc"
+
+	"gonum.org/v1/gonum/optimize"
+)
+
+var (
+	// ErrInvalidQuotes is returned when the strikes and vols differ in length or aren't positive
+	ErrInvalidQuotes = errors.New("strikes and volatilities must be positive and of the same length")
+	// ErrTooFewQuotes is returned when there are fewer quotes than the 3 calibrated parameters
+	ErrTooFewQuotes = errors.New("at least 3 quotes are needed to calibrate alpha, rho and nu")
+	// ErrForwardNotBracketed is returned when the forward isn't within the quoted strikes so the at-the-money vol is unknown
+	ErrForwardNotBracketed = errors.New("quoted strikes must bracket the forward price")
+	// ErrAlphaNotFound is returned when no alpha reproduces the at-the-money volatility
+	ErrAlphaNotFound = errors.New("no alpha reproduces the at-the-money volatility")
+)
+
+const (
+	alphaMaxIter = 200
+	alphaTol     = 1e-15
+)
+
+// AlphaFromATMVol returns alpha such that the SABR implied volatility at the money equals atmVol
+// given beta, rho and nu, which are taken from p. The implied volatility is lognormal unless normal is set.
+func AlphaFromATMVol(F, T, atmVol float64, p Params, normal bool) (float64, error) {
+	guess := atmVol * math.Pow(F, 1-p.Beta)
+	if normal {
+		guess = atmVol * math.Pow(F, -p.Beta)
+	}
+	f := func(alpha float64) float64 {
+		p.Alpha = alpha
+		if normal {
+			return p.NormalVol(F, F, T) - atmVol
+		}
+		return p.LognormalVol(F, F, T) - atmVol
+	}
+
+	lo, hi := 0.5*guess, 2*guess
+	for f(lo) > 0 && lo > 1e-10*guess {
+		lo /= 2
+	}
+	for f(hi) < 0 && hi < 1e10*guess {
+		hi *= 2
+	}
+	alpha, err := misc.FindRootBrent(f, lo, hi, alphaMaxIter, alphaTol*guess)
+	if err != nil {
+		return math.NaN(), ErrAlphaNotFound
+	}
+	return alpha, nil
+}
+
+// Calibrate returns the SABR parameters for the given beta fitted to the lognormal implied volatilities vols at strikes K
+// of expiry T on the forward F. Alpha reproduces the at-the-money volatility, interpolated linearly from the quotes on either
+// side of F, while rho and nu minimise the sum of squared volatility errors.
+func Calibrate(F, T, beta float64, K, vols []float64) (Params, error) {
+	return calibrate(F, T, beta, K, vols, false)
+}
+
+// CalibrateNormal returns the SABR parameters for the given beta fitted to the normal implied volatilities, see Calibrate
+func CalibrateNormal(F, T, beta float64, K, vols []float64) (Params, error) {
+	return calibrate(F, T, beta, K, vols, true)
+}
+
+func calibrate(F, T, beta float64, K, vols []float64, normal bool) (Params, error) {
+	if len(K) != len(vols) || !(F > 0) || !(T > 0) {
+		return Params{}, ErrInvalidQuotes
+	}
+	if len(K) < 3 {
+		return Params{}, ErrTooFewQuotes
+	}
+	atmVol, err := interpolateATMVol(F, K, vols)
+	if err != nil {
+		return Params{}, err
+	}
+
+	// rho = tanh(x[0]) and nu = exp(x[1]) keep the parameters in range
+	toParams := func(x []float64) (Params, error) {
+		p := Params{Beta: beta, Rho: math.Tanh(x[0]), Nu: math.Exp(x[1])}
+		alpha, err := AlphaFromATMVol(F, T, atmVol, p, normal)
+		p.Alpha = alpha
+		return p, err
+	}
+	problem := optimize.Problem{
+		Func: func(x []float64) float64 {
+			p, err := toParams(x)
+			if err != nil {
+				return math.Inf(1)
+			}
+			vol := p.LognormalVol
+			if normal {
+				vol = p.NormalVol
+			}
+			sse := 0.0
+			for i := range K {
+				e := vol(F, K[i], T) - vols[i]
+				sse += e * e
+			}
+			if math.IsNaN(sse) {
+				return math.Inf(1)
+			}
+			return sse
+		},
+	}
+
+	settings := &optimize.Settings{
+		Converger:       &optimize.FunctionConverge{Absolute: 1e-24, Relative: 1e-12, Iterations: 200},
+		MajorIterations: 10000,
+	}
+	best := math.Inf(1)
+	var bestX []float64
+	for _, x0 := range [][]float64{{0, math.Log(0.5)}, {-0.5, math.Log(1.5)}, {0.5, math.Log(1.5)}} {
+		result, _ := optimize.Minimize(problem, x0, settings, &optimize.NelderMead{})
+		if result != nil && result.F < best {
+			best, bestX = result.F, result.X
+		}
+	}
+	if bestX == nil {
+		return Params{}, ErrAlphaNotFound
+	}
+	return toParams(bestX)
+}
+
+// interpolateATMVol returns the volatility at F interpolated linearly in the strike between the nearest quotes on either side
+func interpolateATMVol(F float64, K, vols []float64) (float64, error) {
+	below, above := -1, -1
+	for i := range K {
+		if !(K[i] > 0) || !(vols[i] > 0) || math.IsInf(vols[i], 0) {
+			return 0, ErrInvalidQuotes
+		}
+		if K[i] <= F && (below < 0 || K[i] > K[below]) {
+			below = i
+		}
+		if K[i] >= F && (above < 0 || K[i] < K[above]) {
+			above = i
+		}
+	}
+	if below < 0 || above < 0 {
+		return 0, ErrForwardNotBracketed
+	}
+	if K[above] == K[below] {
+		return vols[below], nil
+	}
+	return vols[below] + (vols[above]-vols[below])*(F-K[below])/(K[above]-K[below]), nil
+}
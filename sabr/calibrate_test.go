@@ -0,0 +1,77 @@
+package sabr
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCalibrateRecoversParameters(t *testing.T) {
+	const testTolerance float64 = 1.0e-4
+
+	for _, normal := range []bool{false, true} {
+		for _, table := range testParams {
+			p, F, T := table.params, table.F, table.T
+			var K, vols []float64
+			for i := 0; i < 11; i++ {
+				strike := F * (0.6 + 0.08*float64(i))
+				vol := p.LognormalVol(F, strike, T)
+				if normal {
+					vol = p.NormalVol(F, strike, T)
+				}
+				K = append(K, strike)
+				vols = append(vols, vol)
+			}
+
+			calibrate := Calibrate
+			if normal {
+				calibrate = CalibrateNormal
+			}
+			calibrated, err := calibrate(F, T, p.Beta, K, vols)
+			if err != nil {
+				t.Errorf("%+v: %s\n", p, err.Error())
+				continue
+			}
+			error := math.Abs(calibrated.Alpha-p.Alpha)/p.Alpha + math.Abs(calibrated.Rho-p.Rho) + math.Abs(calibrated.Nu-p.Nu)
+			if math.IsNaN(error) || error > testTolerance {
+				t.Errorf("normal=%v, %+v: calibrated %+v\n", normal, p, calibrated)
+			}
+		}
+	}
+}
+
+func TestAlphaFromATMVol(t *testing.T) {
+	const testTolerance float64 = 1.0e-12
+
+	for _, table := range testParams {
+		p, F, T := table.params, table.F, table.T
+		for _, normal := range []bool{false, true} {
+			atmVol := p.LognormalVol(F, F, T)
+			if normal {
+				atmVol = p.NormalVol(F, F, T)
+			}
+			alpha, err := AlphaFromATMVol(F, T, atmVol, p, normal)
+			error := math.Abs(alpha-p.Alpha) / p.Alpha
+			if err != nil || math.IsNaN(error) || error > testTolerance {
+				t.Errorf("normal=%v, %+v: alpha=%g, err=%v\n", normal, p, alpha, err)
+			}
+		}
+	}
+}
+
+func TestCalibrateErrors(t *testing.T) {
+	tables := []struct {
+		K    []float64
+		vols []float64
+		err  error
+	}{
+		{[]float64{90, 100}, []float64{0.2, 0.2}, ErrTooFewQuotes},
+		{[]float64{90, 100, 110}, []float64{0.2, 0.2}, ErrInvalidQuotes},
+		{[]float64{90, 100, 110}, []float64{0.2, -0.2, 0.2}, ErrInvalidQuotes},
+		{[]float64{110, 120, 130}, []float64{0.2, 0.2, 0.2}, ErrForwardNotBracketed},
+	}
+	for i, table := range tables {
+		if _, err := Calibrate(100, 1, 1, table.K, table.vols); err != table.err {
+			t.Errorf("case %d: expected %v, got %v\n", i, table.err, err)
+		}
+	}
+}
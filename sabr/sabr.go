@@ -0,0 +1,121 @@
+package sabr
+
+import (
+	"math"
+
+	"code.vegaprotocol.io/quant/bachelier"
+	"code.vegaprotocol.io/quant/bsformula"
+)
+
+// The SABR model of Hagan et al. (Managing smile risk, Wilmott, 2002) has the forward price F and its volatility alpha follow
+// dF = alpha F^beta dW, dalpha = nu alpha dZ with correlation rho between W and Z.
+// Options are priced with Black-76 (or Bachelier) at the implied volatility given by Hagan's approximation
+// and all functions take the discount factor D from option expiry back to today, e.g. D = exp(-rT).
+// The Black-76 prices use bsformula.Precise so that the smile and its sensitivities are smooth in the strike.
+
+// fdStep is the relative step used for the finite differences of the implied volatility
+const fdStep = 1e-5
+
+// Params collect the parameters of the SABR model
+type Params struct {
+	Alpha float64
+	Beta  float64
+	Rho   float64
+	Nu    float64
+}
+
+// zOverX returns z / x(z) where x(z) = ln((sqrt(1 - 2 rho z + z^2) + z - rho) / (1 - rho)), its limit is 1 as z goes to 0
+func zOverX(z, rho float64) float64 {
+	if math.Abs(z) < 1e-8 {
+		// expansion in z
+		return 1 - 0.5*rho*z
+	}
+	x := math.Log((math.Sqrt(1-2*rho*z+z*z) + z - rho) / (1 - rho))
+	return z / x
+}
+
+// LognormalVol returns Hagan's approximation of the Black-76 implied volatility for strike K and expiry T
+func (p Params) LognormalVol(F, K, T float64) float64 {
+	oneMinusBeta := 1 - p.Beta
+	logFK := math.Log(F / K)
+	fkBeta := math.Pow(F*K, 0.5*oneMinusBeta)
+	z := p.Nu / p.Alpha * fkBeta * logFK
+
+	logFK2 := logFK * logFK
+	denominator := fkBeta * (1 + oneMinusBeta*oneMinusBeta/24*logFK2 + math.Pow(oneMinusBeta, 4)/1920*logFK2*logFK2)
+	correction := 1 + (oneMinusBeta*oneMinusBeta/24*p.Alpha*p.Alpha/(fkBeta*fkBeta)+
+		0.25*p.Rho*p.Beta*p.Nu*p.Alpha/fkBeta+(2-3*p.Rho*p.Rho)/24*p.Nu*p.Nu)*T
+	return p.Alpha / denominator * zOverX(z, p.Rho) * correction
+}
+
+// NormalVol returns Hagan's approximation of the Bachelier (normal) implied volatility for strike K and expiry T
+func (p Params) NormalVol(F, K, T float64) float64 {
+	oneMinusBeta := 1 - p.Beta
+	logFK := math.Log(F / K)
+	fkBeta := math.Pow(F*K, 0.5*oneMinusBeta)
+	z := p.Nu / p.Alpha * fkBeta * logFK
+
+	logFK2 := logFK * logFK
+	numerator := p.Alpha * math.Pow(F*K, 0.5*p.Beta) * (1 + logFK2/24 + logFK2*logFK2/1920)
+	denominator := 1 + oneMinusBeta*oneMinusBeta/24*logFK2 + math.Pow(oneMinusBeta, 4)/1920*logFK2*logFK2
+	correction := 1 + (-p.Beta*(2-p.Beta)/24*p.Alpha*p.Alpha/(fkBeta*fkBeta)+
+		0.25*p.Rho*p.Beta*p.Nu*p.Alpha/fkBeta+(2-3*p.Rho*p.Rho)/24*p.Nu*p.Nu)*T
+	return numerator / denominator * zOverX(z, p.Rho) * correction
+}
+
+// CallPrice calculates the call option price with Black-76 at the SABR lognormal implied volatility
+func (p Params) CallPrice(F, K, D, T float64) float64 {
+	return bsformula.Precise.Black76CallPrice(F, K, D, p.LognormalVol(F, K, T), T)
+}
+
+// PutPrice calculates the put option price with Black-76 at the SABR lognormal implied volatility
+func (p Params) PutPrice(F, K, D, T float64) float64 {
+	return bsformula.Precise.Black76PutPrice(F, K, D, p.LognormalVol(F, K, T), T)
+}
+
+// NormalCallPrice calculates the call option price with Bachelier at the SABR normal implied volatility
+func (p Params) NormalCallPrice(F, K, D, T float64) float64 {
+	return bachelier.CallPrice(F, K, D, p.NormalVol(F, K, T), T)
+}
+
+// NormalPutPrice calculates the put option price with Bachelier at the SABR normal implied volatility
+func (p Params) NormalPutPrice(F, K, D, T float64) float64 {
+	return bachelier.PutPrice(F, K, D, p.NormalVol(F, K, T), T)
+}
+
+// volSensitivities returns the lognormal implied volatility and its partial derivatives w.r.t. F and alpha
+func (p Params) volSensitivities(F, K, T float64) (vol, dVoldF, dVoldAlpha float64) {
+	vol = p.LognormalVol(F, K, T)
+	h := fdStep * F
+	dVoldF = (p.LognormalVol(F+h, K, T) - p.LognormalVol(F-h, K, T)) / (2 * h)
+
+	up, down := p, p
+	h = fdStep * p.Alpha
+	up.Alpha += h
+	down.Alpha -= h
+	dVoldAlpha = (up.LognormalVol(F, K, T) - down.LognormalVol(F, K, T)) / (2 * h)
+	return
+}
+
+// CallDelta calculates the SABR delta of a call (partial derivative w.r.t. F), that is the Black-76 delta
+// plus the Black-76 vega times the change in the implied volatility along the smile.
+// The change in alpha correlated with the move in F is included as in Bartlett (Hedging under SABR model, Wilmott, 2006).
+func (p Params) CallDelta(F, K, D, T float64) float64 {
+	vol, dVoldF, dVoldAlpha := p.volSensitivities(F, K, T)
+	dVol := dVoldF + p.Rho*p.Nu/math.Pow(F, p.Beta)*dVoldAlpha
+	return bsformula.Precise.Black76CallDelta(F, K, D, vol, T) + bsformula.Black76Vega(F, K, D, vol, T)*dVol
+}
+
+// PutDelta calculates the SABR delta of a put (partial derivative w.r.t. F), see CallDelta
+func (p Params) PutDelta(F, K, D, T float64) float64 {
+	vol, dVoldF, dVoldAlpha := p.volSensitivities(F, K, T)
+	dVol := dVoldF + p.Rho*p.Nu/math.Pow(F, p.Beta)*dVoldAlpha
+	return bsformula.Precise.Black76PutDelta(F, K, D, vol, T) + bsformula.Black76Vega(F, K, D, vol, T)*dVol
+}
+
+// Vega calculates the SABR vega (partial derivative w.r.t. alpha)
+// Note that it's identical for both puts and calls
+func (p Params) Vega(F, K, D, T float64) float64 {
+	vol, _, dVoldAlpha := p.volSensitivities(F, K, T)
+	return bsformula.Black76Vega(F, K, D, vol, T) * dVoldAlpha
+}
@@ -0,0 +1,127 @@
+package sabr
+
+import (
+	"math"
+	"testing"
+
+	"golang.org/x/exp/rand"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+var testParams = []struct {
+	params Params
+	F      float64
+	T      float64
+}{
+	{Params{Alpha: 0.2, Beta: 1.0, Rho: -0.3, Nu: 0.6}, 100, 1.0},   // lognormal backbone
+	{Params{Alpha: 0.04, Beta: 0.5, Rho: -0.2, Nu: 0.4}, 0.04, 2.0}, // rates
+	{Params{Alpha: 0.01, Beta: 0.0, Rho: 0.1, Nu: 0.3}, 0.03, 0.5},  // normal backbone
+	{Params{Alpha: 0.8, Beta: 1.0, Rho: 0.3, Nu: 1.5}, 30000, 0.1},  // crypto
+	{Params{Alpha: 0.3, Beta: 0.7, Rho: -0.6, Nu: 0.8}, 1.0, 0.25},
+}
+
+// TestVolLimits checks the at-the-money limit and the cases without stochastic volatility
+func TestVolLimits(t *testing.T) {
+	const testTolerance float64 = 1.0e-7
+
+	for _, table := range testParams {
+		p, F, T := table.params, table.F, table.T
+		for _, vol := range []func(F, K, T float64) float64{p.LognormalVol, p.NormalVol} {
+			atm := vol(F, F, T)
+			error := math.Abs(vol(F, F*(1+1e-9), T)-atm) + math.Abs(vol(F, F*(1-1e-9), T)-atm)
+			if math.IsNaN(error) || error > testTolerance*atm {
+				t.Errorf("%+v: vol isn't continuous at the money, error=%g\n", p, error)
+			}
+		}
+	}
+
+	// nu = 0 and beta = 1 is Black-76 with volatility alpha, nu = 0 and beta = 0 is Bachelier with volatility alpha
+	black := Params{Alpha: 0.25, Beta: 1, Rho: 0, Nu: 0}
+	bachelier := Params{Alpha: 0.01, Beta: 0, Rho: 0, Nu: 0}
+	for _, K := range []float64{50, 80, 100, 125, 200} {
+		if error := math.Abs(black.LognormalVol(100, K, 1) - 0.25); error > 1e-14 {
+			t.Errorf("K=%g: lognormal vol %g, expected 0.25\n", K, black.LognormalVol(100, K, 1))
+		}
+		// Hagan's normal vol is exact up to the fourth order in ln(F/K)
+		if error := math.Abs(bachelier.NormalVol(100, K, 1) - 0.01); error > 1e-5 {
+			t.Errorf("K=%g: normal vol %g, expected 0.01\n", K, bachelier.NormalVol(100, K, 1))
+		}
+	}
+}
+
+// TestPricesAgainstMC checks the Black-76 and Bachelier prices at Hagan's implied vols against a Monte Carlo simulation of the model
+func TestPricesAgainstMC(t *testing.T) {
+	const numPaths, numSteps int = 20000, 200
+	const D = 0.97
+	rng := rand.New(rand.NewSource(1))
+	normal := distuv.Normal{Mu: 0, Sigma: 1, Src: rng}
+
+	for _, table := range testParams[:2] {
+		p, F0, T := table.params, table.F, table.T
+		dt := T / float64(numSteps)
+		strikes := []float64{0.8 * F0, F0, 1.2 * F0}
+		calls := make([]float64, len(strikes))
+		puts := make([]float64, len(strikes))
+		for path := 0; path < numPaths; path++ {
+			F, alpha := F0, p.Alpha
+			for step := 0; step < numSteps; step++ {
+				z1 := normal.Rand()
+				z2 := p.Rho*z1 + math.Sqrt(1-p.Rho*p.Rho)*normal.Rand()
+				F += alpha * math.Pow(math.Max(F, 0), p.Beta) * math.Sqrt(dt) * z1
+				F = math.Max(F, 0)
+				alpha *= math.Exp(p.Nu*math.Sqrt(dt)*z2 - 0.5*p.Nu*p.Nu*dt)
+			}
+			for i, K := range strikes {
+				calls[i] += D * math.Max(F-K, 0) / float64(numPaths)
+				puts[i] += D * math.Max(K-F, 0) / float64(numPaths)
+			}
+		}
+
+		for i, K := range strikes {
+			tolerance := 0.05 * p.CallPrice(F0, F0, D, T)
+			for _, prices := range [][2]float64{
+				{p.CallPrice(F0, K, D, T), p.PutPrice(F0, K, D, T)},
+				{p.NormalCallPrice(F0, K, D, T), p.NormalPutPrice(F0, K, D, T)},
+			} {
+				error := math.Abs(prices[0]-calls[i]) + math.Abs(prices[1]-puts[i])
+				if math.IsNaN(error) || error > tolerance {
+					t.Errorf("%+v, K=%g: call=%g, put=%g, MC call=%g, MC put=%g\n", p, K, prices[0], prices[1], calls[i], puts[i])
+				}
+			}
+		}
+	}
+}
+
+// TestDeltaAndVegaVsFiniteDifference checks the SABR delta against the change in price when F moves along with alpha
+// and the vega against the change in price when alpha moves
+func TestDeltaAndVegaVsFiniteDifference(t *testing.T) {
+	const testTolerance float64 = 1.0e-5
+	const D = 0.95
+
+	for _, table := range testParams {
+		p, F, T := table.params, table.F, table.T
+		for _, K := range []float64{0.7 * F, 0.9 * F, F, 1.1 * F, 1.4 * F} {
+			h := 1e-4 * F
+			up, down := p, p
+			up.Alpha += p.Rho * p.Nu / math.Pow(F, p.Beta) * h
+			down.Alpha -= p.Rho * p.Nu / math.Pow(F, p.Beta) * h
+			callDelta := (up.CallPrice(F+h, K, D, T) - down.CallPrice(F-h, K, D, T)) / (2 * h)
+			putDelta := (up.PutPrice(F+h, K, D, T) - down.PutPrice(F-h, K, D, T)) / (2 * h)
+
+			h = 1e-4 * p.Alpha
+			up, down = p, p
+			up.Alpha += h
+			down.Alpha -= h
+			vega := (up.CallPrice(F, K, D, T) - down.CallPrice(F, K, D, T)) / (2 * h)
+
+			error := math.Abs(p.CallDelta(F, K, D, T)-callDelta) + math.Abs(p.PutDelta(F, K, D, T)-putDelta)
+			if math.IsNaN(error) || error > testTolerance {
+				t.Errorf("%+v, K=%g: delta error=%g\n", p, K, error)
+			}
+			error = math.Abs(p.Vega(F, K, D, T)-vega) / math.Max(math.Abs(vega), F*math.Sqrt(T))
+			if math.IsNaN(error) || error > testTolerance {
+				t.Errorf("%+v, K=%g: vega=%g, finite difference=%g\n", p, K, p.Vega(F, K, D, T), vega)
+			}
+		}
+	}
+}
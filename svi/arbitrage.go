@@ -0,0 +1,91 @@
+package svi
+
+import (
+	"math"
+	"sort"
+)
+
+// ArbitrageType identifies the static arbitrage found in a smile or across expiries
+type ArbitrageType int
+
+const (
+	// Butterfly arbitrage means the implied risk-neutral density of a slice is negative
+	Butterfly ArbitrageType = iota
+	// Calendar arbitrage means the total variance at some log-moneyness decreases from one expiry to the next
+	Calendar
+)
+
+// Violation records where an arbitrage check failed: the log-moneyness k, the expiry of the (earlier) slice
+// and by how much, i.e. minus Gatheral's g(k) for butterfly arbitrage and the decrease in total variance for calendar arbitrage
+type Violation struct {
+	Type         ArbitrageType
+	Expiry       float64
+	LogMoneyness float64
+	Amount       float64
+}
+
+// G returns Gatheral's g(k) = (1 - k w1/(2w))^2 - w1^2/4 (1/w + 1/4) + w2/2 where w1 and w2 are the first and second
+// derivatives of the total variance w, the slice is free of butterfly arbitrage if g is non-negative as the
+// risk-neutral density of the log-moneyness is then non-negative
+func (p Raw) G(k float64) float64 {
+	w, w1, w2 := p.derivatives(k)
+	a := 1 - 0.5*k*w1/w
+	return a*a - 0.25*w1*w1*(1/w+0.25) + 0.5*w2
+}
+
+// ButterflyArbitrage returns the points out of n equally spaced ones in [kMin, kMax] at which g(k) is negative
+// (or the total variance isn't positive)
+func (s Slice) ButterflyArbitrage(kMin, kMax float64, n int) []Violation {
+	var violations []Violation
+	for _, k := range grid(kMin, kMax, n) {
+		g := s.Params.G(k)
+		if s.Params.TotalVariance(k) <= 0 {
+			g = math.Inf(-1)
+		}
+		if g < 0 || math.IsNaN(g) {
+			violations = append(violations, Violation{Type: Butterfly, Expiry: s.Expiry, LogMoneyness: k, Amount: -g})
+		}
+	}
+	return violations
+}
+
+// CalendarArbitrage returns the points out of n equally spaced ones in [kMin, kMax] at which the total variance
+// decreases from one expiry to the next, the slices needn't be sorted by expiry
+func CalendarArbitrage(slices []Slice, kMin, kMax float64, n int) []Violation {
+	sorted := make([]Slice, len(slices))
+	copy(sorted, slices)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Expiry < sorted[j].Expiry })
+
+	var violations []Violation
+	for i := 1; i < len(sorted); i++ {
+		for _, k := range grid(kMin, kMax, n) {
+			decrease := sorted[i-1].Params.TotalVariance(k) - sorted[i].Params.TotalVariance(k)
+			if decrease > 0 {
+				violations = append(violations, Violation{Type: Calendar, Expiry: sorted[i-1].Expiry, LogMoneyness: k, Amount: decrease})
+			}
+		}
+	}
+	return violations
+}
+
+// Arbitrage returns the butterfly arbitrage of each slice and the calendar arbitrage between them,
+// checked at n equally spaced points in [kMin, kMax]
+func Arbitrage(slices []Slice, kMin, kMax float64, n int) []Violation {
+	var violations []Violation
+	for _, s := range slices {
+		violations = append(violations, s.ButterflyArbitrage(kMin, kMax, n)...)
+	}
+	return append(violations, CalendarArbitrage(slices, kMin, kMax, n)...)
+}
+
+// grid returns n equally spaced points from kMin to kMax
+func grid(kMin, kMax float64, n int) []float64 {
+	if n < 2 {
+		return []float64{0.5 * (kMin + kMax)}
+	}
+	points := make([]float64, n)
+	for i := range points {
+		points[i] = kMin + (kMax-kMin)*float64(i)/float64(n-1)
+	}
+	return points
+}
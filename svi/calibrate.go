@@ -0,0 +1,215 @@
+package svi
+
+import (
+	"errors"
+	"math"
+
+	"code.vegaprotocol.io/quant/bsformula"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/optimize"
+)
+
+var (
+	// ErrTooFewQuotes is returned when a slice has fewer quotes than the 5 SVI parameters
+	ErrTooFewQuotes = errors.New("at least 5 quotes are needed to calibrate an SVI slice")
+	// ErrInvalidQuotes is returned when the strikes and vols differ in length or aren't positive
+	ErrInvalidQuotes = errors.New("strikes and volatilities must be positive and of the same length")
+	// ErrCalibrationFailed is returned when the optimiser doesn't find parameters
+	ErrCalibrationFailed = errors.New("SVI calibration failed")
+)
+
+// arbitrageCheckPoints is the number of log-moneyness points at which the calibrated slices are checked for arbitrage
+const arbitrageCheckPoints = 201
+
+// Quotes are the implied volatilities of one expiry (in years) on the underlying with the given forward price
+type Quotes struct {
+	Expiry  float64
+	Forward float64
+	Strikes []float64
+	Vols    []float64
+}
+
+// Calibrate fits the raw SVI parameters to the implied volatilities by least squares
+// and returns the slice along with any butterfly arbitrage within the range of quoted strikes.
+// The fit first solves for (A, B Rho, B) by linear least squares for each (M, Sigma) in the total variance
+// as in the quasi-explicit method of Zeliade and then refines all the parameters on the implied volatilities
+// subject to B >= 0, |Rho| < 1, Sigma > 0 and a positive minimum total variance.
+func Calibrate(quotes Quotes) (Slice, []Violation, error) {
+	n := len(quotes.Strikes)
+	if len(quotes.Vols) != n || !(quotes.Expiry > 0) || !(quotes.Forward > 0) {
+		return Slice{}, nil, ErrInvalidQuotes
+	}
+	if n < 5 {
+		return Slice{}, nil, ErrTooFewQuotes
+	}
+	k := make([]float64, n)
+	w := make([]float64, n)
+	kMin, kMax := math.Inf(1), math.Inf(-1)
+	for i := range k {
+		if !(quotes.Strikes[i] > 0) || !(quotes.Vols[i] > 0) || math.IsInf(quotes.Vols[i], 0) {
+			return Slice{}, nil, ErrInvalidQuotes
+		}
+		k[i] = math.Log(quotes.Strikes[i] / quotes.Forward)
+		w[i] = quotes.Vols[i] * quotes.Vols[i] * quotes.Expiry
+		kMin, kMax = math.Min(kMin, k[i]), math.Max(kMax, k[i])
+	}
+
+	params, err := fitQuasiExplicit(k, w)
+	if err != nil {
+		return Slice{}, nil, err
+	}
+	params, err = refine(params, k, quotes.Vols, quotes.Expiry)
+	if err != nil {
+		return Slice{}, nil, err
+	}
+
+	slice := Slice{Params: params, Expiry: quotes.Expiry, Forward: quotes.Forward}
+	return slice, slice.ButterflyArbitrage(kMin, kMax, arbitrageCheckPoints), nil
+}
+
+// CalibrateSurface calibrates a slice per expiry and returns them along with any butterfly arbitrage in each slice
+// and calendar arbitrage between the slices within the range of quoted strikes
+func CalibrateSurface(quotes []Quotes) ([]Slice, []Violation, error) {
+	slices := make([]Slice, len(quotes))
+	kMin, kMax := math.Inf(1), math.Inf(-1)
+	for i, q := range quotes {
+		var err error
+		if slices[i], _, err = Calibrate(q); err != nil {
+			return nil, nil, err
+		}
+		for _, K := range q.Strikes {
+			kMin, kMax = math.Min(kMin, math.Log(K/q.Forward)), math.Max(kMax, math.Log(K/q.Forward))
+		}
+	}
+	return slices, Arbitrage(slices, kMin, kMax, arbitrageCheckPoints), nil
+}
+
+// CalibrateToPrices calibrates the slice of expiry T to Black-Scholes-Merton option prices on the underlying S
+// with interest rate r and yield q, the implied volatilities are found with bsformula.Precise
+func CalibrateToPrices(S, r, q, T float64, K, prices []float64, isCall []bool) (Slice, []Violation, error) {
+	maturities := make([]float64, len(K))
+	for i := range maturities {
+		maturities[i] = T
+	}
+	vols := make([]float64, len(K))
+	if err := bsformula.Precise.ImpliedVols(vols, S, r, q, K, maturities, prices, isCall, 1); err != nil {
+		return Slice{}, nil, err
+	}
+	return Calibrate(Quotes{Expiry: T, Forward: S * math.Exp((r-q)*T), Strikes: K, Vols: vols})
+}
+
+// fitQuasiExplicit minimises over (M, Sigma) the residual of the linear least squares fit of
+// w = a + d y + c sqrt(y^2 + 1) with y = (k - M) / Sigma, where c = B Sigma and d = B Rho Sigma
+func fitQuasiExplicit(k, w []float64) (Raw, error) {
+	n := len(k)
+	design := mat.NewDense(n, 3, nil)
+	target := mat.NewVecDense(n, w)
+	var coef, residual mat.VecDense
+
+	linearFit := func(m, sigma float64) (Raw, float64) {
+		for i := range k {
+			y := (k[i] - m) / sigma
+			design.Set(i, 0, 1)
+			design.Set(i, 1, y)
+			design.Set(i, 2, math.Sqrt(y*y+1))
+		}
+		if err := coef.SolveVec(design, target); err != nil {
+			return Raw{}, math.Inf(1)
+		}
+		residual.MulVec(design, &coef)
+		residual.SubVec(&residual, target)
+		a, d, c := coef.AtVec(0), coef.AtVec(1), coef.AtVec(2)
+		return Raw{A: a, B: c / sigma, Rho: d / c, M: m, Sigma: sigma}, mat.Dot(&residual, &residual)
+	}
+
+	// start at the lowest quoted variance
+	iMin := 0
+	for i := range w {
+		if w[i] < w[iMin] {
+			iMin = i
+		}
+	}
+	problem := optimize.Problem{
+		Func: func(x []float64) float64 {
+			_, sse := linearFit(x[0], math.Exp(x[1]))
+			return sse
+		},
+	}
+
+	best := math.Inf(1)
+	var bestParams Raw
+	for _, sigma := range []float64{0.05, 0.2, 0.5} {
+		result, err := optimize.Minimize(problem, []float64{k[iMin], math.Log(sigma)}, optimizerSettings(), &optimize.NelderMead{})
+		if result == nil {
+			if err == nil {
+				err = ErrCalibrationFailed
+			}
+			return Raw{}, err
+		}
+		if result.F < best {
+			best = result.F
+			bestParams, _ = linearFit(result.X[0], math.Exp(result.X[1]))
+		}
+	}
+	if math.IsInf(best, 0) || math.IsNaN(best) {
+		return Raw{}, ErrCalibrationFailed
+	}
+	return bestParams, nil
+}
+
+// refine minimises the squared implied volatility errors over all the raw parameters, starting from params
+// projected on the parameters without arbitrage in the wings
+func refine(params Raw, k, vols []float64, T float64) (Raw, error) {
+	meanW := 0.0
+	for _, vol := range vols {
+		meanW += vol * vol * T / float64(len(vols))
+	}
+
+	// project the starting point on B > 0, |Rho| < 1 and a positive minimum variance
+	b := math.Max(params.B, 1e-6*meanW)
+	rho := math.Max(math.Min(params.Rho, 0.999), -0.999)
+	if math.IsNaN(rho) {
+		rho = 0
+	}
+	minW := params.MinTotalVariance()
+	if !(minW > 0) {
+		minW = 1e-3 * meanW
+	}
+
+	toRaw := func(x []float64) Raw {
+		b, rho, sigma := math.Exp(x[1]), math.Tanh(x[2]), math.Exp(x[4])
+		return Raw{A: math.Exp(x[0]) - b*sigma*math.Sqrt(1-rho*rho), B: b, Rho: rho, M: x[3], Sigma: sigma}
+	}
+	problem := optimize.Problem{
+		Func: func(x []float64) float64 {
+			p := toRaw(x)
+			sse := 0.0
+			for i := range k {
+				e := math.Sqrt(p.TotalVariance(k[i])/T) - vols[i]
+				sse += e * e
+			}
+			return sse
+		},
+	}
+	x0 := []float64{math.Log(minW), math.Log(b), math.Atanh(rho), params.M, math.Log(params.Sigma)}
+	result, err := optimize.Minimize(problem, x0, optimizerSettings(), &optimize.NelderMead{})
+	if result == nil || math.IsNaN(result.F) {
+		if err == nil {
+			err = ErrCalibrationFailed
+		}
+		return Raw{}, err
+	}
+	// keep the starting point if the optimiser made it worse, e.g. when it is already exact
+	if problem.Func(x0) <= result.F {
+		return toRaw(x0), nil
+	}
+	return toRaw(result.X), nil
+}
+
+func optimizerSettings() *optimize.Settings {
+	return &optimize.Settings{
+		Converger:       &optimize.FunctionConverge{Absolute: 1e-24, Relative: 1e-12, Iterations: 200},
+		MajorIterations: 20000,
+	}
+}
@@ -0,0 +1,119 @@
+package svi
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/bsformula"
+)
+
+// makeQuotes returns the quotes of the slice at numStrikes log-moneyness points equally spaced in [-width, width]
+func makeQuotes(params Raw, T, F, width float64, numStrikes int) Quotes {
+	quotes := Quotes{Expiry: T, Forward: F}
+	for i := 0; i < numStrikes; i++ {
+		k := width * (-1 + 2*float64(i)/float64(numStrikes-1))
+		quotes.Strikes = append(quotes.Strikes, F*math.Exp(k))
+		quotes.Vols = append(quotes.Vols, math.Sqrt(params.TotalVariance(k)/T))
+	}
+	return quotes
+}
+
+func TestCalibrateRecoversParameters(t *testing.T) {
+	const testTolerance float64 = 1.0e-6
+
+	for _, table := range testParams[:3] {
+		quotes := makeQuotes(table.params, table.T, 100, 0.5, 21)
+		slice, violations, err := Calibrate(quotes)
+		if err != nil {
+			t.Errorf("%+v: %s\n", table.params, err.Error())
+			continue
+		}
+		if len(violations) > 0 {
+			t.Errorf("%+v: unexpected arbitrage %+v\n", table.params, violations[0])
+		}
+		for i, K := range quotes.Strikes {
+			error := math.Abs(slice.Vol(K, table.T) - quotes.Vols[i])
+			if math.IsNaN(error) || error > testTolerance {
+				t.Errorf("%+v: K=%g, vol=%g, quote=%g\n", table.params, K, slice.Vol(K, table.T), quotes.Vols[i])
+			}
+		}
+		p := slice.Params
+		error := math.Abs(p.A-table.params.A) + math.Abs(p.B-table.params.B) + math.Abs(p.Rho-table.params.Rho) +
+			math.Abs(p.M-table.params.M) + math.Abs(p.Sigma-table.params.Sigma)
+		if math.IsNaN(error) || error > 1e3*testTolerance {
+			t.Errorf("%+v: calibrated %+v\n", table.params, p)
+		}
+	}
+}
+
+func TestCalibrateToPrices(t *testing.T) {
+	const testTolerance float64 = 1.0e-6
+	const S, r, q, T = 100.0, 0.03, 0.01, 0.5
+	params := Raw{A: 0.02, B: 0.3, Rho: -0.5, M: 0.05, Sigma: 0.15}
+	F := S * math.Exp((r-q)*T)
+	quotes := makeQuotes(params, T, F, 0.5, 15)
+
+	prices := make([]float64, len(quotes.Strikes))
+	isCall := make([]bool, len(quotes.Strikes))
+	for i, K := range quotes.Strikes {
+		isCall[i] = K >= F
+		prices[i] = bsformula.Precise.PutPrice(S, K, r, q, quotes.Vols[i], T)
+		if isCall[i] {
+			prices[i] = bsformula.Precise.CallPrice(S, K, r, q, quotes.Vols[i], T)
+		}
+	}
+
+	slice, _, err := CalibrateToPrices(S, r, q, T, quotes.Strikes, prices, isCall)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	for i, K := range quotes.Strikes {
+		error := math.Abs(slice.Vol(K, T) - quotes.Vols[i])
+		if math.IsNaN(error) || error > testTolerance {
+			t.Errorf("K=%g, vol=%g, quote=%g\n", K, slice.Vol(K, T), quotes.Vols[i])
+		}
+	}
+}
+
+func TestCalibrateReportsArbitrage(t *testing.T) {
+	// Vogt's slice has butterfly arbitrage and the second expiry has less variance than the first
+	quotes := []Quotes{
+		makeQuotes(vogt, 1.0, 1.0, 1.5, 31),
+		makeQuotes(Raw{A: 0.005, B: 0.05, Rho: 0.0, M: 0.0, Sigma: 0.1}, 2.0, 1.0, 1.5, 31),
+	}
+	_, violations, err := CalibrateSurface(quotes)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	found := map[ArbitrageType]bool{}
+	for _, v := range violations {
+		found[v.Type] = true
+		if !(v.Amount > 0) {
+			t.Errorf("violation with non-positive amount %+v", v)
+		}
+	}
+	if !found[Butterfly] || !found[Calendar] {
+		t.Errorf("Expected butterfly and calendar arbitrage, got %+v", found)
+	}
+
+	// the arbitrage-free slices on their own
+	_, violations, err = CalibrateSurface([]Quotes{
+		makeQuotes(testParams[0].params, testParams[0].T, 1.0, 0.5, 21),
+		makeQuotes(Raw{A: 0.1, B: 0.4, Rho: -0.4, M: 0.1, Sigma: 0.2}, 2.0, 1.0, 0.5, 21),
+	})
+	if err != nil || len(violations) > 0 {
+		t.Errorf("Expected no arbitrage, got %v, %+v", err, violations)
+	}
+}
+
+func TestCalibrateErrors(t *testing.T) {
+	quotes := makeQuotes(testParams[0].params, 1, 100, 0.5, 4)
+	if _, _, err := Calibrate(quotes); err != ErrTooFewQuotes {
+		t.Errorf("Expected ErrTooFewQuotes, got %v", err)
+	}
+	quotes = makeQuotes(testParams[0].params, 1, 100, 0.5, 7)
+	quotes.Vols[3] = -0.1
+	if _, _, err := Calibrate(quotes); err != ErrInvalidQuotes {
+		t.Errorf("Expected ErrInvalidQuotes, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package svi
+
+import (
+	"errors"
+	"math"
+
+	"code.vegaprotocol.io/quant/bsformula"
+)
+
+// The stochastic volatility inspired (SVI) parameterisation of Gatheral gives the total implied variance
+// w(k) = sigma_BS(k)^2 T of one expiry as a function of the log-moneyness k = ln(K/F) where F is the forward price.
+
+// ErrJWNotInvertible is returned when SVI-JW parameters don't correspond to any raw SVI parameters
+var ErrJWNotInvertible = errors.New("SVI-JW parameters don't correspond to raw SVI parameters")
+
+// Raw are the raw SVI parameters, the total variance is
+// w(k) = A + B (Rho (k - M) + sqrt((k - M)^2 + Sigma^2))
+// with B >= 0, |Rho| < 1, Sigma > 0 and A + B Sigma sqrt(1 - Rho^2) >= 0 so that w is non-negative.
+type Raw struct {
+	A     float64
+	B     float64
+	Rho   float64
+	M     float64
+	Sigma float64
+}
+
+// JW are the SVI jump-wings parameters of an expiry T, which have a direct interpretation in terms of the smile:
+// V is the at-the-money variance, Psi the at-the-money skew, P and C the slopes of the left (put) and right (call)
+// wings and VTilde the minimum implied variance.
+type JW struct {
+	V      float64
+	Psi    float64
+	P      float64
+	C      float64
+	VTilde float64
+}
+
+// TotalVariance returns the total implied variance w(k) at log-moneyness k
+func (p Raw) TotalVariance(k float64) float64 {
+	x := k - p.M
+	return p.A + p.B*(p.Rho*x+math.Sqrt(x*x+p.Sigma*p.Sigma))
+}
+
+// derivatives returns the total variance w(k) and its first two derivatives w.r.t. k
+func (p Raw) derivatives(k float64) (w, w1, w2 float64) {
+	x := k - p.M
+	r := math.Sqrt(x*x + p.Sigma*p.Sigma)
+	w = p.A + p.B*(p.Rho*x+r)
+	w1 = p.B * (p.Rho + x/r)
+	w2 = p.B * p.Sigma * p.Sigma / (r * r * r)
+	return
+}
+
+// MinTotalVariance returns the minimum over k of the total variance
+func (p Raw) MinTotalVariance() float64 {
+	return p.A + p.B*p.Sigma*math.Sqrt(1-p.Rho*p.Rho)
+}
+
+// ToJW converts the raw parameters to the jump-wings parameters for expiry T
+func (p Raw) ToJW(T float64) JW {
+	w := p.TotalVariance(0)
+	sqrtW := math.Sqrt(w)
+	return JW{
+		V:      w / T,
+		Psi:    0.5 * p.B / sqrtW * (p.Rho - p.M/math.Sqrt(p.M*p.M+p.Sigma*p.Sigma)),
+		P:      p.B * (1 - p.Rho) / sqrtW,
+		C:      p.B * (1 + p.Rho) / sqrtW,
+		VTilde: p.MinTotalVariance() / T,
+	}
+}
+
+// ToRaw converts the jump-wings parameters for expiry T to the raw parameters,
+// see Gatheral and Jacquier, Arbitrage-free SVI volatility surfaces, Quantitative Finance, 2014.
+func (j JW) ToRaw(T float64) (Raw, error) {
+	w := j.V * T
+	sqrtW := math.Sqrt(w)
+	b := 0.5 * sqrtW * (j.C + j.P)
+	if !(b > 0) || !(w > 0) {
+		return Raw{}, ErrJWNotInvertible
+	}
+	rho := 1 - j.P*sqrtW/b
+	beta := rho - 2*j.Psi*sqrtW/b
+	if !(math.Abs(rho) < 1) || !(math.Abs(beta) <= 1) {
+		return Raw{}, ErrJWNotInvertible
+	}
+
+	var m, sigma float64
+	sqrtOneMinusRho2 := math.Sqrt(1 - rho*rho)
+	if math.Abs(beta-rho) < 1e-12 {
+		// the smile is centered at the money, the skew comes from rho alone
+		if rho == 0 {
+			return Raw{}, ErrJWNotInvertible
+		}
+		sigma = (j.V - j.VTilde) * T / (b * (1 - sqrtOneMinusRho2))
+	} else {
+		if beta == 0 {
+			return Raw{}, ErrJWNotInvertible
+		}
+		alpha := math.Copysign(math.Sqrt(1/(beta*beta)-1), beta)
+		m = (j.V - j.VTilde) * T / (b * (-rho + math.Copysign(math.Sqrt(1+alpha*alpha), alpha) - alpha*sqrtOneMinusRho2))
+		sigma = alpha * m
+	}
+	if !(sigma > 0) {
+		return Raw{}, ErrJWNotInvertible
+	}
+	return Raw{A: j.VTilde*T - b*sigma*sqrtOneMinusRho2, B: b, Rho: rho, M: m, Sigma: sigma}, nil
+}
+
+// Slice is an SVI smile for a single expiry (in years) on the underlying with the given forward price.
+// It implements interfaces.VolatilitySurface with the implied volatilities of the expiry used for all maturities.
+type Slice struct {
+	Params  Raw
+	Expiry  float64
+	Forward float64
+}
+
+// LogMoneyness returns ln(K/F) for the strike K
+func (s Slice) LogMoneyness(K float64) float64 {
+	return math.Log(K / s.Forward)
+}
+
+// Vol returns the implied volatility at strike K, the maturity T is ignored as the slice holds a single expiry
+func (s Slice) Vol(K, T float64) float64 {
+	return math.Sqrt(math.Max(s.Params.TotalVariance(s.LogMoneyness(K)), 0) / s.Expiry)
+}
+
+// CallPrice returns the Black-Scholes-Merton price of a call on the underlying S with strike K, maturity T,
+// interest rate r and yield q using the volatility from the slice
+func (s Slice) CallPrice(S, K, r, q, T float64) float64 {
+	return bsformula.BSMCallPrice(S, K, r, q, s.Vol(K, T), T)
+}
+
+// PutPrice returns the Black-Scholes-Merton price of a put on the underlying S with strike K, maturity T,
+// interest rate r and yield q using the volatility from the slice
+func (s Slice) PutPrice(S, K, r, q, T float64) float64 {
+	return bsformula.BSMPutPrice(S, K, r, q, s.Vol(K, T), T)
+}
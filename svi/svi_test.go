@@ -0,0 +1,101 @@
+package svi
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/bsformula"
+	"code.vegaprotocol.io/quant/interfaces"
+)
+
+// the slice can be used as the volatility input to the risk models
+var _ interfaces.VolatilitySurface = Slice{}
+
+// the slice of Axel Vogt's example which has butterfly arbitrage, see Gatheral and Jacquier (2014)
+var vogt = Raw{A: -0.0410, B: 0.1331, Rho: 0.3060, M: 0.3586, Sigma: 0.4153}
+
+var testParams = []struct {
+	params Raw
+	T      float64
+}{
+	{Raw{A: 0.04, B: 0.4, Rho: -0.4, M: 0.1, Sigma: 0.2}, 1.0},
+	{Raw{A: 0.001, B: 0.05, Rho: -0.7, M: -0.02, Sigma: 0.05}, 0.1},
+	{Raw{A: 0.1, B: 0.2, Rho: 0.3, M: 0.0, Sigma: 0.3}, 2.0},
+	{vogt, 1.0},
+}
+
+func TestJWRoundTrip(t *testing.T) {
+	const testTolerance float64 = 1.0e-10
+
+	for _, table := range testParams {
+		jw := table.params.ToJW(table.T)
+		raw, err := jw.ToRaw(table.T)
+		if err != nil {
+			t.Errorf("%+v: %s\n", table.params, err.Error())
+			continue
+		}
+		error := math.Abs(raw.A-table.params.A) + math.Abs(raw.B-table.params.B) + math.Abs(raw.Rho-table.params.Rho) +
+			math.Abs(raw.M-table.params.M) + math.Abs(raw.Sigma-table.params.Sigma)
+		if math.IsNaN(error) || error > testTolerance {
+			t.Errorf("%+v: round trip gives %+v\n", table.params, raw)
+		}
+
+		// the at-the-money variance and the minimum variance
+		if math.Abs(jw.V*table.T-table.params.TotalVariance(0)) > testTolerance {
+			t.Errorf("%+v: at-the-money variance %g\n", table.params, jw.V)
+		}
+		kMin := table.params.M - table.params.Rho*table.params.Sigma/math.Sqrt(1-table.params.Rho*table.params.Rho)
+		if math.Abs(jw.VTilde*table.T-table.params.TotalVariance(kMin)) > testTolerance {
+			t.Errorf("%+v: minimum variance %g\n", table.params, jw.VTilde)
+		}
+	}
+
+	if _, err := (JW{V: 0.04, Psi: 0, P: 0.2, C: 0.2, VTilde: 0.04}).ToRaw(1); err != ErrJWNotInvertible {
+		t.Errorf("Expected ErrJWNotInvertible, got %v", err)
+	}
+}
+
+// TestGMatchesDensity checks that g(k) has the sign of the density implied by the second derivative
+// of the call prices w.r.t. the strike
+func TestGMatchesDensity(t *testing.T) {
+	const F, T, h = 1.0, 1.0, 1e-4
+
+	for _, table := range testParams {
+		slice := Slice{Params: table.params, Expiry: table.T, Forward: F}
+		price := func(K float64) float64 {
+			return bsformula.Precise.Black76CallPrice(F, K, 1, slice.Vol(K, T), table.T)
+		}
+		for k := -1.5; k <= 1.5; k += 0.05 {
+			g := table.params.G(k)
+			K := F * math.Exp(k)
+			density := (price(K+h) - 2*price(K) + price(K-h)) / (h * h)
+			if math.Abs(g) > 1e-3 && (g > 0) != (density > 0) {
+				t.Errorf("%+v: k=%g, g=%g, density=%g\n", table.params, k, g, density)
+			}
+		}
+	}
+}
+
+func TestSliceVolAndPrices(t *testing.T) {
+	const testTolerance float64 = 1.0e-14
+	const S, r, q = 100.0, 0.03, 0.01
+
+	for _, table := range testParams[:3] {
+		slice := Slice{Params: table.params, Expiry: table.T, Forward: S * math.Exp((r-q)*table.T)}
+		for _, K := range []float64{50, 90, 100, 110, 200} {
+			vol := slice.Vol(K, table.T)
+			expected := math.Sqrt(table.params.TotalVariance(math.Log(K/slice.Forward)) / table.T)
+			if math.Abs(vol-expected) > testTolerance {
+				t.Errorf("K=%g: vol=%g, expected=%g\n", K, vol, expected)
+			}
+			call := slice.CallPrice(S, K, r, q, table.T)
+			if math.Abs(call-bsformula.BSMCallPrice(S, K, r, q, vol, table.T)) > testTolerance*S {
+				t.Errorf("K=%g: call price %g\n", K, call)
+			}
+			put := slice.PutPrice(S, K, r, q, table.T)
+			if math.Abs(put-bsformula.BSMPutPrice(S, K, r, q, vol, table.T)) > testTolerance*S {
+				t.Errorf("K=%g: put price %g\n", K, put)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package volatility
+
+import (
+	"math"
+
+	"code.vegaprotocol.io/quant/misc"
+	"code.vegaprotocol.io/quant/riskmodelbs"
+
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// Calibration holds the maximum likelihood estimates of the Black-Scholes model parameters from a price history
+// along with their standard errors and confidence intervals
+type Calibration struct {
+	Params        riskmodelbs.ModelParamsBS
+	MuStdErr      float64
+	SigmaStdErr   float64
+	MuInterval    misc.ConfidenceInterval
+	SigmaInterval misc.ConfidenceInterval
+	Observations  int
+}
+
+// CalibrateBS fits the geometric brownian motion to the prices by maximum likelihood: the log-returns r_i over the
+// times dt_i are independent normal with the mean (mu - sigma^2/2) dt_i and the variance sigma^2 dt_i so that
+// m = mu - sigma^2/2 is estimated by sum_i r_i / sum_i dt_i and sigma^2 by 1/n sum_i (r_i - m dt_i)^2 / dt_i.
+// The standard errors come from the Fisher information, sigma / sqrt(sum_i dt_i) for m and sigma / sqrt(2n) for sigma.
+// The confidence interval of sigma is the exact one from the chi-squared distribution of n sigma_hat^2 / sigma^2
+// with n-1 degrees of freedom while that of mu is the normal approximation using the standard error of
+// m + sigma^2/2 from the delta method. The interest rate r is passed on to the model parameters.
+func CalibrateBS(prices []Price, r, confidence float64) (Calibration, error) {
+	if err := misc.FirstError(
+		misc.ValidateProbability(confidence),
+		misc.ValidateFinite(r, misc.ErrInvalidRate),
+	); err != nil {
+		return Calibration{}, err
+	}
+	returns, dt, err := logReturns(prices)
+	if err != nil {
+		return Calibration{}, err
+	}
+	n := len(returns)
+	if n < 2 {
+		return Calibration{}, ErrTooFewObservations
+	}
+
+	var sumReturns, duration float64
+	for i := range returns {
+		sumReturns += returns[i]
+		duration += dt[i]
+	}
+	m := sumReturns / duration
+	var sumSquares float64
+	for i := range returns {
+		e := returns[i] - m*dt[i]
+		sumSquares += e * e / dt[i]
+	}
+	variance := sumSquares / float64(n)
+	sigma := math.Sqrt(variance)
+	mu := m + 0.5*variance
+
+	sigmaStdErr := sigma / math.Sqrt(2*float64(n))
+	// the estimates of m and sigma^2 are independent and sigma^2 has the standard error sigma^2 sqrt(2/n)
+	muStdErr := math.Sqrt(variance/duration + variance*variance/(2*float64(n)))
+
+	alpha := 1 - confidence
+	z := distuv.UnitNormal.Quantile(1 - 0.5*alpha)
+	chiSquared := distuv.ChiSquared{K: float64(n - 1)}
+
+	calibration := Calibration{
+		Params:      riskmodelbs.ModelParamsBS{Mu: mu, R: r, Sigma: sigma},
+		MuStdErr:    muStdErr,
+		SigmaStdErr: sigmaStdErr,
+		MuInterval: misc.ConfidenceInterval{
+			Lower: mu - z*muStdErr, Upper: mu + z*muStdErr, Confidence: confidence,
+		},
+		SigmaInterval: misc.ConfidenceInterval{
+			Lower:      math.Sqrt(sumSquares / chiSquared.Quantile(1-0.5*alpha)),
+			Upper:      math.Sqrt(sumSquares / chiSquared.Quantile(0.5*alpha)),
+			Confidence: confidence,
+		},
+		Observations: n,
+	}
+	if _, err := validated(calibration.Params); err != nil {
+		return Calibration{}, err
+	}
+	return calibration, nil
+}
+
+// Conservative returns the model parameters with sigma at the upper bound of its confidence interval,
+// which give larger risk factors (e.g. from riskmodelbs.RiskFactorsForward) than the point estimate
+func (c Calibration) Conservative() riskmodelbs.ModelParamsBS {
+	p := c.Params
+	p.Sigma = c.SigmaInterval.Upper
+	return p
+}
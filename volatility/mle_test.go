@@ -0,0 +1,105 @@
+package volatility
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"code.vegaprotocol.io/quant/misc"
+	"code.vegaprotocol.io/quant/riskmodelbs"
+
+	"golang.org/x/exp/rand"
+)
+
+func TestCalibrateBSEstimates(t *testing.T) {
+	const testTolerance float64 = 1e-12
+
+	rng := rand.New(rand.NewSource(1))
+	prices := simulatePrices(rng, 1000, 100, 0.3, 0.6, time.Hour)
+	calibration, err := CalibrateBS(prices, 0.01, 0.95)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// the drift of the log-price is its total change over the total time
+	n := len(prices) - 1
+	first, last := prices[0], prices[n]
+	m := math.Log(last.Price/first.Price) / years(last.Time.Sub(first.Time))
+	// the maximum likelihood variance is the unbiased close-to-close one scaled by (n-1)/n
+	closeToClose, _ := CloseToClose(prices)
+	sigma := closeToClose * math.Sqrt(float64(n-1)/float64(n))
+
+	p := calibration.Params
+	error := math.Abs(p.Sigma-sigma) + math.Abs(p.Mu-(m+0.5*sigma*sigma)) + math.Abs(p.R-0.01)
+	if math.IsNaN(error) || error > testTolerance || calibration.Observations != n {
+		t.Errorf("params %+v, expected mu=%g, sigma=%g\n", p, m+0.5*sigma*sigma, sigma)
+	}
+	if !calibration.SigmaInterval.Contains(p.Sigma) || !calibration.MuInterval.Contains(p.Mu) {
+		t.Errorf("the confidence intervals %+v and %+v don't contain the estimates\n", calibration.SigmaInterval, calibration.MuInterval)
+	}
+	if math.Abs(calibration.SigmaStdErr-sigma/math.Sqrt(2*float64(n))) > testTolerance {
+		t.Errorf("sigma standard error=%g\n", calibration.SigmaStdErr)
+	}
+
+	conservative := calibration.Conservative()
+	if conservative.Sigma != calibration.SigmaInterval.Upper || conservative.Mu != p.Mu {
+		t.Errorf("conservative params %+v\n", conservative)
+	}
+	riskFactors := riskmodelbs.RiskFactorsForward(0.01, 1.0/365.25, p)
+	conservativeRiskFactors := riskmodelbs.RiskFactorsForward(0.01, 1.0/365.25, conservative)
+	if conservativeRiskFactors.Long <= riskFactors.Long || conservativeRiskFactors.Short <= riskFactors.Short {
+		t.Errorf("conservative risk factors %+v aren't above %+v\n", conservativeRiskFactors, riskFactors)
+	}
+}
+
+// TestCalibrateBSCoverage checks that the confidence intervals cover the true parameters about as often as stated
+func TestCalibrateBSCoverage(t *testing.T) {
+	const numPaths, confidence = 400, 0.9
+	const mu, sigma = 0.5, 0.8
+	// about 3 standard deviations of the number of paths covered
+	tolerance := 3 * math.Sqrt(confidence*(1-confidence)/numPaths)
+
+	rng := rand.New(rand.NewSource(1))
+	var coveredMu, coveredSigma float64
+	for i := 0; i < numPaths; i++ {
+		prices := simulatePrices(rng, 250, 100, mu, sigma, 24*time.Hour)
+		calibration, err := CalibrateBS(prices, 0, confidence)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if calibration.MuInterval.Contains(mu) {
+			coveredMu++
+		}
+		if calibration.SigmaInterval.Contains(sigma) {
+			coveredSigma++
+		}
+	}
+	coveredMu /= numPaths
+	coveredSigma /= numPaths
+	if math.Abs(coveredMu-confidence) > tolerance || math.Abs(coveredSigma-confidence) > tolerance {
+		t.Errorf("coverage of mu=%g, sigma=%g, expected %g\n", coveredMu, coveredSigma, confidence)
+	}
+}
+
+func TestCalibrateBSErrors(t *testing.T) {
+	t1, t2, t3 := start, start.Add(time.Hour), start.Add(2*time.Hour)
+	prices := []Price{{t1, 100}, {t2, 101}, {t3, 99}}
+
+	tables := []struct {
+		prices     []Price
+		r          float64
+		confidence float64
+		err        error
+	}{
+		{prices, 0, 0.95, nil},
+		{prices[:2], 0, 0.95, ErrTooFewObservations},
+		{prices, 0, 1, misc.ErrProbabilityOutOfRange},
+		{prices, math.NaN(), 0.95, misc.ErrInvalidRate},
+		{[]Price{{t1, 100}, {t2, 100}, {t3, 100}}, 0, 0.95, misc.ErrInvalidSigma},
+	}
+	for i, table := range tables {
+		if _, err := CalibrateBS(table.prices, table.r, table.confidence); err != table.err {
+			t.Errorf("case %d: expected error %v, got %v\n", i, table.err, err)
+		}
+	}
+}
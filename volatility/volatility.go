@@ -0,0 +1,265 @@
+package volatility
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"code.vegaprotocol.io/quant/misc"
+	"code.vegaprotocol.io/quant/riskmodelbs"
+)
+
+// The estimators return the annualised volatility, time is measured in years of 365.25 days
+// and consecutive observations may be any time apart. All of them normalise by the calendar time the observations
+// span, i.e. between consecutive closes, so the range estimators, which only see the moves within the bars,
+// understate the volatility when the price also moves between bars, e.g. overnight, which YangZhang includes.
+
+var (
+	// ErrTooFewObservations is returned when there are too few prices or bars for the estimator
+	ErrTooFewObservations = errors.New("too few observations to estimate the volatility")
+	// ErrUnsortedTimes is returned when the timestamps of the prices or bars aren't increasing
+	ErrUnsortedTimes = errors.New("timestamps must be increasing")
+	// ErrInvalidBar is returned when a bar ends before it starts or its high and low don't bound its open and close
+	ErrInvalidBar = errors.New("bar must end after it starts and have low <= open, close <= high")
+	// ErrInvalidDecay is returned when the EWMA decay factor isn't strictly between 0 and 1
+	ErrInvalidDecay = errors.New("decay factor must be strictly between 0 and 1")
+)
+
+// RiskMetricsLambda is the EWMA decay factor used by RiskMetrics for daily returns
+const RiskMetricsLambda = 0.94
+
+// Price is a price observed at the given time
+type Price struct {
+	Time  time.Time
+	Price float64
+}
+
+// Bar holds the open, high, low and close prices of the period from Start to End
+type Bar struct {
+	Start time.Time
+	End   time.Time
+	Open  float64
+	High  float64
+	Low   float64
+	Close float64
+}
+
+// PriceEstimator estimates the annualised volatility from a series of prices, e.g. CloseToClose
+type PriceEstimator func(prices []Price) (float64, error)
+
+// BarEstimator estimates the annualised volatility from a series of bars, e.g. YangZhang
+type BarEstimator func(bars []Bar) (float64, error)
+
+// years returns the duration d in years
+func years(d time.Duration) float64 {
+	return d.Hours() / (365.25 * 24)
+}
+
+// logReturns returns the log-returns of the prices and the times (in years) over which they happened
+func logReturns(prices []Price) (returns, dt []float64, err error) {
+	if len(prices) < 2 {
+		return nil, nil, ErrTooFewObservations
+	}
+	returns = make([]float64, len(prices)-1)
+	dt = make([]float64, len(prices)-1)
+	for i, p := range prices {
+		if err := misc.ValidatePositive(p.Price, misc.ErrInvalidPrice); err != nil {
+			return nil, nil, err
+		}
+		if i == 0 {
+			continue
+		}
+		if !p.Time.After(prices[i-1].Time) {
+			return nil, nil, ErrUnsortedTimes
+		}
+		returns[i-1] = math.Log(p.Price / prices[i-1].Price)
+		dt[i-1] = years(p.Time.Sub(prices[i-1].Time))
+	}
+	return returns, dt, nil
+}
+
+// CloseToClose estimates the volatility from the log-returns r_i over the times dt_i as
+// sigma^2 = 1/(n-1) sum_i (r_i - m dt_i)^2 / dt_i where m = sum_i r_i / sum_i dt_i is the drift,
+// for equally spaced prices this is the sample variance of the returns divided by the time step
+func CloseToClose(prices []Price) (float64, error) {
+	returns, dt, err := logReturns(prices)
+	if err != nil {
+		return 0, err
+	}
+	n := len(returns)
+	if n < 2 {
+		return 0, ErrTooFewObservations
+	}
+	var sumReturns, sumDt float64
+	for i := range returns {
+		sumReturns += returns[i]
+		sumDt += dt[i]
+	}
+	drift := sumReturns / sumDt
+	var variance float64
+	for i := range returns {
+		e := returns[i] - drift*dt[i]
+		variance += e * e / dt[i]
+	}
+	return math.Sqrt(variance / float64(n-1)), nil
+}
+
+// EWMA estimates the current volatility with the exponentially weighted moving average of the squared log-returns
+// sigma_i^2 = lambda sigma_{i-1}^2 + (1 - lambda) r_i^2 / dt_i, which assumes zero drift, e.g. with the RiskMetricsLambda.
+// The average starts from the mean of r_i^2 / dt_i over all the returns.
+func EWMA(prices []Price, lambda float64) (float64, error) {
+	if !(lambda > 0) || !(lambda < 1) {
+		return 0, ErrInvalidDecay
+	}
+	returns, dt, err := logReturns(prices)
+	if err != nil {
+		return 0, err
+	}
+	var variance float64
+	for i := range returns {
+		variance += returns[i] * returns[i] / dt[i] / float64(len(returns))
+	}
+	for i := range returns {
+		variance = lambda*variance + (1-lambda)*returns[i]*returns[i]/dt[i]
+	}
+	return math.Sqrt(variance), nil
+}
+
+// validateBars checks that the bars are valid and don't overlap
+func validateBars(bars []Bar, minBars int) error {
+	for i, b := range bars {
+		if err := misc.FirstError(
+			misc.ValidatePositive(b.Open, misc.ErrInvalidPrice),
+			misc.ValidatePositive(b.High, misc.ErrInvalidPrice),
+			misc.ValidatePositive(b.Low, misc.ErrInvalidPrice),
+			misc.ValidatePositive(b.Close, misc.ErrInvalidPrice),
+		); err != nil {
+			return err
+		}
+		if !b.End.After(b.Start) || b.Low > math.Min(b.Open, b.Close) || b.High < math.Max(b.Open, b.Close) {
+			return ErrInvalidBar
+		}
+		if i > 0 && b.Start.Before(bars[i-1].End) {
+			return ErrUnsortedTimes
+		}
+	}
+	if len(bars) < minBars {
+		return ErrTooFewObservations
+	}
+	return nil
+}
+
+// rangeEstimate returns the square root of the sum of the variance estimates of the bars over the time from the start
+// of the first bar to the end of the last one, i.e. including any time between bars
+func rangeEstimate(bars []Bar, barVariance func(b Bar) float64) (float64, error) {
+	if err := validateBars(bars, 1); err != nil {
+		return 0, err
+	}
+	var variance float64
+	for _, b := range bars {
+		variance += barVariance(b)
+	}
+	duration := years(bars[len(bars)-1].End.Sub(bars[0].Start))
+	return math.Sqrt(variance / duration), nil
+}
+
+// parkinsonVariance returns ln(H/L)^2 / (4 ln 2), see Parkinson (The extreme value method for estimating the variance
+// of the rate of return, Journal of Business, 1980)
+func parkinsonVariance(b Bar) float64 {
+	hl := math.Log(b.High / b.Low)
+	return hl * hl / (4 * math.Ln2)
+}
+
+// garmanKlassVariance returns ln(H/L)^2 / 2 - (2 ln 2 - 1) ln(C/O)^2, see Garman and Klass (On the estimation of security
+// price volatilities from historical data, Journal of Business, 1980)
+func garmanKlassVariance(b Bar) float64 {
+	hl, co := math.Log(b.High/b.Low), math.Log(b.Close/b.Open)
+	return 0.5*hl*hl - (2*math.Ln2-1)*co*co
+}
+
+// rogersSatchellVariance returns ln(H/C) ln(H/O) + ln(L/C) ln(L/O), see Rogers and Satchell (Estimating variance from
+// high, low and closing prices, Annals of Applied Probability, 1991), which is unbiased for any drift
+func rogersSatchellVariance(b Bar) float64 {
+	return math.Log(b.High/b.Close)*math.Log(b.High/b.Open) + math.Log(b.Low/b.Close)*math.Log(b.Low/b.Open)
+}
+
+// Parkinson estimates the volatility from the high-low range of the bars assuming zero drift,
+// the moves between bars are left out
+func Parkinson(bars []Bar) (float64, error) {
+	return rangeEstimate(bars, parkinsonVariance)
+}
+
+// GarmanKlass estimates the volatility from the open, high, low and close prices of the bars assuming zero drift,
+// the moves between bars are left out
+func GarmanKlass(bars []Bar) (float64, error) {
+	return rangeEstimate(bars, garmanKlassVariance)
+}
+
+// RogersSatchell estimates the volatility from the open, high, low and close prices of the bars allowing for a drift,
+// the moves between bars are left out
+func RogersSatchell(bars []Bar) (float64, error) {
+	return rangeEstimate(bars, rogersSatchellVariance)
+}
+
+// YangZhang estimates the volatility including the moves between the close of a bar and the open of the next one
+// (e.g. overnight) as sigma^2 = sigma_O^2 + k sigma_C^2 + (1 - k) sigma_RS^2 with k = 0.34 / (1.34 + (n + 1) / (n - 1)),
+// see Yang and Zhang (Drift-independent volatility estimation based on high, low, open and close prices, Journal of Business, 2000).
+// sigma_O^2 and sigma_C^2 are the sample variances of the open-to-previous-close and close-to-open log-returns
+// and sigma_RS^2 the mean Rogers-Satchell variance, all normalised by the time from the previous close to the close
+// so that irregular periods are handled, the first bar only provides the previous close.
+func YangZhang(bars []Bar) (float64, error) {
+	if err := validateBars(bars, 3); err != nil {
+		return 0, err
+	}
+	n := len(bars) - 1
+	openReturns := make([]float64, n)
+	closeReturns := make([]float64, n)
+	var meanOpen, meanClose, rs float64
+	for i := range openReturns {
+		b := bars[i+1]
+		sqrtDt := math.Sqrt(years(b.End.Sub(bars[i].End)))
+		openReturns[i] = math.Log(b.Open/bars[i].Close) / sqrtDt
+		closeReturns[i] = math.Log(b.Close/b.Open) / sqrtDt
+		meanOpen += openReturns[i] / float64(n)
+		meanClose += closeReturns[i] / float64(n)
+		rs += rogersSatchellVariance(b) / (sqrtDt * sqrtDt) / float64(n)
+	}
+	var openVariance, closeVariance float64
+	for i := range openReturns {
+		openVariance += (openReturns[i] - meanOpen) * (openReturns[i] - meanOpen) / float64(n-1)
+		closeVariance += (closeReturns[i] - meanClose) * (closeReturns[i] - meanClose) / float64(n-1)
+	}
+	k := 0.34 / (1.34 + float64(n+1)/float64(n-1))
+	return math.Sqrt(openVariance + k*closeVariance + (1-k)*rs), nil
+}
+
+// ModelParamsFromPrices returns the Black-Scholes model parameters with the volatility estimated from the prices,
+// e.g. with CloseToClose or func(p []Price) (float64, error) { return EWMA(p, RiskMetricsLambda) },
+// and the given growth rate mu and interest rate r. The parameters are validated with ModelParamsBS.Validate
+// and a zero volatility estimate is rejected with misc.ErrInvalidSigma.
+func ModelParamsFromPrices(prices []Price, estimator PriceEstimator, mu, r float64) (riskmodelbs.ModelParamsBS, error) {
+	sigma, err := estimator(prices)
+	if err != nil {
+		return riskmodelbs.ModelParamsBS{}, err
+	}
+	return validated(riskmodelbs.ModelParamsBS{Mu: mu, R: r, Sigma: sigma})
+}
+
+// ModelParamsFromBars returns the Black-Scholes model parameters with the volatility estimated from the bars,
+// e.g. with YangZhang, and the given growth rate mu and interest rate r, see ModelParamsFromPrices
+func ModelParamsFromBars(bars []Bar, estimator BarEstimator, mu, r float64) (riskmodelbs.ModelParamsBS, error) {
+	sigma, err := estimator(bars)
+	if err != nil {
+		return riskmodelbs.ModelParamsBS{}, err
+	}
+	return validated(riskmodelbs.ModelParamsBS{Mu: mu, R: r, Sigma: sigma})
+}
+
+// validated checks the estimated parameters with ModelParamsBS.Validate and additionally rejects a zero volatility,
+// which only constant prices give and which says the prices can't identify the volatility
+func validated(p riskmodelbs.ModelParamsBS) (riskmodelbs.ModelParamsBS, error) {
+	if err := misc.FirstError(p.Validate(), misc.ValidatePositive(p.Sigma, misc.ErrInvalidSigma)); err != nil {
+		return riskmodelbs.ModelParamsBS{}, err
+	}
+	return p, nil
+}
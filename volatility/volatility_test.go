@@ -0,0 +1,265 @@
+package volatility
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"code.vegaprotocol.io/quant/misc"
+
+	"golang.org/x/exp/rand"
+)
+
+var start = time.Date(2021, 1, 4, 0, 0, 0, 0, time.UTC)
+
+// simulatePrices returns a geometric brownian motion observed at irregular times, on average meanGap apart
+func simulatePrices(rng *rand.Rand, n int, S, mu, sigma float64, meanGap time.Duration) []Price {
+	prices := make([]Price, n)
+	prices[0] = Price{Time: start, Price: S}
+	for i := 1; i < n; i++ {
+		gap := time.Duration((0.1 + rng.ExpFloat64()) * float64(meanGap))
+		dt := years(gap)
+		S *= math.Exp((mu-0.5*sigma*sigma)*dt + sigma*math.Sqrt(dt)*rng.NormFloat64())
+		prices[i] = Price{Time: prices[i-1].Time.Add(gap), Price: S}
+	}
+	return prices
+}
+
+// simulateBars returns daily bars of a geometric brownian motion trading from 9:00 to 17:00 with the volatility sigma
+// and moving overnight with the volatility overnightSigma, the bars are built from steps price moves each
+func simulateBars(rng *rand.Rand, n, steps int, S, mu, sigma, overnightSigma float64) []Bar {
+	bars := make([]Bar, n)
+	move := func(S float64, d time.Duration, sigma float64) float64 {
+		dt := years(d)
+		return S * math.Exp((mu-0.5*sigma*sigma)*dt+sigma*math.Sqrt(dt)*rng.NormFloat64())
+	}
+	for i := range bars {
+		day := start.AddDate(0, 0, i)
+		b := Bar{Start: day.Add(9 * time.Hour), End: day.Add(17 * time.Hour)}
+		if i > 0 {
+			S = move(S, 16*time.Hour, overnightSigma)
+		}
+		b.Open, b.High, b.Low = S, S, S
+		for j := 0; j < steps; j++ {
+			S = move(S, 8*time.Hour/time.Duration(steps), sigma)
+			b.High, b.Low = math.Max(b.High, S), math.Min(b.Low, S)
+		}
+		b.Close = S
+		bars[i] = b
+	}
+	return bars
+}
+
+func TestCloseToCloseIrregularTimes(t *testing.T) {
+	const testTolerance float64 = 2e-2 // relative
+
+	rng := rand.New(rand.NewSource(1))
+	for _, sigma := range []float64{0.2, 0.8, 2.0} {
+		prices := simulatePrices(rng, 20000, 100, 0.5, sigma, time.Hour)
+		estimate, err := CloseToClose(prices)
+		error := math.Abs(estimate/sigma - 1)
+		if err != nil || math.IsNaN(error) || error > testTolerance {
+			t.Errorf("sigma=%g: estimate=%g, error %v\n", sigma, estimate, err)
+		}
+	}
+}
+
+func TestEWMA(t *testing.T) {
+	const testTolerance float64 = 1e-12
+
+	// constant squared returns per unit of time give the volatility exactly
+	const sigma = 0.5
+	prices := []Price{{Time: start, Price: 100}}
+	for i, gap := range []time.Duration{time.Hour, 2 * time.Hour, time.Hour, 30 * time.Minute, 5 * time.Hour} {
+		r := sigma * math.Sqrt(years(gap)) * math.Pow(-1, float64(i))
+		last := prices[len(prices)-1]
+		prices = append(prices, Price{Time: last.Time.Add(gap), Price: last.Price * math.Exp(r)})
+	}
+	estimate, err := EWMA(prices, RiskMetricsLambda)
+	if err != nil || math.Abs(estimate-sigma) > testTolerance {
+		t.Errorf("estimate=%g, expected=%g, error %v\n", estimate, sigma, err)
+	}
+
+	// the estimate follows a change of volatility
+	rng := rand.New(rand.NewSource(1))
+	calm := simulatePrices(rng, 2000, 100, 0, 0.4, time.Hour)
+	last := calm[len(calm)-1]
+	volatile := simulatePrices(rng, 2000, last.Price, 0, 1.2, time.Hour)
+	for i := range volatile {
+		volatile[i].Time = last.Time.Add(volatile[i].Time.Sub(start) + time.Hour)
+	}
+	prices = append(calm, volatile...)
+	ewma, err1 := EWMA(prices, 0.99)
+	closeToClose, err2 := CloseToClose(prices)
+	if err := misc.FirstError(err1, err2); err != nil {
+		t.Fatalf(err.Error())
+	}
+	if math.Abs(ewma/1.2-1) > 0.2 || math.Abs(closeToClose-1.2) < math.Abs(ewma-1.2) {
+		t.Errorf("EWMA=%g, close-to-close=%g, expected the EWMA closer to the latest volatility 1.2\n", ewma, closeToClose)
+	}
+}
+
+// TestRangeEstimators checks the estimators on the calendar time clock: the range estimators only see the trading
+// hours, a third of the day, and Yang-Zhang also the overnight moves
+func TestRangeEstimators(t *testing.T) {
+	const testTolerance float64 = 5e-2 // relative
+
+	rng := rand.New(rand.NewSource(1))
+	for _, sigma := range []float64{0.3, 1.0} {
+		for _, overnightSigma := range []float64{0, sigma, 2 * sigma} {
+			bars := simulateBars(rng, 500, 2000, 100, 0.1, sigma, overnightSigma)
+			intraday := sigma * math.Sqrt(8.0/24)
+			total := math.Sqrt(sigma*sigma*8/24 + overnightSigma*overnightSigma*16/24)
+			estimators := []struct {
+				name      string
+				estimator BarEstimator
+				expected  float64
+			}{
+				{"Parkinson", Parkinson, intraday},
+				{"Garman-Klass", GarmanKlass, intraday},
+				{"Rogers-Satchell", RogersSatchell, intraday},
+				{"Yang-Zhang", YangZhang, total},
+			}
+			for _, e := range estimators {
+				estimate, err := e.estimator(bars)
+				error := math.Abs(estimate/e.expected - 1)
+				if err != nil || math.IsNaN(error) || error > testTolerance {
+					t.Errorf("%s, sigma=%g, overnight sigma=%g: estimate=%g, expected=%g, error %v\n",
+						e.name, sigma, overnightSigma, estimate, e.expected, err)
+				}
+			}
+		}
+	}
+}
+
+// TestContinuousBars checks that with bars covering all the time every estimator recovers the volatility
+func TestContinuousBars(t *testing.T) {
+	const testTolerance float64 = 5e-2 // relative
+	const sigma = 0.8
+
+	rng := rand.New(rand.NewSource(1))
+	const n, steps = 500, 2000
+	prices := simulatePrices(rng, n*steps+1, 100, 0.1, sigma, time.Second)
+	bars := make([]Bar, 0, n)
+	for i := 0; i+steps < len(prices); i += steps {
+		b := Bar{Start: prices[i].Time, End: prices[i+steps].Time, Open: prices[i].Price, Close: prices[i+steps].Price}
+		b.High, b.Low = b.Open, b.Open
+		for _, p := range prices[i+1 : i+steps+1] {
+			b.High, b.Low = math.Max(b.High, p.Price), math.Min(b.Low, p.Price)
+		}
+		bars = append(bars, b)
+	}
+	for _, estimator := range []BarEstimator{Parkinson, GarmanKlass, RogersSatchell, YangZhang} {
+		estimate, err := estimator(bars)
+		error := math.Abs(estimate/sigma - 1)
+		if err != nil || math.IsNaN(error) || error > testTolerance {
+			t.Errorf("estimate=%g, error %v\n", estimate, err)
+		}
+	}
+}
+
+func TestYangZhangIncludesOvernightMoves(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	bars := simulateBars(rng, 500, 100, 100, 0, 0.5, 0.5)
+	// large opening gaps only show up in the Yang-Zhang estimate
+	for i := 1; i < len(bars); i++ {
+		gap := math.Exp(0.05 * rng.NormFloat64())
+		for j := i; j < len(bars); j++ {
+			bars[j].Open *= gap
+			bars[j].High *= gap
+			bars[j].Low *= gap
+			bars[j].Close *= gap
+		}
+	}
+	yangZhang, err1 := YangZhang(bars)
+	rogersSatchell, err2 := RogersSatchell(bars)
+	if err := misc.FirstError(err1, err2); err != nil {
+		t.Fatalf(err.Error())
+	}
+	// the overnight variance adds about 0.05^2 per day, the trading hours are a third of the day
+	expected := math.Sqrt(0.5*0.5 + 0.05*0.05*365.25)
+	intraday := 0.5 * math.Sqrt(8.0/24)
+	if math.Abs(yangZhang/expected-1) > 0.1 || math.Abs(rogersSatchell/intraday-1) > 0.1 {
+		t.Errorf("Yang-Zhang=%g, expected=%g, Rogers-Satchell=%g, expected=%g\n", yangZhang, expected, rogersSatchell, intraday)
+	}
+}
+
+func TestModelParams(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	prices := simulatePrices(rng, 1000, 100, 0, 0.5, time.Hour)
+	bars := simulateBars(rng, 100, 100, 100, 0, 0.5, 0.5)
+
+	p, err := ModelParamsFromPrices(prices, CloseToClose, 0.1, 0.01)
+	sigma, _ := CloseToClose(prices)
+	if err != nil || p.Sigma != sigma || p.Mu != 0.1 || p.R != 0.01 {
+		t.Errorf("params %+v, error %v\n", p, err)
+	}
+	ewma := func(prices []Price) (float64, error) { return EWMA(prices, RiskMetricsLambda) }
+	if p, err := ModelParamsFromPrices(prices, ewma, 0, 0); err != nil || !(p.Sigma > 0) {
+		t.Errorf("params %+v, error %v\n", p, err)
+	}
+	if p, err := ModelParamsFromBars(bars, YangZhang, 0, 0); err != nil || !(p.Sigma > 0) {
+		t.Errorf("params %+v, error %v\n", p, err)
+	}
+
+	// constant prices give zero volatility which isn't a valid model
+	constant := []Price{{start, 100}, {start.Add(time.Hour), 100}, {start.Add(2 * time.Hour), 100}}
+	if _, err := ModelParamsFromPrices(constant, CloseToClose, 0, 0); err != misc.ErrInvalidSigma {
+		t.Errorf("expected error %v, got %v\n", misc.ErrInvalidSigma, err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	t1, t2, t3 := start, start.Add(time.Hour), start.Add(2*time.Hour)
+	bar := Bar{Start: t1, End: t2, Open: 100, High: 110, Low: 90, Close: 105}
+	next := Bar{Start: t2, End: t3, Open: 105, High: 110, Low: 90, Close: 100}
+	last := Bar{Start: t3, End: t3.Add(time.Hour), Open: 100, High: 110, Low: 90, Close: 100}
+
+	priceTables := []struct {
+		prices []Price
+		err    error
+	}{
+		{[]Price{{t1, 100}, {t2, 101}}, ErrTooFewObservations},
+		{[]Price{{t1, 100}, {t2, 101}, {t2, 102}}, ErrUnsortedTimes},
+		{[]Price{{t1, 100}, {t2, 0}, {t3, 102}}, misc.ErrInvalidPrice},
+	}
+	for i, table := range priceTables {
+		if _, err := CloseToClose(table.prices); err != table.err {
+			t.Errorf("case %d: expected error %v, got %v\n", i, table.err, err)
+		}
+	}
+	if _, err := EWMA([]Price{{t1, 100}}, RiskMetricsLambda); err != ErrTooFewObservations {
+		t.Errorf("expected error %v, got %v\n", ErrTooFewObservations, err)
+	}
+	if _, err := EWMA([]Price{{t1, 100}, {t2, 101}}, 1); err != ErrInvalidDecay {
+		t.Errorf("expected error %v, got %v\n", ErrInvalidDecay, err)
+	}
+
+	inverted := bar
+	inverted.High, inverted.Low = 90, 110
+	early := next
+	early.Start = t1
+	barTables := []struct {
+		bars []Bar
+		err  error
+	}{
+		{nil, ErrTooFewObservations},
+		{[]Bar{inverted}, ErrInvalidBar},
+		{[]Bar{{Start: t2, End: t1, Open: 100, High: 100, Low: 100, Close: 100}}, ErrInvalidBar},
+		{[]Bar{bar, early}, ErrUnsortedTimes},
+		{[]Bar{{Start: t1, End: t2, Open: 100, High: 100, Low: -1, Close: 100}}, misc.ErrInvalidPrice},
+	}
+	for i, table := range barTables {
+		for _, estimator := range []BarEstimator{Parkinson, GarmanKlass, RogersSatchell, YangZhang} {
+			if _, err := estimator(table.bars); err != table.err {
+				t.Errorf("case %d: expected error %v, got %v\n", i, table.err, err)
+			}
+		}
+	}
+	if _, err := YangZhang([]Bar{bar, next}); err != ErrTooFewObservations {
+		t.Errorf("expected error %v, got %v\n", ErrTooFewObservations, err)
+	}
+	if _, err := YangZhang([]Bar{bar, next, last}); err != nil {
+		t.Errorf("unexpected error %v\n", err)
+	}
+}
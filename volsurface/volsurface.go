@@ -0,0 +1,179 @@
+package volsurface
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"code.vegaprotocol.io/quant/bsformula"
+)
+
+var (
+	// ErrNoQuotes is returned when the surface is built from an empty list of quotes
+	ErrNoQuotes = errors.New("no quotes to build the volatility surface from")
+	// ErrInvalidQuote is returned when a quote has a non-positive expiry, strike or volatility
+	ErrInvalidQuote = errors.New("quote expiry, strike and volatility must be positive")
+	// ErrDuplicateQuote is returned when two quotes have the same expiry and strike
+	ErrDuplicateQuote = errors.New("more than one quote for the same expiry and strike")
+	// ErrInvalidReference is returned when the reference price needed for log-moneyness isn't positive
+	ErrInvalidReference = errors.New("reference price must be positive")
+)
+
+// Quote is the implied volatility of an option with the given expiry (in years) and strike
+type Quote struct {
+	Expiry float64
+	Strike float64
+	Vol    float64
+}
+
+// StrikeAxis selects the variable in which each smile is interpolated
+type StrikeAxis int
+
+const (
+	// Strike interpolates the smiles linearly in the strike K
+	Strike StrikeAxis = iota
+	// LogMoneyness interpolates the smiles linearly in ln(K/S) where S is the reference price of the surface
+	LogMoneyness
+)
+
+// Extrapolation selects how the volatility is extended beyond the quoted strikes and expiries
+type Extrapolation int
+
+const (
+	// Flat keeps the volatility of the nearest quoted strike (or expiry)
+	Flat Extrapolation = iota
+	// Linear extends the volatility of the two nearest quoted strikes linearly (and the total variance of the
+	// last two expiries linearly in time), floored at zero
+	Linear
+)
+
+// smile holds the quotes of one expiry sorted by the interpolation variable x
+type smile struct {
+	x   []float64
+	vol []float64
+}
+
+// Surface is an implied volatility surface built from quotes on a grid of expiries and strikes.
+// Each smile is interpolated linearly in strike or log-moneyness while in between expiries the total
+// variance sigma^2 T is interpolated linearly in time at the same strike (or log-moneyness).
+// Before the first expiry the volatility of the first smile is used.
+// Surface implements interfaces.VolatilitySurface.
+type Surface struct {
+	S             float64
+	Axis          StrikeAxis
+	Extrapolation Extrapolation
+
+	expiries []float64
+	smiles   []smile
+}
+
+// New returns the surface through the given quotes, S is the reference price used for log-moneyness
+// (it is ignored for the Strike axis). The quotes don't need to be sorted and the expiries may have
+// different strikes.
+func New(S float64, quotes []Quote, axis StrikeAxis, extrapolation Extrapolation) (*Surface, error) {
+	if len(quotes) == 0 {
+		return nil, ErrNoQuotes
+	}
+	if axis == LogMoneyness && !(S > 0) {
+		return nil, ErrInvalidReference
+	}
+	for _, quote := range quotes {
+		if !(quote.Expiry > 0) || !(quote.Strike > 0) || !(quote.Vol > 0) || math.IsInf(quote.Vol, 0) {
+			return nil, ErrInvalidQuote
+		}
+	}
+
+	sorted := make([]Quote, len(quotes))
+	copy(sorted, quotes)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Expiry != sorted[j].Expiry {
+			return sorted[i].Expiry < sorted[j].Expiry
+		}
+		return sorted[i].Strike < sorted[j].Strike
+	})
+
+	surface := &Surface{S: S, Axis: axis, Extrapolation: extrapolation}
+	for i, quote := range sorted {
+		if i == 0 || quote.Expiry != sorted[i-1].Expiry {
+			surface.expiries = append(surface.expiries, quote.Expiry)
+			surface.smiles = append(surface.smiles, smile{})
+		} else if quote.Strike == sorted[i-1].Strike {
+			return nil, ErrDuplicateQuote
+		}
+		last := &surface.smiles[len(surface.smiles)-1]
+		last.x = append(last.x, surface.x(quote.Strike))
+		last.vol = append(last.vol, quote.Vol)
+	}
+	return surface, nil
+}
+
+// Vol returns the implied volatility for strike K and time to maturity T
+func (s *Surface) Vol(K, T float64) float64 {
+	x := s.x(K)
+	n := len(s.expiries)
+	j := sort.SearchFloat64s(s.expiries, T)
+
+	switch {
+	case j < n && s.expiries[j] == T:
+		return s.smiles[j].at(x, s.Extrapolation)
+	case j == 0:
+		return s.smiles[0].at(x, s.Extrapolation)
+	case j == n:
+		if s.Extrapolation == Flat || n == 1 {
+			return s.smiles[n-1].at(x, s.Extrapolation)
+		}
+		j = n - 1
+	}
+
+	// total variance linear in time between (or beyond) expiries j-1 and j
+	t0, t1 := s.expiries[j-1], s.expiries[j]
+	vol0, vol1 := s.smiles[j-1].at(x, s.Extrapolation), s.smiles[j].at(x, s.Extrapolation)
+	w0, w1 := vol0*vol0*t0, vol1*vol1*t1
+	w := w0 + (w1-w0)*(T-t0)/(t1-t0)
+	return math.Sqrt(math.Max(w, 0) / T)
+}
+
+// CallPrice returns the Black-Scholes-Merton price of a call on the underlying S with strike K, maturity T,
+// interest rate r and yield q using the volatility from the surface
+func (s *Surface) CallPrice(S, K, r, q, T float64) float64 {
+	return bsformula.BSMCallPrice(S, K, r, q, s.Vol(K, T), T)
+}
+
+// PutPrice returns the Black-Scholes-Merton price of a put on the underlying S with strike K, maturity T,
+// interest rate r and yield q using the volatility from the surface
+func (s *Surface) PutPrice(S, K, r, q, T float64) float64 {
+	return bsformula.BSMPutPrice(S, K, r, q, s.Vol(K, T), T)
+}
+
+// x returns the interpolation variable of the strike K
+func (s *Surface) x(K float64) float64 {
+	if s.Axis == LogMoneyness {
+		return math.Log(K / s.S)
+	}
+	return K
+}
+
+// at returns the volatility of the smile at x
+func (sm smile) at(x float64, extrapolation Extrapolation) float64 {
+	n := len(sm.x)
+	if n == 1 {
+		return sm.vol[0]
+	}
+	i := sort.SearchFloat64s(sm.x, x)
+	switch {
+	case i < n && sm.x[i] == x:
+		return sm.vol[i]
+	case i == 0:
+		if extrapolation == Flat {
+			return sm.vol[0]
+		}
+		i = 1
+	case i == n:
+		if extrapolation == Flat {
+			return sm.vol[n-1]
+		}
+		i = n - 1
+	}
+	vol := sm.vol[i-1] + (sm.vol[i]-sm.vol[i-1])*(x-sm.x[i-1])/(sm.x[i]-sm.x[i-1])
+	return math.Max(vol, 0)
+}
@@ -0,0 +1,141 @@
+package volsurface
+
+import (
+	"math"
+	"testing"
+
+	"code.vegaprotocol.io/quant/bsformula"
+)
+
+const S float64 = 100.0
+
+var testQuotes = []Quote{
+	{Expiry: 0.5, Strike: 120, Vol: 0.22},
+	{Expiry: 0.25, Strike: 80, Vol: 0.35},
+	{Expiry: 0.25, Strike: 100, Vol: 0.25},
+	{Expiry: 0.25, Strike: 120, Vol: 0.28},
+	{Expiry: 0.5, Strike: 80, Vol: 0.30},
+	{Expiry: 0.5, Strike: 100, Vol: 0.20},
+	{Expiry: 1.0, Strike: 100, Vol: 0.18},
+}
+
+func TestSurfaceReproducesQuotes(t *testing.T) {
+	const testTolerance float64 = 1.0e-15
+
+	for _, axis := range []StrikeAxis{Strike, LogMoneyness} {
+		for _, extrapolation := range []Extrapolation{Flat, Linear} {
+			surface, err := New(S, testQuotes, axis, extrapolation)
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+			for _, quote := range testQuotes {
+				vol := surface.Vol(quote.Strike, quote.Expiry)
+				error := math.Abs(vol - quote.Vol)
+				if math.IsNaN(error) || error > testTolerance {
+					t.Errorf("axis=%d, extrapolation=%d, K=%g, T=%g: vol=%g, quote=%g\n",
+						axis, extrapolation, quote.Strike, quote.Expiry, vol, quote.Vol)
+				}
+			}
+		}
+	}
+}
+
+func TestSurfaceInterpolation(t *testing.T) {
+	const testTolerance float64 = 1.0e-14
+
+	surface, _ := New(S, testQuotes, Strike, Flat)
+	logSurface, _ := New(S, testQuotes, LogMoneyness, Flat)
+
+	// in between strikes the smile is linear in the strike or in the log-moneyness
+	weight := math.Log(90.0/80.0) / math.Log(100.0/80.0)
+	tables := []struct {
+		name     string
+		vol      float64
+		expected float64
+	}{
+		{"strike", surface.Vol(90, 0.25), 0.30},
+		{"log-moneyness", logSurface.Vol(90, 0.25), 0.35 + (0.25-0.35)*weight},
+		// in between expiries the total variance is linear in time
+		{"time", surface.Vol(100, 0.75), math.Sqrt((0.5*0.2*0.2*0.5 + 0.5*0.18*0.18*1.0) / 0.75)},
+		{"strike and time", surface.Vol(110, 0.375), math.Sqrt((0.5*0.265*0.265*0.25 + 0.5*0.21*0.21*0.5) / 0.375)},
+	}
+
+	for _, table := range tables {
+		error := math.Abs(table.vol - table.expected)
+		if math.IsNaN(error) || error > testTolerance {
+			t.Errorf("%s: vol=%g, expected=%g\n", table.name, table.vol, table.expected)
+		}
+	}
+}
+
+func TestSurfaceExtrapolation(t *testing.T) {
+	const testTolerance float64 = 1.0e-14
+
+	flat, _ := New(S, testQuotes, Strike, Flat)
+	linear, _ := New(S, testQuotes, Strike, Linear)
+
+	wLastTwo := func(T float64) float64 {
+		w0, w1 := 0.2*0.2*0.5, 0.18*0.18*1.0
+		return w0 + (w1-w0)*(T-0.5)/0.5
+	}
+	tables := []struct {
+		name     string
+		vol      float64
+		expected float64
+	}{
+		{"flat low strike", flat.Vol(60, 0.25), 0.35},
+		{"flat high strike", flat.Vol(140, 0.25), 0.28},
+		{"linear low strike", linear.Vol(60, 0.25), 0.45},
+		{"linear high strike", linear.Vol(140, 0.25), 0.31},
+		{"flat short expiry", flat.Vol(100, 0.01), 0.25},
+		{"linear short expiry", linear.Vol(100, 0.01), 0.25},
+		{"flat long expiry", flat.Vol(100, 2.0), 0.18},
+		{"linear long expiry", linear.Vol(100, 2.0), math.Sqrt(wLastTwo(2.0) / 2.0)},
+	}
+
+	for _, table := range tables {
+		error := math.Abs(table.vol - table.expected)
+		if math.IsNaN(error) || error > testTolerance {
+			t.Errorf("%s: vol=%g, expected=%g\n", table.name, table.vol, table.expected)
+		}
+	}
+}
+
+func TestSurfacePricesUseSmile(t *testing.T) {
+	const testTolerance float64 = 1.0e-14
+	const r, q = 0.03, 0.01
+
+	surface, _ := New(S, testQuotes, LogMoneyness, Flat)
+	for _, quote := range testQuotes {
+		K, T := quote.Strike, quote.Expiry
+		call := surface.CallPrice(S, K, r, q, T)
+		put := surface.PutPrice(S, K, r, q, T)
+		expectedCall := bsformula.BSMCallPrice(S, K, r, q, quote.Vol, T)
+		expectedPut := bsformula.BSMPutPrice(S, K, r, q, quote.Vol, T)
+		if math.Abs(call-expectedCall) > testTolerance*S || math.Abs(put-expectedPut) > testTolerance*S {
+			t.Errorf("K=%g, T=%g: call=%g, expected=%g, put=%g, expected=%g\n", K, T, call, expectedCall, put, expectedPut)
+		}
+	}
+}
+
+func TestSurfaceErrors(t *testing.T) {
+	tables := []struct {
+		S      float64
+		quotes []Quote
+		axis   StrikeAxis
+		err    error
+	}{
+		{S, nil, Strike, ErrNoQuotes},
+		{S, []Quote{{Expiry: 0, Strike: 100, Vol: 0.2}}, Strike, ErrInvalidQuote},
+		{S, []Quote{{Expiry: 1, Strike: -1, Vol: 0.2}}, Strike, ErrInvalidQuote},
+		{S, []Quote{{Expiry: 1, Strike: 100, Vol: math.NaN()}}, Strike, ErrInvalidQuote},
+		{S, []Quote{{Expiry: 1, Strike: 100, Vol: 0.2}, {Expiry: 1, Strike: 100, Vol: 0.3}}, Strike, ErrDuplicateQuote},
+		{0, testQuotes, LogMoneyness, ErrInvalidReference},
+	}
+
+	for i, table := range tables {
+		if _, err := New(table.S, table.quotes, table.axis, Flat); err != table.err {
+			t.Errorf("case %d: expected error %v, got %v\n", i, table.err, err)
+		}
+	}
+}